@@ -3,13 +3,21 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
 	"strings"
 
 	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/CycloneDX/license-scanner/archive"
+	"github.com/CycloneDX/license-scanner/cache"
 	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/expression"
 	"github.com/CycloneDX/license-scanner/identifier"
 	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/manifest"
 	"github.com/CycloneDX/license-scanner/normalizer"
 	"github.com/spf13/pflag"
 )
@@ -17,18 +25,41 @@ import (
 // NOASSERTION_SPDX_NAME in License SPDX Name signify that the license text passed through the scan without any errors but no match was found
 const NOASSERTION_SPDX_NAME = "NOASSERTION"
 
+// maxFileSize is the largest file that ScanFile will read (the same limit as the identifier)
+const maxFileSize = 1000000
+
+// languageManifests are the manifest types of the package managers of programming languages
+var languageManifests = map[string]manifest.Type{
+	"javascript": manifest.NPM,
+	"typescript": manifest.NPM,
+	"java":       manifest.Maven,
+	"kotlin":     manifest.Maven,
+	"scala":      manifest.Maven,
+	"python":     manifest.PyPI,
+	"rust":       manifest.Cargo,
+	"c#":         manifest.NuGet,
+	"csharp":     manifest.NuGet,
+	"f#":         manifest.NuGet,
+	"ruby":       manifest.Gem,
+	"php":        manifest.Composer,
+}
+
 // ScanSpecs holds the package manager, the programming language, and a list of multiple packages with their specifications
 type ScanSpecs struct {
 	// package manager to search for
 	// This is the standard package manager, for example, pypi for python, npm for nodejs, etc
+	// Only the licenses declared by the manifests of this package manager (or of the PURL type of a spec) are in the DeclaredLicenses of a ScanFile.
 	PackageManager string
 	// programming language to search for
+	// When there is no package manager, only the licenses declared by the manifests of the package manager of the language are in the DeclaredLicenses of a ScanFile.
 	Language string
 	// a list of scan specification
 	// for a single package manager or a language, specify a list of packages with their respective specifications
 	Specs []ScanSpec
 	// config flag set
 	flags *pflag.FlagSet
+	// cache of identifier results, if any
+	cache cache.Cache
 }
 
 // ScanSpec holds the specifications used for scanning the incoming package/file
@@ -74,6 +105,10 @@ type ScanResult struct {
 	Error error
 	// a list of LicenseMatch i.e. a list of SPDX license IDs in sequential order, the matches of the input text across the various licenses
 	CycloneDXLicenses Licenses
+	// the licenses declared by the package manifests of a ScanFile (see manifest.Parse), next to the CycloneDXLicenses detected in the texts
+	DeclaredLicenses Licenses
+	// the discrepancies between the declared licenses and the licenses detected in license files, by manifest
+	Discrepancies []string
 }
 
 // WithConfig sets the config to use for the scan
@@ -82,19 +117,53 @@ func (s *ScanSpecs) WithFlags(flags *pflag.FlagSet) *ScanSpecs {
 	return s
 }
 
-// ScanLicenseText scans the specified license file to retrieve license information
-func (s *ScanSpecs) ScanLicenseText() ([]*ScanResult, error) {
+// WithCache sets the cache of identifier results to use instead of the --cacheDir.
+// The cache must be specific to the license library (see licenses.LicenseLibrary.Fingerprint).
+func (s *ScanSpecs) WithCache(c cache.Cache) *ScanSpecs {
+	s.cache = c
+	return s
+}
+
+// newLicenseLibrary loads the license library and returns it with the identifier options of the flags (see identifier.NewOptions),
+// with the cache of WithCache, if any, instead of the cacheDir
+func (s *ScanSpecs) newLicenseLibrary() (*licenses.LicenseLibrary, identifier.Options, error) {
 	cfg, err := configurer.InitConfig(s.flags)
 	if err != nil {
-		return nil, err
+		return nil, identifier.Options{}, err
 	}
 	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
 	if err != nil {
-		return nil, err
+		return nil, identifier.Options{}, err
 	}
 
 	// initialize the license data set to compare against
-	if err := licenseLibrary.AddAll(); err != nil {
+	if err := licenseLibrary.Load(); err != nil {
+		return nil, identifier.Options{}, err
+	}
+
+	if s.cache != nil {
+		cfg.Set(configurer.CacheDirFlag, "")
+	}
+	options, err := identifier.NewOptions(cfg, licenseLibrary)
+	if err != nil {
+		return nil, options, err
+	}
+	if s.cache != nil {
+		options.Cache = s.cache
+	}
+	return licenseLibrary, options, nil
+}
+
+// ScanLicenseText scans the specified license file to retrieve license information
+func (s *ScanSpecs) ScanLicenseText() ([]*ScanResult, error) {
+	return s.ScanLicenseTextContext(context.Background())
+}
+
+// ScanLicenseTextContext is ScanLicenseText, which stops when the context is canceled or its deadline passes.
+// The specs which were not scanned have the error of the context in their ScanResult.
+func (s *ScanSpecs) ScanLicenseTextContext(ctx context.Context) ([]*ScanResult, error) {
+	licenseLibrary, options, err := s.newLicenseLibrary()
+	if err != nil {
 		return nil, err
 	}
 
@@ -107,7 +176,7 @@ func (s *ScanSpecs) ScanLicenseText() ([]*ScanResult, error) {
 
 	for _, p := range s.Specs {
 		// identify license information for the specified license text
-		scanResult := p.ScanLicenseText(licenseLibrary, resultsCache)
+		scanResult := p.scanText(ctx, p.LicenseText, licenseLibrary, resultsCache, options)
 		r = append(r, scanResult)
 	}
 	return r, nil
@@ -115,16 +184,22 @@ func (s *ScanSpecs) ScanLicenseText() ([]*ScanResult, error) {
 
 // ScanLicenseText scans the specified license file to retrieve license information
 func (s *ScanSpec) ScanLicenseText(licenseLibrary *licenses.LicenseLibrary, resultsCache map[normalizer.Digest]*ScanResult) *ScanResult {
+	return s.scanText(context.Background(), s.LicenseText, licenseLibrary, resultsCache, identifier.Options{})
+}
+
+// scanText identifies the licenses in the text and sets the CycloneDX licenses of the result.
+// When the options.FileTimeout passes, the result has an error.
+func (s *ScanSpec) scanText(ctx context.Context, text string, licenseLibrary *licenses.LicenseLibrary, resultsCache map[normalizer.Digest]*ScanResult, options identifier.Options) *ScanResult {
 	// create a scanResult with the specifications and licenseText
 	r := &ScanResult{
 		Spec:              *s,
-		OriginalText:      s.LicenseText,
+		OriginalText:      text,
 		CycloneDXLicenses: Licenses{},
 	}
 
 	// instantiate normalizedData with the input license text
 	normalizedData := normalizer.NormalizationData{
-		OriginalText: s.LicenseText,
+		OriginalText: text,
 	}
 
 	// normalize the input license text
@@ -139,71 +214,223 @@ func (s *ScanSpec) ScanLicenseText(licenseLibrary *licenses.LicenseLibrary, resu
 
 	// check the cache in memory if we have seen the same license before
 	// return the result if it exists in the cache to avoid running identification for it
+	// the copy keeps the spec and text of this scan
 	if cachedResult, ok := resultsCache[*r.Hash]; ok {
-		return cachedResult
+		cached := *cachedResult
+		cached.Spec = *s
+		cached.OriginalText = text
+		return &cached
 	}
 
 	// find the licenses in the normalized text and return a list of SPDX IDs
 	// in case of an error, return as much as we have along with an error
-	results, err := identifier.Identify(identifier.Options{}, licenseLibrary, normalizedData)
+	fileCtx := ctx
+	if options.FileTimeout > 0 {
+		var cancel context.CancelFunc
+		fileCtx, cancel = context.WithTimeout(ctx, options.FileTimeout)
+		defer cancel()
+	}
+	results, err := identifier.IdentifyContext(fileCtx, options, licenseLibrary, &normalizedData)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out (> %v): %w", options.FileTimeout, err)
+	}
 	if err != nil {
 		r.Error = err
 		return r
 	}
 
+	r.CycloneDXLicenses = newLicenses(licenseLibrary, options.ExpressionOrder, results)
+
+	// populate the results cache to keep the match in memory for next license match
+	resultsCache[*r.Hash] = r
+
+	return r
+}
+
+// newLicenses returns the license expression composed from the results (see identifier.ComposeExpression), or NOASSERTION if no licenses were found.
+// A single license is returned as a license with its name, URL and text.
+func newLicenses(licenseLibrary *licenses.LicenseLibrary, order identifier.ExpressionOrder, results ...identifier.IdentifierResults) Licenses {
+	return newExpressionLicenses(licenseLibrary, identifier.ComposeExpression(licenseLibrary.LicenseMap, order, results...))
+}
+
+// newExpressionLicenses returns the license expression, or NOASSERTION if it is nil.
+// A single license is returned as a license with its name, URL and text.
+func newExpressionLicenses(licenseLibrary *licenses.LicenseLibrary, e *expression.Expression) Licenses {
 	// if the results are empty, add unknown as the SPDX ID
-	if len(results.Matches) == 0 {
+	if e == nil {
 		// Add NOASSERTION to the LicenseChoice of the SPDX Name for this scan
-		r.CycloneDXLicenses = append(r.CycloneDXLicenses, cyclonedx.LicenseChoice{
-			License: &cyclonedx.License{
-				Name: NOASSERTION_SPDX_NAME,
-			},
-		})
-	} else {
-		// iterate over the list of matches and maintain the unique list of SPDX IDs in the result
-		for id := range results.Matches {
-			// Add an SPDX ID from the match
-			// update the LicenseChoice to include each new match
-
-			// Add suffix of (family) to the name, if we have a family
-			family := licenseLibrary.LicenseMap[id].LicenseInfo.Family
-			name := licenseLibrary.LicenseMap[id].LicenseInfo.Name
-			if family != "" {
-				name = fmt.Sprintf("%s (%s)", name, family)
-			}
-			r.CycloneDXLicenses = append(r.CycloneDXLicenses, cyclonedx.LicenseChoice{
+		return Licenses{
+			{
 				License: &cyclonedx.License{
-					ID:   id,
-					Name: name,
-					// TODO: verify whether this is acceptable or just expect a single license here
-					URL: strings.Join(licenseLibrary.LicenseMap[id].LicenseInfo.URLs, ","),
-					Text: &cyclonedx.AttachedText{
-						Content:     licenseLibrary.LicenseMap[id].Text.Content,
-						ContentType: licenseLibrary.LicenseMap[id].Text.ContentType,
-						Encoding:    licenseLibrary.LicenseMap[id].Text.Encoding,
-					},
+					Name: NOASSERTION_SPDX_NAME,
 				},
-			})
-
+			},
 		}
 	}
 
-	// populate the results cache to keep the match in memory for next license match
-	resultsCache[*r.Hash] = r
+	// a compound expression, or a license with "+" or an exception, is an expression
+	id := e.String()
+	lic, ok := licenseLibrary.LicenseMap[id]
+	if !ok {
+		if e.IsLicense() && e.Exception == "" && !e.OrLater {
+			// a license which is not in the library (e.g. a LicenseRef-) has no name, URL or text
+			return Licenses{{License: &cyclonedx.License{Name: id}}}
+		}
+		return Licenses{{Expression: id}}
+	}
 
-	return r
+	// Add suffix of (family) to the name, if we have a family
+	name := lic.LicenseInfo.Name
+	if family := lic.LicenseInfo.Family; family != "" {
+		name = fmt.Sprintf("%s (%s)", name, family)
+	}
+	return Licenses{
+		{
+			License: &cyclonedx.License{
+				ID:   id,
+				Name: name,
+				// TODO: verify whether this is acceptable or just expect a single license here
+				URL: strings.Join(lic.LicenseInfo.URLs, ","),
+				Text: &cyclonedx.AttachedText{
+					Content:     lic.Text.Content,
+					ContentType: lic.Text.ContentType,
+					Encoding:    lic.Text.Encoding,
+				},
+			},
+		},
+	}
 }
 
 // ScanFile looks up a specific file by name to retrieve license data.
 // If the license data is not available, scan the specified file,
 // persist the scanned result into a datastore, and return the license data.
-func (s *ScanSpecs) ScanFile() []*ScanResult {
+// The Location of each spec is read from the local filesystem and may be a file or a directory.
+// Errors for a spec are returned in its ScanResult.
+func (s *ScanSpecs) ScanFile() ([]*ScanResult, error) {
+	return s.ScanFileContext(context.Background())
+}
+
+// ScanFileContext is ScanFile, which stops when the context is canceled or its deadline passes.
+// The specs which were not scanned have the error of the context in their ScanResult.
+// The files of a directory or archive which have an error, are unreadable, or time out (see the fileTimeout flag) are not included in its CycloneDX licenses,
+// and are listed in the Error of its ScanResult.
+func (s *ScanSpecs) ScanFileContext(ctx context.Context) ([]*ScanResult, error) {
+	licenseLibrary, options, err := s.newLicenseLibrary()
+	if err != nil {
+		return nil, err
+	}
+
 	var r []*ScanResult
-	// identify license information for each specified package
+
+	// resultsCache is a local cache holding the results of scanned files (keyed by the normalized text hash)
+	resultsCache := make(map[normalizer.Digest]*ScanResult)
+
+	// identify license information for each specified file or directory
 	for _, p := range s.Specs {
-		r = append(r, &ScanResult{
-			Spec: p,
-		})
+		r = append(r, p.scanFile(ctx, licenseLibrary, resultsCache, options, s.manifestType(p)))
+	}
+	return r, nil
+}
+
+// ScanFile scans the file or directory at the Location of the spec to retrieve license information.
+// For a directory or an archive (when the archiveDepth flag is not 0), the CycloneDX licenses are the licenses found in any of its files.
+func (s *ScanSpec) ScanFile(licenseLibrary *licenses.LicenseLibrary, resultsCache map[normalizer.Digest]*ScanResult) *ScanResult {
+	return s.scanFile(context.Background(), licenseLibrary, resultsCache, identifier.Options{}, "")
+}
+
+// manifestType returns the manifest type of the PURL of the spec, or else of the package manager or language of the specs, or "" for any manifest
+func (s *ScanSpecs) manifestType(spec ScanSpec) manifest.Type {
+	purlType, _, _ := strings.Cut(strings.TrimPrefix(strings.ToLower(spec.PURL), "pkg:"), "/")
+	for _, name := range []string{purlType, s.PackageManager} {
+		if t, ok := manifest.ParseType(name); ok {
+			return t
+		}
+	}
+	return languageManifests[strings.ToLower(strings.TrimSpace(s.Language))]
+}
+
+// scanFile scans the file or directory of the spec. Only the licenses declared by manifests of the manifest type, or of any type when it is "", are declared.
+func (s *ScanSpec) scanFile(ctx context.Context, licenseLibrary *licenses.LicenseLibrary, resultsCache map[normalizer.Digest]*ScanResult, options identifier.Options, manifestType manifest.Type) *ScanResult {
+	if s.Location == "" {
+		return &ScanResult{Spec: *s, Error: fmt.Errorf("no location specified for %q", s.Name)}
+	}
+	if u, err := url.Parse(s.Location); err == nil && len(u.Scheme) > 1 && u.Scheme != "file" {
+		return &ScanResult{Spec: *s, Error: fmt.Errorf("unsupported location %q (only local files and directories can be scanned)", s.Location)}
+	}
+	location := strings.TrimPrefix(s.Location, "file://")
+
+	fi, err := os.Stat(location)
+	if err != nil {
+		return &ScanResult{Spec: *s, Error: err}
+	}
+
+	if fi.IsDir() || (options.ArchiveLimits.MaxDepth > 0 && archive.IsArchive(location)) {
+		var results []identifier.IdentifierResults
+		if fi.IsDir() {
+			results, err = identifier.IdentifyLicensesInDirectoryContext(ctx, location, options, licenseLibrary)
+		} else {
+			results, err = identifier.IdentifyLicensesInArchiveContext(ctx, location, options, licenseLibrary)
+		}
+		if err != nil {
+			return &ScanResult{Spec: *s, Error: err}
+		}
+		r := &ScanResult{
+			Spec:              *s,
+			Hash:              s.Hash,
+			Error:             fileErrors(results),
+			CycloneDXLicenses: newLicenses(licenseLibrary, options.ExpressionOrder, results...),
+		}
+		r.setDeclaredLicenses(licenseLibrary, manifestType, results...)
+		return r
+	}
+
+	if fi.Size() > maxFileSize {
+		return &ScanResult{Spec: *s, Error: fmt.Errorf("file %v is too large (%v > %v)", location, fi.Size(), maxFileSize)}
+	}
+	b, err := os.ReadFile(location)
+	if err != nil {
+		return &ScanResult{Spec: *s, Error: err}
 	}
+	// a cached result may be for another file with the same text, so the declared licenses are always set,
+	// on a copy which keeps them out of the cache
+	scanned := *s.scanText(ctx, string(b), licenseLibrary, resultsCache, options)
+	r := &scanned
+	result := identifier.IdentifierResults{File: location}
+	if m, err := manifest.Parse(location, b); err == nil && m != nil {
+		result.Declared = m.Resolve(identifier.ManifestIndex(licenseLibrary))
+	}
+	r.setDeclaredLicenses(licenseLibrary, manifestType, result)
 	return r
 }
+
+// fileErrors returns an error with the notes of the files which had an error or were unreadable, or nil if there are none
+func fileErrors(results []identifier.IdentifierResults) error {
+	var notes []string
+	for _, result := range results {
+		if result.Status == identifier.StatusError || result.Status == identifier.StatusUnreadable {
+			notes = append(notes, fmt.Sprintf("%v: %v", result.File, result.Notes))
+		}
+	}
+	if len(notes) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%v of %v files not scanned (%v)", len(notes), len(results), strings.Join(notes, "; "))
+}
+
+// setDeclaredLicenses sets the licenses declared by the manifests of the manifest type (of any type when it is ""),
+// joined with AND, and their discrepancies
+func (r *ScanResult) setDeclaredLicenses(licenseLibrary *licenses.LicenseLibrary, manifestType manifest.Type, results ...identifier.IdentifierResults) {
+	r.DeclaredLicenses, r.Discrepancies = nil, nil
+	var declared []*expression.Expression
+	for _, result := range results {
+		if d := result.Declared; d != nil && (manifestType == "" || d.Type == manifestType) {
+			declared = append(declared, d.Expression)
+			for _, discrepancy := range d.Discrepancies {
+				r.Discrepancies = append(r.Discrepancies, fmt.Sprintf("%v: %v", result.File, discrepancy))
+			}
+		}
+	}
+	if e := expression.Join(expression.And, declared...); e != nil {
+		r.DeclaredLicenses = newExpressionLicenses(licenseLibrary, e)
+	}
+}
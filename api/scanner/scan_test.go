@@ -5,8 +5,12 @@
 package scanner_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/CycloneDX/cyclonedx-go"
@@ -202,32 +206,301 @@ func TestScanSpec_ScanLicenseText_With_CachedResults(t *testing.T) {
 }
 
 func TestScanSpecs_ScanFile(t *testing.T) {
-	async_specs := scanner.ScanSpec{
-		Name:     "async",
+	zeroBSDFile := scanner.ScanSpec{
+		Name:     "0BSD.txt",
+		Location: "../../testdata/addAll/input/text/0BSD.txt",
+	}
+	zeroBSDDir := scanner.ScanSpec{
+		Name:     "text",
+		Location: "../../testdata/addAll/input/text",
+	}
+	missing := scanner.ScanSpec{
+		Name:     "missing",
+		Location: "../../testdata/addAll/bogus/LICENSE",
+	}
+	remote := scanner.ScanSpec{
 		Version:  "3.2.2",
 		Location: "https://github.com/caolan/async/",
 	}
-	sps := []struct {
-		name           string
-		spec           *scanner.ScanSpecs
-		expectedResult []*scanner.ScanResult
-	}{{
-		name: "scan license file",
-		spec: &scanner.ScanSpecs{
-			PackageManager: "npm",
-			Specs:          []scanner.ScanSpec{async_specs},
+	noLocation := scanner.ScanSpec{
+		Name: "async",
+	}
+
+	tests := []struct {
+		name    string
+		spec    scanner.ScanSpec
+		wantIDs []string
+		wantErr bool
+	}{
+		{name: "scan license file", spec: zeroBSDFile, wantIDs: []string{"0BSD"}},
+		{name: "scan directory", spec: zeroBSDDir, wantIDs: []string{"0BSD"}},
+		{name: "file not found", spec: missing, wantErr: true},
+		{name: "remote location", spec: remote, wantErr: true},
+		{name: "no location", spec: noLocation, wantErr: true},
+	}
+
+	specs := &scanner.ScanSpecs{PackageManager: "npm"}
+	for _, tt := range tests {
+		specs.Specs = append(specs.Specs, tt.spec)
+	}
+	actualResults, err := specs.WithFlags(configurer.NewDefaultFlags()).ScanFile()
+	if err != nil {
+		t.Fatalf("ScanFile() error = %v", err)
+	}
+	if len(actualResults) != len(tests) {
+		t.Fatalf("Expected %d scan results but got %d", len(tests), len(actualResults))
+	}
+
+	for i, tt := range tests {
+		actualResult := actualResults[i]
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.spec, actualResult.Spec); d != "" {
+				t.Errorf("Didn't get expected spec: %s", fmt.Sprintf("(-want, +got): %s", d))
+			}
+			if (actualResult.Error != nil) != tt.wantErr {
+				t.Fatalf("Error = %v, wantErr %v", actualResult.Error, tt.wantErr)
+			}
+			var actualIDs []string
+			for _, l := range actualResult.CycloneDXLicenses {
+				actualIDs = append(actualIDs, l.License.ID)
+			}
+			if d := cmp.Diff(tt.wantIDs, actualIDs); d != "" {
+				t.Errorf("Didn't get expected License IDs: %s", fmt.Sprintf("(-want, +got): %s", d))
+			}
+		})
+	}
+}
+
+func TestScanSpecs_ScanFile_DeclaredLicenses(t *testing.T) {
+	dir := t.TempDir()
+	text, err := os.ReadFile("../../testdata/addAll/input/text/0BSD.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"LICENSE":      string(text),
+		"package.json": `{"name": "foo", "license": "MIT License"}`,
+		"pom.xml":      `<project><licenses><license><url>https://spdx.org/licenses/0BSD.html</url></license></licenses></project>`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name              string
+		specs             scanner.ScanSpecs
+		wantIDs           []string
+		wantDiscrepancies []string
+	}{
+		{
+			name:              "package manager",
+			specs:             scanner.ScanSpecs{PackageManager: "npm", Specs: []scanner.ScanSpec{{Name: "foo", Location: dir}}},
+			wantIDs:           []string{"MIT"},
+			wantDiscrepancies: []string{"declared MIT is not detected in a license file", "detected 0BSD in " + filepath.Join(dir, "LICENSE") + " is not declared"},
 		},
-		expectedResult: []*scanner.ScanResult{{
-			Spec: async_specs,
-		}},
-	}}
-
-	for _, sp := range sps {
-		t.Run(sp.name, func(t *testing.T) {
-			actualResult := sp.spec.ScanFile()
-			if d := cmp.Diff(sp.expectedResult, actualResult); d != "" {
-				t.Errorf("Didn't get expected License match: %s", fmt.Sprintf("(-want, +got): %s", d))
+		{
+			name:    "PURL",
+			specs:   scanner.ScanSpecs{PackageManager: "npm", Specs: []scanner.ScanSpec{{Name: "foo", Location: dir, PURL: "pkg:maven/org.example/foo@1.0"}}},
+			wantIDs: []string{"0BSD"},
+		},
+		{
+			name:  "language",
+			specs: scanner.ScanSpecs{Language: "Python", Specs: []scanner.ScanSpec{{Name: "foo", Location: dir}}},
+		},
+		{
+			name:    "manifest file",
+			specs:   scanner.ScanSpecs{Specs: []scanner.ScanSpec{{Name: "foo", Location: filepath.Join(dir, "pom.xml")}}},
+			wantIDs: []string{"0BSD"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.specs.WithFlags(configurer.NewDefaultFlags()).ScanFile()
+			if err != nil {
+				t.Fatalf("ScanFile() error = %v", err)
+			}
+			if results[0].Error != nil {
+				t.Fatalf("ScanFile() result error = %v", results[0].Error)
+			}
+			var ids []string
+			for _, l := range results[0].DeclaredLicenses {
+				ids = append(ids, l.License.ID)
+			}
+			if d := cmp.Diff(tt.wantIDs, ids); d != "" {
+				t.Errorf("Didn't get expected declared License IDs: (-want, +got): %v", d)
+			}
+			var discrepancies []string
+			for _, d := range results[0].Discrepancies {
+				if strings.HasPrefix(d, filepath.Join(dir, "package.json")+": ") {
+					discrepancies = append(discrepancies, strings.TrimPrefix(d, filepath.Join(dir, "package.json")+": "))
+				}
+			}
+			if d := cmp.Diff(tt.wantDiscrepancies, discrepancies); d != "" {
+				t.Errorf("Didn't get expected discrepancies: (-want, +got): %v", d)
 			}
 		})
 	}
 }
+
+func TestScanSpec_ScanFile_DeclaredLicensesNotCached(t *testing.T) {
+	dir := t.TempDir()
+	pom := filepath.Join(dir, "pom.xml")
+	if err := os.WriteFile(pom, []byte(`<project><licenses><license><url>https://spdx.org/licenses/0BSD.html</url></license></licenses></project>`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := configurer.InitConfig(configurer.NewDefaultFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenseLibrary, err := licenses.NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("Error initializing license library %v", err.Error())
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("Error adding licenses %v", err.Error())
+	}
+
+	cache := make(map[normalizer.Digest]*scanner.ScanResult)
+	spec := scanner.ScanSpec{Name: "foo", Location: pom}
+	if r := spec.ScanFile(licenseLibrary, cache); r.Error != nil || len(r.DeclaredLicenses) != 1 {
+		t.Fatalf("ScanFile() error = %v, declared licenses = %v", r.Error, r.DeclaredLicenses)
+	}
+	for _, cached := range cache {
+		if cached.DeclaredLicenses != nil {
+			t.Errorf("expected the cached result to have no declared licenses but got %v", cached.DeclaredLicenses)
+		}
+	}
+}
+
+func TestScanSpecs_ScanLicenseText_With_CacheDir(t *testing.T) {
+	cacheDir := t.TempDir()
+	flags := configurer.NewDefaultFlags()
+	_ = flags.Set(configurer.ConfigPathFlag, "../../testdata/config/")
+	_ = flags.Set(configurer.CacheDirFlag, cacheDir)
+	scanSpecs := scanner.ScanSpecs{
+		Specs: []scanner.ScanSpec{{LicenseText: "Licensed under the Apache License, Version 2.0"}},
+	}
+
+	first, err := scanSpecs.WithFlags(flags).ScanLicenseText()
+	if err != nil {
+		t.Fatalf("ScanLicenseText() error = %v", err)
+	}
+	entries, err := filepath.Glob(filepath.Join(cacheDir, "*", "*", first[0].Hash.Sha256+"-*"))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one cache entry for %v got %v %v", first[0].Hash.Sha256, entries, err)
+	}
+
+	// a new call reads the persisted entry and returns the same results
+	second, err := scanSpecs.WithFlags(flags).ScanLicenseText()
+	if err != nil {
+		t.Fatalf("ScanLicenseText() error = %v", err)
+	}
+	if d := cmp.Diff(first, second); d != "" {
+		t.Errorf("Didn't get the same results from the cache: %s", fmt.Sprintf("(-want, +got): %s", d))
+	}
+}
+
+func TestScanSpecs_ScanLicenseText_Expression(t *testing.T) {
+	tests := []struct {
+		name  string
+		order string
+		text  string
+		want  scanner.Licenses
+	}{
+		{
+			name: "tag",
+			text: "// SPDX-License-Identifier: MIT OR Apache-2.0\n",
+			want: scanner.Licenses{{Expression: "MIT OR Apache-2.0"}},
+		},
+		{
+			name:  "tag in ID order",
+			order: "id",
+			text:  "// SPDX-License-Identifier: MIT OR Apache-2.0\n",
+			want:  scanner.Licenses{{Expression: "Apache-2.0 OR MIT"}},
+		},
+		{
+			name: "independent tags",
+			text: "// SPDX-License-Identifier: MIT\n// SPDX-License-Identifier: LicenseRef-mine\n",
+			want: scanner.Licenses{{Expression: "MIT AND LicenseRef-mine"}},
+		},
+		{
+			name: "LicenseRef",
+			text: "// SPDX-License-Identifier: LicenseRef-mine\n",
+			want: scanner.Licenses{{License: &cyclonedx.License{Name: "LicenseRef-mine"}}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			flags := configurer.NewDefaultFlags()
+			_ = flags.Set(configurer.SpdxFlag, "")
+			if tt.order != "" {
+				_ = flags.Set(configurer.ExpressionOrderFlag, tt.order)
+			}
+			specs := &scanner.ScanSpecs{Specs: []scanner.ScanSpec{{LicenseText: tt.text}}}
+			results, err := specs.WithFlags(flags).ScanLicenseText()
+			if err != nil {
+				t.Fatalf("ScanLicenseText() error = %v", err)
+			}
+			if d := cmp.Diff(tt.want, results[0].CycloneDXLicenses); d != "" {
+				t.Errorf("Didn't get expected licenses: (-want, +got): %v", d)
+			}
+		})
+	}
+
+	flags := configurer.NewDefaultFlags()
+	_ = flags.Set(configurer.ExpressionOrderFlag, "random")
+	if _, err := (&scanner.ScanSpecs{}).WithFlags(flags).ScanLicenseText(); err == nil {
+		t.Error("ScanLicenseText() expected an error for an invalid expression order")
+	}
+}
+
+func TestScanSpecs_ScanFileContext(t *testing.T) {
+	flags := configurer.NewDefaultFlags()
+	_ = flags.Set(configurer.SpdxFlag, "")
+	specs := &scanner.ScanSpecs{Specs: []scanner.ScanSpec{{Name: "text", Location: "../../testdata/addAll/input/text"}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := specs.WithFlags(flags).ScanFileContext(ctx)
+	if err != nil {
+		t.Fatalf("ScanFileContext() error = %v", err)
+	}
+	if !errors.Is(results[0].Error, context.Canceled) {
+		t.Errorf("ScanFileContext() with a canceled context result error = %v want %v", results[0].Error, context.Canceled)
+	}
+
+	_ = flags.Set(configurer.FileTimeoutFlag, "1ns")
+	results, err = (&scanner.ScanSpecs{Specs: []scanner.ScanSpec{{LicenseText: "MIT License"}}}).WithFlags(flags).ScanLicenseTextContext(context.Background())
+	if err != nil {
+		t.Fatalf("ScanLicenseTextContext() error = %v", err)
+	}
+	if !errors.Is(results[0].Error, context.DeadlineExceeded) {
+		t.Errorf("ScanLicenseTextContext() with a file timeout result error = %v want %v", results[0].Error, context.DeadlineExceeded)
+	}
+}
+
+func TestScanSpecs_ScanFile_FileErrors(t *testing.T) {
+	flags := configurer.NewDefaultFlags()
+	_ = flags.Set(configurer.SpdxFlag, "")
+	_ = flags.Set(configurer.FileTimeoutFlag, "1ns")
+	results, err := (&scanner.ScanSpecs{Specs: []scanner.ScanSpec{{Name: "dir", Location: "../../testdata/addAll/input"}}}).WithFlags(flags).ScanFile()
+	if err != nil {
+		t.Fatalf("ScanFile() error = %v", err)
+	}
+	if err := results[0].Error; err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("ScanFile() of a directory with timed out files result error = %v want the timed out files", err)
+	}
+
+	_ = flags.Set(configurer.FileTimeoutFlag, "0")
+	results, err = (&scanner.ScanSpecs{Specs: []scanner.ScanSpec{{Name: "dir", Location: "../../testdata/addAll/input"}}}).WithFlags(flags).ScanFile()
+	if err != nil {
+		t.Fatalf("ScanFile() error = %v", err)
+	}
+	if err := results[0].Error; err != nil {
+		t.Errorf("ScanFile() of a directory result error = %v want nil", err)
+	}
+}
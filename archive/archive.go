@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Separator separates the name of an archive from the path of an entry in it, e.g. "lib/foo.jar!/META-INF/LICENSE"
+const Separator = "!/"
+
+var (
+	// ErrLimit is returned (wrapped) when an archive has more entries or decompressed bytes than the Limits allow
+	ErrLimit = errors.New("archive limit exceeded")
+	// ErrTooLarge is the Err (wrapped) of an entry larger than the MaxFileBytes
+	ErrTooLarge = errors.New("file too large")
+	// ErrTooDeep is the Err (wrapped) of an archive nested deeper than the MaxDepth
+	ErrTooDeep = errors.New("archive nested too deeply")
+)
+
+// DefaultLimits are used for any Limits field which is 0, except MaxDepth
+var DefaultLimits = Limits{MaxDepth: 5, MaxEntries: 10000, MaxBytes: 1 << 30, MaxFileBytes: 1000000}
+
+var (
+	zipSuffixes = []string{".zip", ".jar", ".war", ".ear", ".whl", ".nupkg"}
+	tarSuffixes = []string{".tar"}
+	tgzSuffixes = []string{".tar.gz", ".tgz"}
+)
+
+// Limits protect a walk from archive bombs
+type Limits struct {
+	// MaxDepth is how many levels of archives are expanded, e.g. 2 expands a jar in a zip (0 expands none)
+	MaxDepth int
+	// MaxEntries limits the number of files in an archive, including the files in nested archives
+	MaxEntries int
+	// MaxBytes limits the decompressed bytes read from an archive, including nested archives
+	MaxBytes int64
+	// MaxFileBytes limits the size of each file which is read. Larger files are walked without content.
+	MaxFileBytes int64
+}
+
+// Entry is a file in an archive
+type Entry struct {
+	// Name is the virtual path of the file, e.g. "lib/foo.jar!/META-INF/LICENSE"
+	Name string
+	Size int64
+	// Content is nil when the file was not read (see Err)
+	Content []byte
+	// Err explains why the file was not read, wrapping ErrTooLarge or ErrTooDeep
+	Err error
+}
+
+// IsArchive is true if the name has the extension of a supported archive (zip, jar, war, ear, whl, nupkg, tar, tar.gz, or tgz)
+func IsArchive(name string) bool {
+	return hasSuffix(name, zipSuffixes) || hasSuffix(name, tarSuffixes) || hasSuffix(name, tgzSuffixes)
+}
+
+// walker holds the limits and the entries and bytes read so far
+type walker struct {
+	limits  Limits
+	entries int
+	bytes   int64
+	fn      func(Entry) error
+}
+
+// Walk calls fn for each file in the archive, expanding nested archives up to the MaxDepth.
+// It stops with an error wrapping ErrLimit when the archive has too many entries or decompressed bytes.
+func Walk(name string, b []byte, limits Limits, fn func(Entry) error) error {
+	w := newWalker(limits, fn)
+	return w.walk(name, bytes.NewReader(b), int64(len(b)), 1)
+}
+
+// WalkFile walks the archive file at the path (see Walk). Entry names start with the path.
+func WalkFile(path string, limits Limits, fn func(Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	w := newWalker(limits, fn)
+	return w.walk(path, f, fi.Size(), 1)
+}
+
+func newWalker(limits Limits, fn func(Entry) error) *walker {
+	if limits.MaxEntries <= 0 {
+		limits.MaxEntries = DefaultLimits.MaxEntries
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = DefaultLimits.MaxBytes
+	}
+	if limits.MaxFileBytes <= 0 {
+		limits.MaxFileBytes = DefaultLimits.MaxFileBytes
+	}
+	return &walker{limits: limits, fn: fn}
+}
+
+// walk calls fn for each file in the archive, or for the archive itself when it is too deep
+func (w *walker) walk(name string, r io.ReaderAt, size int64, depth int) error {
+	if depth > w.limits.MaxDepth {
+		return w.fn(Entry{Name: name, Size: size, Err: fmt.Errorf("%w (> %v)", ErrTooDeep, w.limits.MaxDepth)})
+	}
+	switch {
+	case hasSuffix(name, zipSuffixes):
+		return w.walkZip(name, r, size, depth)
+	case hasSuffix(name, tarSuffixes):
+		return w.walkTar(name, io.NewSectionReader(r, 0, size), depth)
+	case hasSuffix(name, tgzSuffixes):
+		gr, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+		defer gr.Close()
+		return w.walkTar(name, gr, depth)
+	default:
+		return fmt.Errorf("%v: not a supported archive", name)
+	}
+}
+
+func (w *walker) walkZip(name string, r io.ReaderAt, size int64, depth int) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%v: %w", name, err)
+	}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return fmt.Errorf("%v: %w", name+Separator+zf.Name, err)
+		}
+		err = w.entry(name+Separator+zf.Name, int64(zf.UncompressedSize64), rc, depth)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkTar(name string, r io.Reader, depth int) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := w.entry(name+Separator+strings.TrimPrefix(hdr.Name, "./"), hdr.Size, tr, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// entry reads a file from an archive and walks it if it is an archive. Sizes from headers are not trusted.
+func (w *walker) entry(name string, size int64, r io.Reader, depth int) error {
+	if w.entries++; w.entries > w.limits.MaxEntries {
+		return fmt.Errorf("%v: %w (more than %v entries)", name, ErrLimit, w.limits.MaxEntries)
+	}
+
+	nested := IsArchive(name)
+	if nested && depth >= w.limits.MaxDepth {
+		return w.fn(Entry{Name: name, Size: size, Err: fmt.Errorf("%w (> %v)", ErrTooDeep, w.limits.MaxDepth)})
+	}
+	limit := w.limits.MaxFileBytes
+	if nested {
+		limit = w.limits.MaxBytes - w.bytes
+	}
+	if size > limit && !nested {
+		return w.fn(Entry{Name: name, Size: size, Err: fmt.Errorf("%w (%v > %v)", ErrTooLarge, size, w.limits.MaxFileBytes)})
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return fmt.Errorf("%v: %w", name, err)
+	}
+	if w.bytes += int64(len(b)); w.bytes > w.limits.MaxBytes {
+		return fmt.Errorf("%v: %w (more than %v bytes)", name, ErrLimit, w.limits.MaxBytes)
+	}
+	if int64(len(b)) > limit {
+		return w.fn(Entry{Name: name, Size: int64(len(b)), Err: fmt.Errorf("%w (> %v)", ErrTooLarge, w.limits.MaxFileBytes)})
+	}
+	if nested {
+		return w.walk(name, bytes.NewReader(b), int64(len(b)), depth+1)
+	}
+	return w.fn(Entry{Name: name, Size: int64(len(b)), Content: b})
+}
+
+func hasSuffix(name string, suffixes []string) bool {
+	lower := strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func zipOf(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func tgzOf(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write(content)
+	}
+	_ = tw.Close()
+	_ = gw.Close()
+	return b.Bytes()
+}
+
+// walkAll returns the content or notes of each entry by name
+func walkAll(t *testing.T, name string, b []byte, limits Limits) (map[string]string, error) {
+	t.Helper()
+	got := make(map[string]string)
+	err := Walk(name, b, limits, func(e Entry) error {
+		if e.Err != nil {
+			if !errors.Is(e.Err, ErrTooLarge) && !errors.Is(e.Err, ErrTooDeep) {
+				t.Errorf("unexpected entry error %v", e.Err)
+			}
+			got[e.Name] = "note: " + e.Err.Error()
+		} else {
+			got[e.Name] = string(e.Content)
+		}
+		return nil
+	})
+	return got, err
+}
+
+func TestIsArchive(t *testing.T) {
+	t.Parallel()
+	for name, want := range map[string]bool{
+		"a.zip": true, "lib/foo.JAR": true, "x.whl": true, "x.nupkg": true, "x.tar": true, "x.tar.gz": true, "x.tgz": true,
+		"LICENSE": false, "x.gz": false, "zip": false,
+	} {
+		if got := IsArchive(name); got != want {
+			t.Errorf("IsArchive(%q) = %v want %v", name, got, want)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+	jar := zipOf(t, map[string][]byte{"META-INF/LICENSE": []byte("jar license"), "META-INF/": nil})
+	src := tgzOf(t, map[string][]byte{"./b/COPYING": []byte("tgz license")})
+	outer := zipOf(t, map[string][]byte{"lib/foo.jar": jar, "src.tgz": src, "big.txt": bytes.Repeat([]byte("x"), 12), "README": []byte("readme")})
+
+	tests := []struct {
+		name   string
+		limits Limits
+		want   map[string]string
+	}{
+		{
+			name:   "nested archives",
+			limits: Limits{MaxDepth: 2, MaxFileBytes: 11},
+			want: map[string]string{
+				"a.zip!/lib/foo.jar!/META-INF/LICENSE": "jar license",
+				"a.zip!/src.tgz!/b/COPYING":            "tgz license",
+				"a.zip!/big.txt":                       "note: file too large (12 > 11)",
+				"a.zip!/README":                        "readme",
+			},
+		},
+		{
+			name:   "nested too deeply",
+			limits: Limits{MaxDepth: 1, MaxFileBytes: 11},
+			want: map[string]string{
+				"a.zip!/lib/foo.jar": "note: archive nested too deeply (> 1)",
+				"a.zip!/src.tgz":     "note: archive nested too deeply (> 1)",
+				"a.zip!/big.txt":     "note: file too large (12 > 11)",
+				"a.zip!/README":      "readme",
+			},
+		},
+		{
+			name:   "top level too deep",
+			limits: Limits{},
+			want:   map[string]string{"a.zip": "note: archive nested too deeply (> 0)"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := walkAll(t, "a.zip", outer, tt.limits)
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("Walk() Diff(-want +got) = %v", d)
+			}
+		})
+	}
+}
+
+func TestWalk_limits(t *testing.T) {
+	t.Parallel()
+	files := map[string][]byte{"a": []byte("12345"), "b": []byte("67890"), "c": []byte("abcde")}
+	b := zipOf(t, files)
+
+	if _, err := walkAll(t, "x.zip", b, Limits{MaxDepth: 1, MaxEntries: 2}); !errors.Is(err, ErrLimit) || !strings.Contains(err.Error(), "entries") {
+		t.Errorf("Walk() with too many entries error = %v want %v", err, ErrLimit)
+	}
+	if _, err := walkAll(t, "x.zip", b, Limits{MaxDepth: 1, MaxBytes: 12}); !errors.Is(err, ErrLimit) || !strings.Contains(err.Error(), "bytes") {
+		t.Errorf("Walk() with too many bytes error = %v want %v", err, ErrLimit)
+	}
+	if _, err := walkAll(t, "x.zip", []byte("not a zip"), Limits{MaxDepth: 1}); err == nil || errors.Is(err, ErrLimit) {
+		t.Errorf("Walk() of a corrupt archive error = %v", err)
+	}
+	if got, err := walkAll(t, "x.zip", b, Limits{MaxDepth: 1, MaxEntries: 3, MaxBytes: 15}); err != nil || len(got) != len(files) {
+		t.Errorf("Walk() at the limits = %v, %v", got, err)
+	}
+}
+
+func TestWalkFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "src.tar.gz")
+	if err := os.WriteFile(path, tgzOf(t, map[string][]byte{"LICENSE": []byte("license")}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	if err := WalkFile(path, Limits{MaxDepth: 1}, func(e Entry) error {
+		got = append(got, e.Name)
+		return nil
+	}); err != nil {
+		t.Fatalf("WalkFile() error = %v", err)
+	}
+	if d := cmp.Diff([]string{path + Separator + "LICENSE"}, got); d != "" {
+		t.Errorf("WalkFile() Diff(-want +got) = %v", d)
+	}
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"sync"
+)
+
+// Cache stores scan results by key. Implementations must be safe for concurrent use.
+// A cache is specific to one license library, so a new cache is needed when the library changes.
+type Cache interface {
+	// Get returns the value for the key, or false if the key is not in the cache
+	Get(key string) ([]byte, bool, error)
+	// Put stores the value for the key, replacing any existing value
+	Put(key string, value []byte) error
+}
+
+// MemoryCache is a Cache which is held in memory for the life of the process
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string][]byte
+}
+
+// NewMemoryCache returns an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string][]byte)}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.entries[key]
+	return value, ok, nil
+}
+
+func (c *MemoryCache) Put(key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = value
+	return nil
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package cache
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	fingerprint1 = strings.Repeat("1", 64)
+	fingerprint2 = strings.Repeat("2", 64)
+)
+
+func testCache(t *testing.T, c Cache) {
+	t.Helper()
+	if _, ok, err := c.Get("abc-1"); ok || err != nil {
+		t.Fatalf("Get() of a missing key = %v, %v", ok, err)
+	}
+	if err := c.Put("abc-1", []byte("one")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := c.Put("abc-1", []byte("two")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	value, ok, err := c.Get("abc-1")
+	if !ok || err != nil || string(value) != "two" {
+		t.Fatalf("Get() = %q, %v, %v want two", value, ok, err)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+	testCache(t, NewMemoryCache())
+}
+
+func TestFileCache(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	c, err := NewFileCache(dir, fingerprint1)
+	if err != nil {
+		t.Fatalf("NewFileCache() error = %v", err)
+	}
+	testCache(t, c)
+
+	if _, err := os.Stat(filepath.Join(dir, fingerprint1, "ab", "abc-1")); err != nil {
+		t.Errorf("expected entry file: %v", err)
+	}
+	if err := c.Put("../escape", []byte("bad")); err == nil {
+		t.Errorf("expected error for invalid key")
+	}
+
+	// a cache with the same fingerprint reads existing entries
+	reopened, err := NewFileCache(dir, fingerprint1)
+	if err != nil {
+		t.Fatalf("NewFileCache() error = %v", err)
+	}
+	if value, ok, _ := reopened.Get("abc-1"); !ok || string(value) != "two" {
+		t.Errorf("expected entry to persist got %q, %v", value, ok)
+	}
+}
+
+func TestFileCache_Prune(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	other := filepath.Join(dir, "not-a-fingerprint")
+	if err := os.Mkdir(other, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewFileCache(dir, fingerprint1)
+	if err != nil {
+		t.Fatalf("NewFileCache() error = %v", err)
+	}
+	if err := c.Put("abc-1", []byte("one")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// a new library fingerprint removes stale entries, but nothing else
+	c, err = NewFileCache(dir, fingerprint2)
+	if err != nil {
+		t.Fatalf("NewFileCache() error = %v", err)
+	}
+	if _, ok, _ := c.Get("abc-1"); ok {
+		t.Errorf("expected a miss for a new fingerprint")
+	}
+	if _, err := os.Stat(filepath.Join(dir, fingerprint1)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected stale fingerprint dir to be removed got %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("expected other dir to be kept got %v", err)
+	}
+
+	if _, err := NewFileCache(dir, "bogus"); err == nil {
+		t.Errorf("expected error for invalid fingerprint")
+	}
+}
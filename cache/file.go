@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	fingerprintRE = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	keyRE         = regexp.MustCompile(`^[0-9A-Za-z._-]{3,}$`)
+)
+
+// FileCache is a Cache stored as one file per entry in a directory for each license library fingerprint.
+// Entries are in <dir>/<fingerprint>/<first 2 characters of the key>/<key>.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a FileCache in dir for the license library with the fingerprint (see licenses.LicenseLibrary.Fingerprint).
+// Entries for other fingerprints are stale, so they are removed.
+func NewFileCache(dir string, fingerprint string) (*FileCache, error) {
+	if !fingerprintRE.MatchString(fingerprint) {
+		return nil, fmt.Errorf("invalid cache fingerprint %q", fingerprint)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, fingerprint), 0o755); err != nil {
+		return nil, err
+	}
+	if err := prune(dir, fingerprint); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: filepath.Join(dir, fingerprint)}, nil
+}
+
+// prune removes the entries for every fingerprint except the current one
+func prune(dir string, fingerprint string) error {
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, de := range des {
+		if de.IsDir() && de.Name() != fingerprint && fingerprintRE.MatchString(de.Name()) {
+			if err := os.RemoveAll(filepath.Join(dir, de.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *FileCache) path(key string) (string, error) {
+	if !keyRE.MatchString(key) {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+	return filepath.Join(c.dir, key[:2], key), nil
+}
+
+func (c *FileCache) Get(key string) ([]byte, bool, error) {
+	p, err := c.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+	value, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Put writes the entry to a temporary file and renames it, so that readers never see a partial entry
+func (c *FileCache) Put(key string, value []byte) error {
+	p, err := c.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(p), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(value); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), p); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/policy"
+	"github.com/CycloneDX/license-scanner/reporter"
+)
+
+// errIntroduced is returned when licenses or copyrights were introduced since the --baseline, without a --policy
+var errIntroduced = errors.New("licenses or copyrights were introduced since the baseline")
+
+// loadBaseline returns the JSON report of the --baseline flag, or nil
+func loadBaseline(cfg *viper.Viper) (*reporter.Report, error) {
+	baselineFile := cfg.GetString(configurer.BaselineFlag)
+	if baselineFile == "" {
+		return nil, nil
+	}
+	baseline, err := reporter.ReadReportFile(baselineFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%v: %w", configurer.BaselineFlag, err)
+	}
+	return &baseline, nil
+}
+
+// checkResults prints the changes from the baseline, if any, and enforces the policy.
+// With a baseline, only the licenses added to each file since the baseline are checked.
+func checkResults(cfg *viper.Viper, p *policy.Policy, baseline *reporter.Report, licenseLibrary *licenses.LicenseLibrary, results ...identifier.IdentifierResults) error {
+	if baseline == nil {
+		return enforcePolicy(cfg, p, licenseLibrary, results...)
+	}
+	changes := reporter.NewReport(results, reportOptions(cfg, licenseLibrary, baseline)).Changes
+	printChanges(os.Stderr, changes)
+
+	introduced := make(map[string]reporter.FileChange)
+	for _, c := range changes {
+		if c.Introduces() {
+			introduced[c.File] = c
+		}
+	}
+	_, basePath := scanBase(cfg)
+	var introducing []identifier.IdentifierResults
+	var violations []policy.Violation
+	for _, r := range results {
+		c, ok := introduced[reporter.RelativeFile(basePath, r.File)]
+		if !ok {
+			continue
+		}
+		introducing = append(introducing, r)
+		if p == nil || len(c.AddedLicenses) == 0 {
+			continue
+		}
+		// the file's expression is evaluated, so that an added license may still be a choice in an OR, but only the added licenses are violations
+		for _, v := range p.Evaluate(licenseLibrary.LicenseMap, basePath, r) {
+			if isAddedLicense(v.License, c.AddedLicenses) {
+				violations = append(violations, v)
+			}
+		}
+	}
+	if p != nil {
+		sort.SliceStable(violations, func(i, j int) bool { return violations[i].File < violations[j].File })
+		printViolations(os.Stderr, violations)
+		return policy.Check(violations)
+	}
+	if len(introducing) > 0 {
+		return fmt.Errorf("%w: %v files", errIntroduced, len(introducing))
+	}
+	return nil
+}
+
+// isAddedLicense is true if the license of a violation (e.g. "GPL-2.0-or-later WITH Classpath-exception-2.0") is one of the added license IDs
+func isAddedLicense(license string, added []string) bool {
+	if slices.Contains(added, license) {
+		return true
+	}
+	e, err := expression.Parse(license)
+	return err == nil && e.IsLicense() && slices.Contains(added, e.License)
+}
+
+// printChanges prints the files whose licenses or copyrights changed from the baseline
+func printChanges(w io.Writer, changes []reporter.FileChange) {
+	if len(changes) == 0 {
+		return
+	}
+	introducing := 0
+	for _, c := range changes {
+		if c.Introduces() {
+			introducing++
+		}
+	}
+	fmt.Fprintf(w, "\nBASELINE CHANGES: %v files, %v introduce licenses or copyrights\n", len(changes), introducing)
+	for _, c := range changes {
+		if c.RenamedFrom != "" {
+			fmt.Fprintf(w, "\t%-8v\t%v (from %v)\n", c.Change, c.File, c.RenamedFrom)
+		} else {
+			fmt.Fprintf(w, "\t%-8v\t%v\n", c.Change, c.File)
+		}
+		for _, l := range []struct {
+			prefix string
+			what   string
+			values []string
+		}{
+			{"+", "license", c.AddedLicenses},
+			{"-", "license", c.RemovedLicenses},
+			{"~", "license", c.ChangedLicenses},
+			{"+", "copyright", c.AddedCopyrights},
+			{"-", "copyright", c.RemovedCopyrights},
+		} {
+			for _, v := range l.values {
+				fmt.Fprintf(w, "\t\t%v %v %v\n", l.prefix, l.what, v)
+			}
+		}
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func newCompileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "compile SNAPSHOT",
+		SilenceUsage: true,
+		Short:        "Compile the license library into a snapshot for fast startup",
+		Long: `
+Load the SPDX and custom license templates, normalize and compile their patterns,
+and write the license library to a snapshot file. Scans load the snapshot with --library
+instead of reading and normalizing the templates again.
+
+A snapshot is only loaded with the same --spdx, --spdxPath, --custom, and --customPath
+resources it was compiled from. It is stale, and must be compiled again, when those
+resources change or the snapshot version changes.
+
+Example usage:
+
+    $ license-scanner compile library.snapshot
+    $ license-scanner scan dir ./src --library library.snapshot
+		`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+			if err != nil {
+				return err
+			}
+			if err := licenseLibrary.AddAll(); err != nil {
+				return err
+			}
+			if err := licenseLibrary.WriteSnapshotFile(args[0]); err != nil {
+				return err
+			}
+			Logger.Infof("Compiled %v licenses into %v", len(licenseLibrary.LicenseMap), args[0])
+			return nil
+		},
+	}
+	configurer.AddCompileFlags(cmd.Flags())
+	return cmd
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/debugger"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func newExplainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "explain FILE LICENSE",
+		SilenceUsage: true,
+		Short:        "Explain why a license does or does not match a file",
+		Long: `
+Scan a file for one license of the library. When the license matches, print its matches.
+Otherwise, print the differences between the normalized text of each primary pattern of the
+license (-) and the normalized text of the file (+).
+
+Example usage:
+
+    $ license-scanner explain LICENSE Apache-2.0
+		`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return explainLicense(cmd.Context(), cmd.OutOrStdout(), cfg, args[0], args[1])
+		},
+	}
+	configurer.AddExplainFlags(cmd.Flags())
+	return cmd
+}
+
+// explainLicense writes the matches of a license in a file, or the differences with its patterns when it does not match
+func explainLicense(ctx context.Context, w io.Writer, cfg *viper.Viper, f string, id string) error {
+	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	if err != nil {
+		return err
+	}
+	if err := licenseLibrary.Load(); err != nil {
+		return err
+	}
+	license, ok := licenseLibrary.LicenseMap[id]
+	if !ok {
+		return fmt.Errorf("unknown license %q (see the list command)", id)
+	}
+
+	results, err := identifier.IdentifyLicensesInFileContext(ctx, f, identifier.Options{ForceResult: true}, licenseLibrary)
+	if err != nil {
+		return err
+	}
+	if results.Status.Skipped() {
+		return fmt.Errorf("%v was not scanned: %v", f, results.Notes)
+	}
+	if matches := results.Matches[id]; len(matches) > 0 {
+		fmt.Fprintf(w, "\nFOUND LICENSE MATCHES: %v\n", f)
+		printMatches(w, map[string][]identifier.Match{id: matches})
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nNO LICENSE MATCHES: %v does not match %v\n", f, id)
+	diffs, err := debugger.DebugLicenseMatchFailure(license, results.NormalizedText)
+	if err != nil {
+		return err
+	}
+	for i, diff := range diffs {
+		fmt.Fprintf(w, "\nPATTERN %v: %v (-pattern +file)\n%v", i, license.PrimaryPatterns[i].FileName, diff)
+	}
+	return nil
+}
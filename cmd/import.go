@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/importer"
+)
+
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "import DIR",
+		SilenceUsage: true,
+		Short:        "Validate, prepare, and import the license templates of a directory",
+		Long: `
+Validate the SPDX license templates (or custom license patterns) of a directory, generate their
+precheck files, and copy them to the one non-default --spdx, --spdxPath, --custom, or --customPath destination.
+
+Example usage:
+
+    $ license-scanner import ~/Downloads/license-list-data-3.xx --spdx my3.xx
+		`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+			cfg.Set(configurer.AddAllFlag, args[0])
+			return importer.Import(cfg)
+		},
+	}
+	configurer.AddImportFlags(cmd.Flags())
+	return cmd
+}
+
+func newUpdateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "update",
+		SilenceUsage: true,
+		Short:        "Validate the imported license templates and generate their precheck files again",
+		Long: `
+Validate the license templates of the one non-default --spdx, --spdxPath, --custom, or --customPath
+destination, and update their precheck files in place.
+
+Example usage:
+
+    $ license-scanner update --spdx my3.xx
+		`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+			cfg.Set(configurer.UpdateAllFlag, true)
+			return importer.Update(cfg)
+		},
+	}
+	configurer.AddUpdateFlags(cmd.Flags())
+	return cmd
+}
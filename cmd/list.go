@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "list",
+		SilenceUsage: true,
+		Short:        "List the licenses and exceptions of the license library",
+		Long: `
+List the SPDX and custom licenses and exceptions of the license library in markdown.
+
+Example usage:
+
+    $ license-scanner list --spdx default > resources/LIST.md
+		`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return listLicenses(cfg)
+		},
+	}
+	configurer.AddListFlags(cmd.Flags())
+	return cmd
+}
+
+// y returns "Y" for true and " " for false to make readable table cells
+func y(isIt bool) string {
+	if isIt {
+		return "Y"
+	} else {
+		return " "
+	}
+}
+
+func listLicenses(cfg *viper.Viper) error {
+	lics, deprecatedLics, exceptions, deprecatedExceptions, spdxVersion, err := licenses.List(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("## Licenses")
+	fmt.Printf("| %v | %v | %v | %v | %v | %v |\n", "ID", "Name", "Family", "Templates", "OSI Approved", "FSF Libre")
+	fmt.Println("| :--- | :--- | :--- | ---: | :---: | :---: |")
+	for _, l := range lics {
+		fmt.Printf("| %v | %v | %v | %v | %v | %v |\n", l.ID, l.Name, l.Family, l.NumTemplates, y(l.IsOSIApproved), y(l.IsFSFLibre))
+	}
+
+	fmt.Println("## Exceptions")
+	fmt.Printf("| %v | %v | %v | %v |\n", "ID", "Name", "Family", "Templates")
+	fmt.Println("| :--- | :--- | :--- | ---: |")
+	for _, e := range exceptions {
+		fmt.Printf("| %v | %v | %v | %v |\n", e.ID, e.Name, e.Family, e.NumTemplates)
+	}
+
+	fmt.Println("## Deprecated Licenses")
+	fmt.Printf("| %v | %v | %v | %v | %v | %v |\n", "ID", "Name", "Family", "Templates", "OSI Approved", "FSF Libre")
+	fmt.Println("| :--- | :--- | :--- | ---: | :---: | :---: |")
+	for _, l := range deprecatedLics {
+		fmt.Printf("| %v | %v | %v | %v | %v | %v |\n", l.ID, l.Name, l.Family, l.NumTemplates, y(l.IsOSIApproved), y(l.IsFSFLibre))
+	}
+
+	fmt.Println("## Deprecated Exceptions")
+	fmt.Printf("| %v | %v | %v | %v |\n", "ID", "Name", "Family", "Templates")
+	fmt.Println("| :--- | :--- | :--- | ---: |")
+	for _, e := range deprecatedExceptions {
+		fmt.Printf("| %v | %v | %v | %v |\n", e.ID, e.Name, e.Family, e.NumTemplates)
+	}
+
+	var licenseListVersion string
+	if spdxVersion != "" {
+		licenseListVersion = fmt.Sprintf("  (SPDX license list %v)", spdxVersion)
+	}
+	fmt.Println("## Runtime Configuration")
+	fmt.Printf("  * spdx/%v%v\n", cfg.GetString(configurer.SpdxFlag), licenseListVersion)
+	fmt.Printf("  * custom/%v\n", cfg.GetString(configurer.CustomFlag))
+	fmt.Println()
+	fmt.Println("## License Library")
+	fmt.Printf("| %v | %v |\n", "Type", "Count")
+	fmt.Printf("| :--- | ---: |\n")
+	fmt.Printf("| Licenses              | %v |\n", len(lics))
+	fmt.Printf("| Exceptions            | %v |\n", len(exceptions))
+	fmt.Printf("| Deprecated Licenses   | %v |\n", len(deprecatedLics))
+	fmt.Printf("| Deprecated Exceptions | %v |\n", len(deprecatedExceptions))
+	fmt.Printf("\n###### Generated on %v\n", time.Now().Format(time.RFC3339))
+	return nil
+}
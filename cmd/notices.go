@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+
+	"github.com/CycloneDX/license-scanner/archive"
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/reporter"
+)
+
+func newNoticesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "notices PATH",
+		SilenceUsage: true,
+		Short:        "Write a third-party notices document for a directory or an archive",
+		Long: `
+Scan a directory, or an archive, and write a third-party notices document: each license found, with the files
+which have it and their copyright holders, the license text once per license, and the contents of the NOTICE files.
+The document is rendered as text, markdown, or html (see --output) with a Go template, which --template overrides.
+
+Example usage:
+
+    $ license-scanner notices ./dist --outputFile THIRD_PARTY_NOTICES.txt
+    $ license-scanner notices app.jar --output html --outputFile notices.html
+    $ license-scanner notices . --output markdown --template notices.md.tmpl
+		`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return writeNotices(cmd.Context(), cfg, args[0])
+		},
+	}
+	configurer.AddNoticesFlags(cmd.Flags())
+	return cmd
+}
+
+// writeNotices scans a directory or a file (the files of an archive), and writes the notices of the results
+func writeNotices(ctx context.Context, cfg *viper.Viper, p string) error {
+	format := cfg.GetString(configurer.OutputFlag)
+	if !slices.Contains(reporter.NoticesFormats, format) {
+		return fmt.Errorf("invalid --%v %q (expected one of: %v)", configurer.OutputFlag, format, strings.Join(reporter.NoticesFormats, ", "))
+	}
+	var tmpl string
+	if templateFile := cfg.GetString(configurer.TemplateFlag); templateFile != "" {
+		b, err := os.ReadFile(templateFile)
+		if err != nil {
+			return fmt.Errorf("invalid --%v: %w", configurer.TemplateFlag, err)
+		}
+		tmpl = string(b)
+	}
+	fileInfo, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	if fileInfo.IsDir() {
+		cfg.Set(configurer.DirFlag, p)
+	} else {
+		cfg.Set(configurer.FileFlag, p)
+	}
+	// The copyright holders are listed for each license
+	cfg.Set(configurer.CopyrightsFlag, true)
+
+	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	if err != nil {
+		return err
+	}
+	if err := licenseLibrary.Load(); err != nil {
+		return err
+	}
+	options, err := getCommandLineOptions(cfg, licenseLibrary)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, err := scanContext(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var results []identifier.IdentifierResults
+	switch {
+	case fileInfo.IsDir():
+		results, err = identifier.IdentifyLicensesInDirectoryContext(ctx, p, options, licenseLibrary)
+	case options.ArchiveLimits.MaxDepth > 0 && archive.IsArchive(p):
+		results, err = identifier.IdentifyLicensesInArchiveContext(ctx, p, options, licenseLibrary)
+	default:
+		var result identifier.IdentifierResults
+		result, err = identifier.IdentifyLicensesInFileContext(ctx, p, options, licenseLibrary)
+		results = append(results, result)
+	}
+	if err != nil {
+		return scanError(cfg, err)
+	}
+
+	notices := reporter.NewNotices(results, licenseLibrary, reportOptions(cfg, licenseLibrary, nil))
+	return writeOutput(cfg, func(w io.Writer) error {
+		return reporter.WriteNotices(w, notices, format, tmpl)
+	})
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/policy"
+	"github.com/CycloneDX/license-scanner/reuse"
+)
+
+// Exit codes of license-scanner
+const (
+	// ExitError is the exit code of an invalid command or a failed scan
+	ExitError = 1
+	// ExitDenied is the exit code of a scan which found a license denied by the --policy
+	ExitDenied = 2
+	// ExitReview is the exit code of a scan which found a license which needs review by the --policy, and no denied license
+	ExitReview = 3
+	// ExitIntroduced is the exit code of a scan which found licenses or copyrights not in the --baseline, without a --policy
+	ExitIntroduced = 4
+	// ExitNotCompliant is the exit code of reuse-lint for a project which does not comply with the REUSE specification
+	ExitNotCompliant = 5
+)
+
+// ExitCode returns the exit code for the error of a command
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, policy.ErrDenied):
+		return ExitDenied
+	case errors.Is(err, policy.ErrReview):
+		return ExitReview
+	case errors.Is(err, errIntroduced):
+		return ExitIntroduced
+	case errors.Is(err, reuse.ErrNotCompliant):
+		return ExitNotCompliant
+	default:
+		return ExitError
+	}
+}
+
+// loadPolicy returns the policy of the --policy flag, or nil
+func loadPolicy(cfg *viper.Viper) (*policy.Policy, error) {
+	policyFile := cfg.GetString(configurer.PolicyFlag)
+	if policyFile == "" {
+		return nil, nil
+	}
+	return policy.Load(policyFile)
+}
+
+// enforcePolicy prints the violations of the policy, if any, to stderr and returns a policy error for them
+func enforcePolicy(cfg *viper.Viper, p *policy.Policy, licenseLibrary *licenses.LicenseLibrary, results ...identifier.IdentifierResults) error {
+	if p == nil {
+		return nil
+	}
+	_, basePath := scanBase(cfg)
+	violations := p.Evaluate(licenseLibrary.LicenseMap, basePath, results...)
+	printViolations(os.Stderr, violations)
+	return policy.Check(violations)
+}
+
+// printViolations prints the licenses which are denied or need review, with the file and the rule which decided them
+func printViolations(w io.Writer, violations []policy.Violation) {
+	if len(violations) == 0 {
+		return
+	}
+	counts := policy.Count(violations)
+	fmt.Fprintf(w, "\nPOLICY VIOLATIONS: %v denied, %v to review\n", counts[policy.Deny], counts[policy.Review])
+	for _, v := range violations {
+		fmt.Fprintf(w, "\t%-6v\t%v\t%v (%v)\n", v.Decision, v.License, v.File, v.Rule)
+	}
+	fmt.Fprintln(w)
+}
+
+// scanBase returns the document name and the base path of the scanned --dir or --file
+func scanBase(cfg *viper.Viper) (string, string) {
+	if dir := cfg.GetString(configurer.DirFlag); dir != "" {
+		return filepath.Base(filepath.Clean(dir)), dir
+	} else if f := cfg.GetString(configurer.FileFlag); f != "" {
+		return filepath.Base(f), filepath.Dir(f)
+	}
+	return "", ""
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/filter"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/reuse"
+)
+
+func newReuseLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "reuse-lint [DIR]",
+		SilenceUsage: true,
+		Short:        "Check that a project complies with the REUSE specification",
+		Long: `
+Check that the files of a directory (default ".") comply with the REUSE specification (https://reuse.software/spec/):
+every file has an SPDX-License-Identifier and a copyright notice in its header, in a FILE.license sidecar,
+or in an annotation of REUSE.toml or .reuse/dep5, every license has a text in the LICENSES directory
+(e.g. LICENSES/MIT.txt), and every license text is used. The report is written as text or json,
+and the exit code is 5 when the project is not compliant.
+
+Example usage:
+
+    $ license-scanner reuse-lint
+    $ license-scanner reuse-lint ./project --output json --outputFile reuse.json
+		`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return lintReuse(cmd.Context(), cfg, dir)
+		},
+	}
+	configurer.AddReuseLintFlags(cmd.Flags())
+	return cmd
+}
+
+// lintReuse writes the REUSE compliance report of a directory, and returns an error if the project is not compliant
+func lintReuse(ctx context.Context, cfg *viper.Viper, dir string) error {
+	output := cfg.GetString(configurer.OutputFlag)
+	if output != configurer.OutputText && output != configurer.OutputJSON {
+		return fmt.Errorf("invalid --%v %q (expected %v or %v)", configurer.OutputFlag, output, configurer.OutputText, configurer.OutputJSON)
+	}
+	f := filter.Filter{
+		Include:  cfg.GetStringSlice(configurer.IncludeFlag),
+		Exclude:  cfg.GetStringSlice(configurer.ExcludeFlag),
+		NoIgnore: cfg.GetBool(configurer.NoIgnoreFlag),
+	}
+	for flag, patterns := range map[string][]string{configurer.IncludeFlag: f.Include, configurer.ExcludeFlag: f.Exclude} {
+		if err := filter.ValidatePatterns(patterns); err != nil {
+			return fmt.Errorf("invalid --%v %w", flag, err)
+		}
+	}
+
+	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	if err != nil {
+		return err
+	}
+	if err := licenseLibrary.Load(); err != nil {
+		return err
+	}
+	ctx, cancel, err := scanContext(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	report, err := reuse.Lint(ctx, dir, f, licenseLibrary.LicenseMap)
+	if err != nil {
+		return scanError(cfg, err)
+	}
+
+	if err := writeOutput(cfg, func(w io.Writer) error {
+		if output == configurer.OutputJSON {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}
+		printReuseReport(w, report)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return report.Check()
+}
+
+// printReuseReport prints each kind of problem found with the files which have it, and a summary
+func printReuseReport(w io.Writer, r *reuse.Report) {
+	for _, section := range []struct {
+		title string
+		files map[string][]string
+	}{
+		{"BAD LICENSES", r.BadLicenses},
+		{"DEPRECATED LICENSES", r.DeprecatedLicenses},
+		{"INVALID LICENSE EXPRESSIONS", r.InvalidExpressions},
+		{"MISSING LICENSE TEXTS", r.MissingLicenseTexts},
+	} {
+		if len(section.files) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%v:\n", section.title)
+		keys := make([]string, 0, len(section.files))
+		for key := range section.files {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, "\t%v\t%v\n", key, strings.Join(section.files[key], ", "))
+		}
+	}
+	for _, section := range []struct {
+		title string
+		files []string
+	}{
+		{"UNUSED LICENSE TEXTS", r.UnusedLicenseTexts},
+		{"MISSING LICENSING INFORMATION", r.MissingLicensing},
+		{"MISSING COPYRIGHT INFORMATION", r.MissingCopyright},
+		{"READ ERRORS", r.ReadErrors},
+	} {
+		if len(section.files) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%v:\n", section.title)
+		for _, file := range section.files {
+			fmt.Fprintf(w, "\t%v\n", file)
+		}
+	}
+
+	fmt.Fprintf(w, "\nREUSE SUMMARY: %v files\n", r.Files)
+	fmt.Fprintf(w, "\tBad licenses:\t%v\n", len(r.BadLicenses))
+	fmt.Fprintf(w, "\tDeprecated licenses:\t%v\n", len(r.DeprecatedLicenses))
+	fmt.Fprintf(w, "\tInvalid license expressions:\t%v\n", len(r.InvalidExpressions))
+	fmt.Fprintf(w, "\tMissing license texts:\t%v\n", len(r.MissingLicenseTexts))
+	fmt.Fprintf(w, "\tUnused license texts:\t%v\n", len(r.UnusedLicenseTexts))
+	fmt.Fprintf(w, "\tFiles without licensing information:\t%v\n", len(r.MissingLicensing))
+	fmt.Fprintf(w, "\tFiles without copyright information:\t%v\n", len(r.MissingCopyright))
+	if r.Compliant {
+		fmt.Fprintln(w, "\nThe project is REUSE compliant")
+	} else {
+		fmt.Fprintln(w, "\nThe project is not REUSE compliant")
+	}
+}
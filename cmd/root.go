@@ -3,22 +3,33 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/CycloneDX/license-scanner/archive"
 	"github.com/CycloneDX/license-scanner/configurer"
 	"github.com/CycloneDX/license-scanner/debugger"
+	"github.com/CycloneDX/license-scanner/expression"
 	"github.com/CycloneDX/license-scanner/identifier"
 	"github.com/CycloneDX/license-scanner/importer"
 	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/manifest"
+	"github.com/CycloneDX/license-scanner/reporter"
 	"github.com/CycloneDX/sbom-utility/log"
 
+	"github.com/CycloneDX/cyclonedx-go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -48,40 +59,56 @@ Scan files to detect licenses.
 
 Example usage to print copyrights, hash codes, and blocks found in file LICENSE.txt:
 
-    $ license-scanner -c -x -f LICENSE.txt
+    $ license-scanner scan file -c -x LICENSE.txt
 
 Example usage to scan LICENSE.txt, but only print the license IDs and positions of license matches:
 
-    $ license-scanner --quiet -f LICENSE.txt
+    $ license-scanner scan file --quiet LICENSE.txt
+
+Example usage to write the results for a directory as JSON:
+
+    $ license-scanner scan dir ./src --copyrights --output json --outputFile results.json
+
+Example usage to write a CycloneDX BOM with a file component for each file in a directory:
+
+    $ license-scanner scan dir ./src --copyrights --format cyclonedx-json
+
+Example usage to write an SPDX 2.3 tag-value document for a directory:
+
+    $ license-scanner scan dir ./src --copyrights --output spdx-tv --outputFile src.spdx
+
+Example usage to write SARIF for a code-scanning dashboard:
+
+    $ license-scanner scan dir . --format sarif --outputFile licenses.sarif
+
+Example usage to explain why the MIT license does or does not match a file:
+
+    $ license-scanner explain LICENSE.txt MIT
+
+Example usage to check that a project complies with the REUSE specification:
+
+    $ license-scanner reuse-lint .
+
+Example usage to write a third-party notices document for a distribution:
+
+    $ license-scanner notices ./dist --outputFile THIRD_PARTY_NOTICES.txt
 
 Please give us feedback at: https://github.com/CycloneDX/license-scanner/issues
 		`,
 		Args:    cobra.NoArgs,
 		Version: currentVersion,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := configurer.InitConfig(cmd.Flags())
+			// The flags of the root command are deprecated aliases of the subcommands
+			cfg, err := initConfig(cmd)
 			if err != nil {
-				Logger.Error(err)
 				return err
 			}
 
-			if cfg.GetBool(configurer.DebugFlag) {
-				Logger.SetLevel(log.DEBUG)
-			}
-
-			Logger.SetQuietMode(cfg.GetBool(configurer.QuietFlag))
-			if Logger.GetLevel() == log.DEBUG {
-				mapSettings := cfg.AllSettings()
-				formattedSettings, _ := json.MarshalIndent(mapSettings, "", "")
-				Logger.Debugf(" * Flags: %+v", string(formattedSettings))
-				Logger.DumpArgs()
-			}
-
 			f := cfg.GetString(configurer.FileFlag)
 			if f != "" {
-				return findLicensesInFile(cfg, f)
+				return findLicensesInFile(cmd.Context(), cfg, f)
 			} else if cfg.GetString(configurer.DirFlag) != "" {
-				return findLicensesInDirectory(cfg)
+				return findLicensesInDirectory(cmd.Context(), cfg)
 			} else if cfg.GetBool(configurer.ListFlag) {
 				return listLicenses(cfg)
 			} else if cfg.GetString(configurer.AddAllFlag) != "" {
@@ -90,202 +117,437 @@ Please give us feedback at: https://github.com/CycloneDX/license-scanner/issues
 				return importer.Update(cfg)
 			} else {
 				// Otherwise, terminate with an error.
-				err = Logger.Errorf("you must provide a command valid flag")
+				err = Logger.Errorf("you must provide a command")
 				cmd.Help()
 				return err
 			}
 		},
 	}
 	notGlobalInit(cmd)
+	cmd.AddCommand(newScanCmd())
+	cmd.AddCommand(newExplainCmd())
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newImportCmd())
+	cmd.AddCommand(newUpdateCmd())
+	cmd.AddCommand(newServeCmd())
+	cmd.AddCommand(newCompileCmd())
+	cmd.AddCommand(newReuseLintCmd())
+	cmd.AddCommand(newNoticesCmd())
+	cmd.AddCommand(newVersionCmd())
 	return cmd
 }
 
-// y returns "Y" for true and " " for false to make readable table cells
-func y(isIt bool) string {
-	if isIt {
-		return "Y"
-	} else {
-		return " "
-	}
+// deprecatedFlags are the flags of the root command which were replaced by a subcommand
+var deprecatedFlags = map[string]string{
+	configurer.FileFlag:      "scan file FILE",
+	configurer.DirFlag:       "scan dir DIR",
+	configurer.ListFlag:      "list",
+	configurer.AddAllFlag:    "import DIR",
+	configurer.UpdateAllFlag: "update",
+	configurer.LicenseFlag:   "explain FILE LICENSE",
 }
 
-func listLicenses(cfg *viper.Viper) error {
-	lics, deprecatedLics, exceptions, deprecatedExceptions, spdxVersion, err := licenses.List(cfg)
+// initConfig reads the config of a command, and sets the logging level and quiet mode from its flags
+func initConfig(cmd *cobra.Command) (*viper.Viper, error) {
+	cfg, err := configurer.InitConfig(cmd.Flags())
 	if err != nil {
-		return err
+		Logger.Error(err)
+		return nil, err
 	}
 
-	fmt.Println("## Licenses")
-	fmt.Printf("| %v | %v | %v | %v | %v | %v |\n", "ID", "Name", "Family", "Templates", "OSI Approved", "FSF Libre")
-	fmt.Println("| :--- | :--- | :--- | ---: | :---: | :---: |")
-	for _, l := range lics {
-		fmt.Printf("| %v | %v | %v | %v | %v | %v |\n", l.ID, l.Name, l.Family, l.NumTemplates, y(l.IsOSIApproved), y(l.IsFSFLibre))
+	if cfg.GetBool(configurer.DebugFlag) {
+		Logger.SetLevel(log.DEBUG)
 	}
 
-	fmt.Println("## Exceptions")
-	fmt.Printf("| %v | %v | %v | %v |\n", "ID", "Name", "Family", "Templates")
-	fmt.Println("| :--- | :--- | :--- | ---: |")
-	for _, e := range exceptions {
-		fmt.Printf("| %v | %v | %v | %v |\n", e.ID, e.Name, e.Family, e.NumTemplates)
+	Logger.SetQuietMode(cfg.GetBool(configurer.QuietFlag))
+	if output := cfg.GetString(configurer.OutputFlag); output != "" && output != configurer.OutputText && cfg.GetString(configurer.OutputFileFlag) == "" {
+		// Keep stdout parseable when it is used for a machine-readable report
+		Logger.SetQuietMode(true)
 	}
-
-	fmt.Println("## Deprecated Licenses")
-	fmt.Printf("| %v | %v | %v | %v | %v | %v |\n", "ID", "Name", "Family", "Templates", "OSI Approved", "FSF Libre")
-	fmt.Println("| :--- | :--- | :--- | ---: | :---: | :---: |")
-	for _, l := range deprecatedLics {
-		fmt.Printf("| %v | %v | %v | %v | %v | %v |\n", l.ID, l.Name, l.Family, l.NumTemplates, y(l.IsOSIApproved), y(l.IsFSFLibre))
+	if Logger.GetLevel() == log.DEBUG {
+		mapSettings := cfg.AllSettings()
+		formattedSettings, _ := json.MarshalIndent(mapSettings, "", "")
+		Logger.Debugf(" * Flags: %+v", string(formattedSettings))
+		Logger.DumpArgs()
 	}
+	return cfg, nil
+}
 
-	fmt.Println("## Deprecated Exceptions")
-	fmt.Printf("| %v | %v | %v | %v |\n", "ID", "Name", "Family", "Templates")
-	fmt.Println("| :--- | :--- | :--- | ---: |")
-	for _, e := range deprecatedExceptions {
-		fmt.Printf("| %v | %v | %v | %v |\n", e.ID, e.Name, e.Family, e.NumTemplates)
-	}
+// getCommandLineOptions returns the identifier options of the flags (see identifier.NewOptions), with the text blocks of the text output
+func getCommandLineOptions(cfg *viper.Viper, licenseLibrary *licenses.LicenseLibrary) (identifier.Options, error) {
+	options, err := identifier.NewOptions(cfg, licenseLibrary)
+	options.ForceResult = true
+	options.Enhancements.AddTextBlocks = true
+	return options, err
+}
 
-	var licenseListVersion string
-	if spdxVersion != "" {
-		licenseListVersion = fmt.Sprintf("  (SPDX license list %v)", spdxVersion)
+// scanContext returns the context for a scan, with the deadline of the --timeout flag
+func scanContext(ctx context.Context, cfg *viper.Viper) (context.Context, context.CancelFunc, error) {
+	timeout := cfg.GetDuration(configurer.TimeoutFlag)
+	if timeout < 0 {
+		return ctx, func() {}, fmt.Errorf("invalid --%v %v (expected 0 or more)", configurer.TimeoutFlag, timeout)
 	}
-	fmt.Println("## Runtime Configuration")
-	fmt.Printf("  * spdx/%v%v\n", cfg.GetString(configurer.SpdxFlag), licenseListVersion)
-	fmt.Printf("  * custom/%v\n", cfg.GetString(configurer.CustomFlag))
-	fmt.Println()
-	fmt.Println("## License Library")
-	fmt.Printf("| %v | %v |\n", "Type", "Count")
-	fmt.Printf("| :--- | ---: |\n")
-	fmt.Printf("| Licenses              | %v |\n", len(lics))
-	fmt.Printf("| Exceptions            | %v |\n", len(exceptions))
-	fmt.Printf("| Deprecated Licenses   | %v |\n", len(deprecatedLics))
-	fmt.Printf("| Deprecated Exceptions | %v |\n", len(deprecatedExceptions))
-	fmt.Printf("\n###### Generated on %v\n", time.Now().Format(time.RFC3339))
-	return nil
+	if timeout == 0 {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
 }
 
-func getCommandLineOptions(cfg *viper.Viper) (options identifier.Options) {
-	options = identifier.Options{
-		ForceResult: true,
-		Enhancements: identifier.Enhancements{
-			AddNotes:       "",
-			AddTextBlocks:  true,
-			FlagAcceptable: cfg.GetBool(configurer.AcceptableFlag),
-			FlagCopyrights: cfg.GetBool(configurer.CopyrightsFlag),
-			FlagKeywords:   cfg.GetBool(configurer.KeywordsFlag),
-		},
+// scanError explains an error which ends a scan, such as the --timeout
+func scanError(cfg *viper.Viper, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("scan timed out (> %v): %w", cfg.GetDuration(configurer.TimeoutFlag), err)
 	}
-	return
+	return err
 }
 
-func findLicensesInDirectory(cfg *viper.Viper) error {
+func findLicensesInDirectory(ctx context.Context, cfg *viper.Viper) error {
 	d := cfg.GetString(configurer.DirFlag)
+	if err := validateOutput(cfg); err != nil {
+		return err
+	}
+	licensePolicy, err := loadPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	baseline, err := loadBaseline(cfg)
+	if err != nil {
+		return err
+	}
 
 	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
 	if err != nil {
 		return err
 	}
-	if err := licenseLibrary.AddAll(); err != nil {
+	if err := licenseLibrary.Load(); err != nil {
 		return err
 	}
 	// retrieve command line options from flags
-	options := getCommandLineOptions(cfg)
+	options, err := getCommandLineOptions(cfg, licenseLibrary)
+	if err != nil {
+		return err
+	}
 
-	results, err := identifier.IdentifyLicensesInDirectory(d, options, licenseLibrary)
+	ctx, cancel, err := scanContext(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	results, err := identifier.IdentifyLicensesInDirectoryContext(ctx, d, options, licenseLibrary)
 	if err != nil {
+		return scanError(cfg, err)
+	}
+	if err := writeResults(cfg, licenseLibrary, baseline, results); err != nil {
 		return err
 	}
+	return checkResults(cfg, licensePolicy, baseline, licenseLibrary, results...)
+}
 
-	for _, result := range results {
-		if len(result.Matches) > 0 {
+// writeResults writes the results of each file of a directory or archive
+func writeResults(cfg *viper.Viper, licenseLibrary *licenses.LicenseLibrary, baseline *reporter.Report, results []identifier.IdentifierResults) error {
+	if cfg.GetString(configurer.OutputFlag) != configurer.OutputText {
+		return writeReport(cfg, licenseLibrary, baseline, results...)
+	}
 
-			// Print the matches by license ID in alphabetical order
-			fmt.Printf("\nFOUND LICENSE MATCHES: %v\n", result.File)
-			var found []string
-			for id := range result.Matches {
-				found = append(found, id)
-			}
-			sort.Strings(found)
-			for _, id := range found {
-				fmt.Printf("\tLicense ID:\t%v", id)
-				fmt.Println()
-				var prev identifier.Match
-				for _, m := range result.Matches[id] {
-					// Print if not same as prev
-					if m != prev {
-						fmt.Printf("\t\tbegins: %5v\tends: %5v\n", m.Begins, m.Ends)
-						prev = m
+	return writeOutput(cfg, func(w io.Writer) error {
+		for _, result := range results {
+			if len(result.Matches) > 0 {
+				fmt.Fprintf(w, "\nFOUND LICENSE MATCHES: %v\n", result.File)
+				printMatches(w, result.Matches)
+				printLicenseTags(w, result.LicenseTags, result.InvalidLicenseTags)
+				printLicenseExpression(w, result.LicenseExpression)
+				fmt.Fprintln(w)
+
+				if Logger.GetLevel() >= log.INFO {
+					for _, block := range result.Blocks {
+						Logger.Infof("%v :: %v", block.Matches, block.Text)
 					}
 				}
+			} else if !result.Status.Skipped() {
+				fmt.Fprintf(w, "\nNo licenses were found: %v\n", result.File)
+				printLicenseTags(w, nil, result.InvalidLicenseTags)
+			}
+			if result.NearMiss != nil {
+				printNearMiss(w, result.NearMiss, result.OriginalText)
 			}
-			fmt.Println()
+			printDeclared(w, result.File, result.Declared)
+		}
+		printCopyrightHolders(w, results)
+		printSkipped(w, results)
+		return nil
+	})
+}
 
-			if Logger.GetLevel() >= log.INFO {
-				for _, block := range result.Blocks {
-					Logger.Infof("%v :: %v", block.Matches, block.Text)
-				}
+// printCopyrightHolders prints the holders of the copyright statements of the results, merged by name, with their years, emails, and files
+func printCopyrightHolders(w io.Writer, results []identifier.IdentifierResults) {
+	holders := identifier.CopyrightHolders(results...)
+	if len(holders) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nCOPYRIGHT HOLDERS: %v\n", len(holders))
+	for _, h := range holders {
+		years := make([]string, 0, len(h.Years))
+		for _, y := range h.Years {
+			years = append(years, y.String())
+		}
+		fmt.Fprintf(w, "\t%v\t%v\t%v\t(%v files)\n", h.Name, strings.Join(years, ", "), strings.Join(h.Emails, ", "), len(h.Files))
+	}
+}
+
+// printSkipped prints the files which were not scanned with the reason, and the number of files with each status
+func printSkipped(w io.Writer, results []identifier.IdentifierResults) {
+	counts := make(map[identifier.FileStatus]int)
+	var skipped []identifier.IdentifierResults
+	for _, result := range results {
+		if result.Status.Skipped() {
+			counts[result.Status]++
+			skipped = append(skipped, result)
+		}
+	}
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nSKIPPED FILES: %v of %v\n", len(skipped), len(results))
+	for _, result := range skipped {
+		fmt.Fprintf(w, "\t%-18v\t%v: %v\n", result.Status, result.File, result.Notes)
+	}
+	var statuses []string
+	for status := range counts {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(w, "\t%v:\t%v\n", status, counts[identifier.FileStatus(status)])
+	}
+	fmt.Fprintln(w)
+}
+
+// printMatches prints the matches by license ID in alphabetical order
+func printMatches(w io.Writer, matches map[string][]identifier.Match) {
+	var found []string
+	for id := range matches {
+		found = append(found, id)
+	}
+	sort.Strings(found)
+	for _, id := range found {
+		fmt.Fprintf(w, "\tLicense ID:\t%v\n", id)
+		var prev identifier.Match
+		for _, m := range matches[id] {
+			// Print if not same as prev
+			if m.Begins != prev.Begins || m.Ends != prev.Ends {
+				fmt.Fprintf(w, "\t\tbegins: %5v\tends: %5v\tkind: %-10v\tconfidence: %.2f\tcoverage: %.2f\n", m.Begins, m.Ends, m.Kind, m.Confidence, m.Coverage)
+				prev = m
 			}
-		} else {
-			fmt.Printf("\nNo licenses were found: %v\n", result.File)
 		}
 	}
-	return nil
 }
 
-func findLicensesInFile(cfg *viper.Viper, f string) error {
+// printLicenseTags prints the license expression of each SPDX-License-Identifier tag, and the tags which are invalid
+func printLicenseTags(w io.Writer, tags []identifier.LicenseTag, invalid []identifier.InvalidLicenseTag) {
+	for _, t := range tags {
+		fmt.Fprintf(w, "\tLicense expression:\t%v\n\t\tbegins: %5v\tends: %5v\n", t.Expression, t.Begins, t.Ends)
+	}
+	for _, t := range invalid {
+		fmt.Fprintf(w, "\tInvalid license tag:\t%v (%v)\n\t\tbegins: %5v\tends: %5v\n", t.Value, t.Error, t.Begins, t.Ends)
+	}
+}
+
+// printLicenseExpression prints the license expression composed from the matches, if any
+func printLicenseExpression(w io.Writer, e *expression.Expression) {
+	if e != nil {
+		fmt.Fprintf(w, "\nLICENSE EXPRESSION:\t%v\n", e)
+	}
+}
+
+// printDeclared prints the license declared by a package manifest, if any, and its discrepancies
+func printDeclared(w io.Writer, file string, d *manifest.Declaration) {
+	if d == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nDECLARED LICENSES: %v (%v)\n", file, d.Type)
+	if d.Expression != nil {
+		fmt.Fprintf(w, "\tLicense expression:\t%v\n", d.Expression)
+	}
+	for _, discrepancy := range d.Discrepancies {
+		fmt.Fprintf(w, "\tDiscrepancy:\t%v\n", discrepancy)
+	}
+	fmt.Fprintln(w)
+}
+
+// printNearMiss prints the closest license and the differences between its template and the text
+func printNearMiss(w io.Writer, nearMiss *identifier.NearMiss, originalText string) {
+	fmt.Fprintf(w, "\nCLOSEST LICENSE:\t%v at %.0f%% similarity\n", nearMiss.LicenseId, nearMiss.Similarity*100)
+	for _, d := range nearMiss.Differences {
+		found := ""
+		if d.Begins >= 0 && d.Ends < len(originalText) {
+			found = originalText[d.Begins : d.Ends+1]
+		}
+		fmt.Fprintf(w, "\t\tbegins: %5v\tends: %5v\tfound: %q\texpected: %q\n", d.Begins, d.Ends, found, d.Expected)
+	}
+}
+
+// validateOutput returns an error for an unsupported --output format
+func validateOutput(cfg *viper.Viper) error {
+	output := cfg.GetString(configurer.OutputFlag)
+	if slices.Contains(configurer.OutputFormats, output) {
+		return nil
+	}
+	return fmt.Errorf("invalid --%v %q (expected one of: %v)", configurer.OutputFlag, output, strings.Join(configurer.OutputFormats, ", "))
+}
+
+// writeOutput calls write with stdout, or with the --outputFile if one was provided
+func writeOutput(cfg *viper.Viper, write func(w io.Writer) error) (err error) {
+	outputFile := cfg.GetString(configurer.OutputFileFlag)
+	if outputFile == "" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return write(f)
+}
+
+// reportOptions returns the report options of the scan, with the baseline report if any
+func reportOptions(cfg *viper.Viper, licenseLibrary *licenses.LicenseLibrary, baseline *reporter.Report) reporter.Options {
+	options := reporter.Options{
+		ToolName:              project,
+		ToolVersion:           currentVersion,
+		SPDXVersion:           licenseLibrary.SPDXVersion,
+		IncludeNormalizedText: cfg.GetBool(configurer.NormalizedFlag),
+		Baseline:              baseline,
+	}
+	options.DocumentName, options.BasePath = scanBase(cfg)
+	return options
+}
+
+// writeReport writes the results in the machine-readable --output format
+func writeReport(cfg *viper.Viper, licenseLibrary *licenses.LicenseLibrary, baseline *reporter.Report, results ...identifier.IdentifierResults) error {
+	options := reportOptions(cfg, licenseLibrary, baseline)
+	return writeOutput(cfg, func(w io.Writer) error {
+		switch cfg.GetString(configurer.OutputFlag) {
+		case configurer.OutputCycloneDXJSON:
+			return reporter.WriteCycloneDX(w, reporter.NewCycloneDXBOM(results, licenseLibrary, options), cyclonedx.BOMFileFormatJSON)
+		case configurer.OutputCycloneDXXML:
+			return reporter.WriteCycloneDX(w, reporter.NewCycloneDXBOM(results, licenseLibrary, options), cyclonedx.BOMFileFormatXML)
+		case configurer.OutputSPDXJSON:
+			return reporter.WriteSPDXJSON(w, reporter.NewSPDXDocument(results, licenseLibrary, options))
+		case configurer.OutputSPDXTagValue:
+			return reporter.WriteSPDXTagValue(w, reporter.NewSPDXDocument(results, licenseLibrary, options))
+		case configurer.OutputSARIF:
+			return reporter.WriteSARIF(w, reporter.NewSARIFLog(results, licenseLibrary, options))
+		default:
+			return reporter.WriteJSON(w, reporter.NewReport(results, options))
+		}
+	})
+}
+
+func findLicensesInFile(ctx context.Context, cfg *viper.Viper, f string) error {
 	Logger.Enter()
 	defer Logger.Exit()
 	startTime := time.Now().UnixMicro()
 	Logger.Info("Looking for all licenses")
+	if err := validateOutput(cfg); err != nil {
+		return err
+	}
+	licensePolicy, err := loadPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	baseline, err := loadBaseline(cfg)
+	if err != nil {
+		return err
+	}
 
 	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
 	if err != nil {
 		logScanTimeMS(startTime)
 		return err
 	}
-	if err := licenseLibrary.AddAll(); err != nil {
+	if err := licenseLibrary.Load(); err != nil {
 		logScanTimeMS(startTime)
 		return err
 	}
 
 	// retrieve command line options from flags
-	options := getCommandLineOptions(cfg)
+	options, err := getCommandLineOptions(cfg, licenseLibrary)
+	if err != nil {
+		logScanTimeMS(startTime)
+		return err
+	}
 
-	results, err := identifier.IdentifyLicensesInFile(f, options, licenseLibrary)
+	ctx, cancel, err := scanContext(ctx, cfg)
 	if err != nil {
 		logScanTimeMS(startTime)
 		return err
 	}
+	defer cancel()
 
-	licenseArg := cfg.GetString(configurer.LicenseFlag)
-	if len(results.Matches) > 0 {
+	if options.ArchiveLimits.MaxDepth > 0 && archive.IsArchive(f) {
+		results, err := identifier.IdentifyLicensesInArchiveContext(ctx, f, options, licenseLibrary)
+		if err == nil {
+			err = writeResults(cfg, licenseLibrary, baseline, results)
+		}
+		logScanTimeMS(startTime)
+		if err != nil {
+			return scanError(cfg, err)
+		}
+		return checkResults(cfg, licensePolicy, baseline, licenseLibrary, results...)
+	}
+
+	results, err := identifier.IdentifyLicensesInFileContext(ctx, f, options, licenseLibrary)
+	if err != nil {
+		logScanTimeMS(startTime)
+		return scanError(cfg, err)
+	}
 
-		// Print the matches by license ID in alphabetical order
-		fmt.Printf("\nFOUND LICENSE MATCHES:\n")
-		var found []string
-		for id := range results.Matches {
-			found = append(found, id)
+	licenseArg := cfg.GetString(configurer.LicenseFlag)
+	if cfg.GetString(configurer.OutputFlag) != configurer.OutputText {
+		if err := writeReport(cfg, licenseLibrary, baseline, results); err != nil {
+			logScanTimeMS(startTime)
+			return err
+		}
+	} else {
+		if len(results.Matches) == 0 && !results.Status.Skipped() {
+			Logger.Info("No licenses were found")
 		}
-		sort.Strings(found)
-		for _, id := range found {
-			fmt.Printf("\tLicense ID:\t%v", id)
-			fmt.Println()
-			var prev identifier.Match
-			for _, m := range results.Matches[id] {
-				// Print if not same as prev
-				if m != prev {
-					fmt.Printf("\t\tbegins: %5v\tends: %5v\n", m.Begins, m.Ends)
-					prev = m
+		if len(results.Matches) > 0 || len(results.InvalidLicenseTags) > 0 || results.NearMiss != nil || results.Declared != nil || len(results.Copyrights) > 0 || results.Status.Skipped() {
+			if err := writeOutput(cfg, func(w io.Writer) error {
+				if len(results.Matches) > 0 {
+					fmt.Fprintf(w, "\nFOUND LICENSE MATCHES:\n")
+					printMatches(w, results.Matches)
+					printLicenseTags(w, results.LicenseTags, results.InvalidLicenseTags)
+					printLicenseExpression(w, results.LicenseExpression)
+					fmt.Fprintln(w)
+				} else if len(results.InvalidLicenseTags) > 0 {
+					fmt.Fprintln(w)
+					printLicenseTags(w, nil, results.InvalidLicenseTags)
+				}
+				if results.NearMiss != nil {
+					printNearMiss(w, results.NearMiss, results.OriginalText)
 				}
+				printDeclared(w, results.File, results.Declared)
+				printCopyrightHolders(w, []identifier.IdentifierResults{results})
+				printSkipped(w, []identifier.IdentifierResults{results})
+				return nil
+			}); err != nil {
+				logScanTimeMS(startTime)
+				return err
 			}
 		}
-		fmt.Println()
 
-		if licenseArg == "" {
+		if licenseArg == "" && len(results.Matches) > 0 {
 			for _, block := range results.Blocks {
 				Logger.Infof("%v :: %v", block.Matches, block.Text)
 			}
 		}
-	} else {
-		Logger.Info("No licenses were found")
 	}
 
 	if licenseArg != "" {
@@ -311,12 +573,21 @@ func findLicensesInFile(cfg *viper.Viper, f string) error {
 	}
 
 	logScanTimeMS(startTime)
-	return nil
+	return checkResults(cfg, licensePolicy, baseline, licenseLibrary, results)
 }
 
 func notGlobalInit(c *cobra.Command) {
 	// Add configurer flag definitions, shared with API, added to CLI flags here.
 	configurer.AddDefaultFlags(c.Flags())
+	// The subcommands have these flags, which are kept on the root command for existing scripts
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		if subcommand, ok := deprecatedFlags[f.Name]; ok {
+			_ = c.Flags().MarkDeprecated(f.Name, fmt.Sprintf("use \"%v %v\" instead", project, subcommand))
+		} else {
+			_ = c.Flags().MarkHidden(f.Name)
+		}
+	})
+	c.MarkFlagsMutuallyExclusive(configurer.FileFlag, configurer.DirFlag, configurer.ListFlag, configurer.AddAllFlag, configurer.UpdateAllFlag)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -326,6 +597,6 @@ func Execute() {
 		_ = doc.GenMarkdownTree(rootCmd, "./cmd/")
 	}
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(ExitCode(err))
 	}
 }
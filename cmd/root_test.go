@@ -5,16 +5,24 @@
 package cmd
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/viper"
+
+	"github.com/CycloneDX/license-scanner/reporter"
 )
 
 func Test_CLI_version(t *testing.T) {
@@ -55,6 +63,141 @@ func Test_CLI_help(t *testing.T) {
 	}
 }
 
+func Test_CLI_version_cmd(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	bOut := bytes.NewBufferString("")
+	cmd.SetOut(bOut)
+	cmd.SetArgs([]string{"version"})
+	if err := cmd.Execute(); err != nil {
+		t.Error(err)
+	}
+	expected := "license-scanner version 0.0.0"
+	if !strings.Contains(bOut.String(), expected) {
+		t.Errorf("expected output containing %s got %s", expected, bOut)
+	}
+}
+
+func Test_CLI_scan_file(t *testing.T) {
+	t.Parallel()
+	outputFile := path.Join(t.TempDir(), "results.json")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"scan", "file", "../testdata/addAll/input/text/0BSD.txt", "--format", "json", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot read output file: %v", err)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	if len(report.Results) != 1 || len(report.Results[0].Licenses) == 0 || report.Results[0].Licenses[0].ID != "0BSD" {
+		t.Errorf("expected one result with 0BSD got %+v", report.Results)
+	}
+}
+
+func Test_CLI_scan_dir(t *testing.T) {
+	t.Parallel()
+	outputFile := path.Join(t.TempDir(), "results.json")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"scan", "dir", "../testdata/addAll/input/text", "--include", "0BSD.txt", "--output", "json", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot read output file: %v", err)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	if len(report.Results) != 1 || len(report.Results[0].Licenses) == 0 || report.Results[0].Licenses[0].ID != "0BSD" {
+		t.Errorf("expected one result with 0BSD got %+v", report.Results)
+	}
+}
+
+func Test_CLI_scan_invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "file without a file", args: []string{"scan", "file"}},
+		{name: "file with two files", args: []string{"scan", "file", "LICENSE", "NOTICE"}},
+		{name: "file with a dir flag", args: []string{"scan", "file", "../testdata/addAll/input/text/0BSD.txt", "--include", "*.txt"}},
+		{name: "dir with a file flag", args: []string{"scan", "dir", "../testdata/addAll/input/text", "--hash"}},
+		{name: "deprecated flags together", args: []string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--dir", "../testdata/addAll/input/text"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err == nil {
+				t.Errorf("expected an error for %v", tt.args)
+			}
+		})
+	}
+}
+
+func Test_CLI_deprecated_flag(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	bOut := bytes.NewBufferString("")
+	cmd.SetOut(bOut)
+	cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	expected := `Flag --file has been deprecated, use "license-scanner scan file FILE" instead`
+	if !strings.Contains(bOut.String(), expected) {
+		t.Errorf("expected output containing %s got %s", expected, bOut)
+	}
+}
+
+func Test_CLI_explain(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		license  string
+		expected string
+		wantErr  string
+	}{
+		{name: "match", license: "0BSD", expected: "FOUND LICENSE MATCHES"},
+		{name: "no match", license: "MIT", expected: "PATTERN 0: spdx/default/template/MIT.template.txt (-pattern +file)"},
+		{name: "unknown license", license: "Bogus", wantErr: `unknown license "Bogus"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			bOut := bytes.NewBufferString("")
+			cmd.SetOut(bOut)
+			cmd.SetArgs([]string{"explain", "../testdata/addAll/input/text/0BSD.txt", tt.license})
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error %q got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Got unexpected error: %v", err)
+			}
+			if !strings.Contains(bOut.String(), tt.expected) {
+				t.Errorf("expected output containing %s got %s", tt.expected, bOut)
+			}
+		})
+	}
+}
+
 func Test_CLI_file_not_found(t *testing.T) {
 	t.Parallel()
 	cmd := NewRootCmd()
@@ -74,6 +217,512 @@ func Test_CLI_file(t *testing.T) {
 	}
 }
 
+func Test_CLI_file_json(t *testing.T) {
+	t.Parallel()
+	outputFile := path.Join(t.TempDir(), "results.json")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--output", "json", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot read output file: %v", err)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	if report.SchemaVersion != reporter.SchemaVersion {
+		t.Errorf("expected schemaVersion %v got %v", reporter.SchemaVersion, report.SchemaVersion)
+	}
+	if len(report.Results) != 1 || len(report.Results[0].Licenses) == 0 || report.Results[0].Licenses[0].ID != "0BSD" {
+		t.Errorf("expected one result with 0BSD got %+v", report.Results)
+	}
+}
+
+func Test_CLI_file_keywordsFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := map[string]string{
+		"input.txt":     "This is subject to Export Control and in the public domain.\n",
+		"keywords.yaml": "rules:\n  - name: default\n    keywords: []\n  - name: export\n    severity: error\n    keywords: ['export\\s+control']\n",
+		"invalid.yaml":  "rules:\n  - name: export\n    severity: fatal\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outputFile := filepath.Join(dir, "results.json")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"-f", filepath.Join(dir, "input.txt"), "--keywords", "--keywordsFile", filepath.Join(dir, "keywords.yaml"), "--output", "json", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot read output file: %v", err)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	expected := []reporter.KeywordMatch{{Text: "Export Control", Begins: 19, Ends: 32, Rule: "export", Severity: "error"}}
+	if len(report.Results) != 1 {
+		t.Fatalf("expected one result got %+v", report.Results)
+	}
+	if d := cmp.Diff(expected, report.Results[0].Keywords); d != "" {
+		t.Errorf("didn't get expected keywords (-want, +got): %v", d)
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"-f", filepath.Join(dir, "input.txt"), "--keywords", "--keywordsFile", filepath.Join(dir, "invalid.yaml"), "--quiet"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --keywordsFile") {
+		t.Errorf("expected an invalid --keywordsFile error got %v", err)
+	}
+}
+
+func Test_CLI_dir_cyclonedx(t *testing.T) {
+	t.Parallel()
+	outputFile := path.Join(t.TempDir(), "bom.xml")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"--dir", "../testdata/addAll/input/text", "--format", "cyclonedx-xml", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	f, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot open output file: %v", err)
+	}
+	defer f.Close()
+	bom := cyclonedx.NewBOM()
+	if err := cyclonedx.NewBOMDecoder(f, cyclonedx.BOMFileFormatXML).Decode(bom); err != nil {
+		t.Fatalf("Invalid CycloneDX XML: %v", err)
+	}
+	if bom.Components == nil || len(*bom.Components) != 1 {
+		t.Fatalf("expected one file component got %+v", bom.Components)
+	}
+	component := (*bom.Components)[0]
+	if component.Evidence == nil || component.Evidence.Licenses == nil || (*component.Evidence.Licenses)[0].License.ID != "0BSD" {
+		t.Errorf("expected 0BSD license evidence got %+v", component.Evidence)
+	}
+}
+
+func Test_CLI_dir_spdx(t *testing.T) {
+	t.Parallel()
+	outputFile := path.Join(t.TempDir(), "text.spdx")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"--dir", "../testdata/addAll/input/text", "--output", "spdx-tv", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot read output file: %v", err)
+	}
+	for _, expected := range []string{"SPDXVersion: SPDX-2.3\n", "FileName: ./0BSD.txt\n", "LicenseInfoInFile: 0BSD\n", "LicenseConcluded: NOASSERTION\n"} {
+		if !bytes.Contains(b, []byte(expected)) {
+			t.Errorf("expected SPDX tag-value containing %q got\n%s", expected, b)
+		}
+	}
+}
+
+func Test_CLI_file_cacheDir(t *testing.T) {
+	t.Parallel()
+	cacheDir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		cmd := NewRootCmd()
+		cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--cacheDir", cacheDir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Got unexpected error: %v", err)
+		}
+	}
+	entries, err := filepath.Glob(filepath.Join(cacheDir, "*", "*", "*"))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one cache entry got %v %v", entries, err)
+	}
+}
+
+func Test_CLI_file_nearMiss(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	inputFile := path.Join(dir, "LICENSE")
+	modified := "Permission to use, copy, and distribute this software for any non-commercial purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.\n\nTHE SOFTWARE IS PROVIDED BY SOMEONE \"AS IS\" AND ANY EXPRESS OR IMPLIED WARRANTIES ARE DISCLAIMED.\n"
+	if err := os.WriteFile(inputFile, []byte(modified), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outputFile := path.Join(dir, "results.json")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"-f", inputFile, "--configPath", "../testdata/similarity", "--minSimilarity", "0.8", "--output", "json", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot read output file: %v", err)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	if len(report.Results) != 1 || report.Results[0].NearMiss == nil || report.Results[0].NearMiss.ID != "Simple" {
+		t.Errorf("expected one result with a Simple near miss got %+v", report.Results)
+	}
+}
+
+func Test_CLI_invalid_minSimilarity(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--minSimilarity", "2"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --minSimilarity") {
+		t.Fatalf("Expected invalid --minSimilarity error got: %v", err)
+	}
+}
+
+func Test_CLI_invalid_expressionOrder(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--expressionOrder", "random"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --expressionOrder") {
+		t.Fatalf("Expected invalid --expressionOrder error got: %v", err)
+	}
+}
+
+func Test_CLI_dir_archive(t *testing.T) {
+	t.Parallel()
+	text, err := os.ReadFile("../testdata/addAll/input/text/0BSD.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipOf := func(name string, content []byte) []byte {
+		var b bytes.Buffer
+		zw := zip.NewWriter(&b)
+		w, _ := zw.Create(name)
+		_, _ = w.Write(content)
+		_ = zw.Close()
+		return b.Bytes()
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "dist.zip"), zipOf("lib/foo.jar", zipOf("META-INF/LICENSE", text)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	outputFile := path.Join(t.TempDir(), "results.json")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--output", "json", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot read output file: %v", err)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	if len(report.Results) != 1 || !strings.HasSuffix(report.Results[0].File, "dist.zip!/lib/foo.jar!/META-INF/LICENSE") ||
+		len(report.Results[0].Licenses) == 0 || report.Results[0].Licenses[0].ID != "0BSD" {
+		t.Errorf("expected one result with 0BSD in the nested jar got %+v", report.Results)
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"-f", filepath.Join(dir, "dist.zip"), "--archiveDepth", "-1"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --archiveDepth") {
+		t.Fatalf("Expected invalid --archiveDepth error got: %v", err)
+	}
+}
+
+func Test_CLI_dir_skipped(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "image.png"), []byte("\x89PNG\x00\x00"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	text, err := os.ReadFile("../testdata/addAll/input/text/0BSD.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "LICENSE"), text, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	outputFile := path.Join(t.TempDir(), "results.txt")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Cannot read output file: %v", err)
+	}
+	for _, expected := range []string{"SKIPPED FILES: 1 of 2", "image.png: binary file", "skipped-binary:\t1"} {
+		if !bytes.Contains(b, []byte(expected)) {
+			t.Errorf("expected output containing %q got %s", expected, b)
+		}
+	}
+	if bytes.Contains(b, []byte("No licenses were found")) {
+		t.Errorf("expected no 'No licenses were found' for a skipped file got %s", b)
+	}
+}
+
+func Test_CLI_compile(t *testing.T) {
+	t.Parallel()
+	snapshot := path.Join(t.TempDir(), "library.snapshot")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"compile", snapshot, "--configPath", "../testdata/config"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--configPath", "../testdata/config", "--library", snapshot})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	// the snapshot is stale for other resources
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--configPath", "../testdata/config", "--spdx", "default", "--library", snapshot})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "stale license library snapshot") {
+		t.Errorf("expected a stale snapshot error got %v", err)
+	}
+}
+
+func Test_CLI_policy(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	policies := map[string]string{
+		"allow":   "rules:\n  - decision: allow\n    ids: [0BSD]\n",
+		"deny":    "rules:\n  - decision: deny\n    ids: [0BSD]\n",
+		"review":  "default: review\n",
+		"invalid": "rules:\n  - decision: block\n",
+	}
+	for name, policy := range policies {
+		if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(policy), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		policy   string
+		args     []string
+		exitCode int
+	}{
+		{policy: "allow", exitCode: 0},
+		{policy: "deny", exitCode: ExitDenied},
+		{policy: "review", exitCode: ExitReview},
+		{policy: "invalid", exitCode: ExitError},
+		{policy: "deny", args: []string{"scan", "dir", "../testdata/addAll/input/text", "--include", "0BSD.txt"}, exitCode: ExitDenied},
+		{policy: "deny", args: []string{"-f", "../testdata/addAll/input/text/0BSD.txt"}, exitCode: ExitDenied},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(strings.Join(append([]string{tt.policy}, tt.args...), " "), func(t *testing.T) {
+			t.Parallel()
+			args := tt.args
+			if args == nil {
+				args = []string{"scan", "file", "../testdata/addAll/input/text/0BSD.txt"}
+			}
+			cmd := NewRootCmd()
+			cmd.SetArgs(append(args, "--quiet", "--policy", filepath.Join(dir, tt.policy+".yaml")))
+			if got := ExitCode(cmd.Execute()); got != tt.exitCode {
+				t.Errorf("expected exit code %v got %v", tt.exitCode, got)
+			}
+		})
+	}
+}
+
+func Test_CLI_baseline(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	text, err := os.ReadFile("../testdata/addAll/input/text/0BSD.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile := func(name string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, name), text, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("a/0BSD.txt")
+	baselineFile := filepath.Join(dir, "baseline.json")
+	if err := os.WriteFile(filepath.Join(dir, "deny.yaml"), []byte("rules:\n  - decision: deny\n    ids: [0BSD]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{"results": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"scan", "dir", src, "--quiet", "--output", "json", "--outputFile", baselineFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	scan := func(args ...string) int {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append([]string{"scan", "dir", src, "--quiet", "--baseline", baselineFile}, args...))
+		return ExitCode(cmd.Execute())
+	}
+	if got := scan(); got != 0 {
+		t.Errorf("unchanged: expected exit code 0 got %v", got)
+	}
+	// a copyright added to a file does not fail the policy for the licenses which the baseline already had
+	if err := os.WriteFile(filepath.Join(src, "a/0BSD.txt"), append(append([]byte{}, text...), "\nCopyright 2024 Someone\n"...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := scan("--copyrights"); got != ExitIntroduced {
+		t.Errorf("added copyright: expected exit code %v got %v", ExitIntroduced, got)
+	}
+	if got := scan("--copyrights", "--policy", filepath.Join(dir, "deny.yaml")); got != 0 {
+		t.Errorf("added copyright with policy: expected exit code 0 got %v", got)
+	}
+	writeFile("a/0BSD.txt")
+	if err := os.Rename(filepath.Join(src, "a"), filepath.Join(src, "b")); err != nil {
+		t.Fatal(err)
+	}
+	if got := scan("--policy", filepath.Join(dir, "deny.yaml")); got != 0 {
+		t.Errorf("renamed: expected exit code 0 got %v", got)
+	}
+	writeFile("c/0BSD.txt")
+	if got := scan(); got != ExitIntroduced {
+		t.Errorf("added: expected exit code %v got %v", ExitIntroduced, got)
+	}
+	if got := scan("--policy", filepath.Join(dir, "deny.yaml")); got != ExitDenied {
+		t.Errorf("added with policy: expected exit code %v got %v", ExitDenied, got)
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"scan", "dir", src, "--quiet", "--baseline", filepath.Join(dir, "invalid.json")})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --baseline") {
+		t.Errorf("expected invalid --baseline error got: %v", err)
+	}
+}
+
+func Test_CLI_reuse_lint(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":                 "// SPDX-FileCopyrightText: 2023 Jane Doe\n// SPDX-License-Identifier: Apache-2.0\npackage main\n",
+		"LICENSES/Apache-2.0.txt": "Apache License",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lint := func(args ...string) int {
+		cmd := NewRootCmd()
+		cmd.SetArgs(append([]string{"reuse-lint", dir, "--quiet", "--outputFile", filepath.Join(dir, "..", "reuse.txt")}, args...))
+		return ExitCode(cmd.Execute())
+	}
+	if got := lint(); got != 0 {
+		t.Errorf("compliant: expected exit code 0 got %v", got)
+	}
+	if got := lint("--output", "sarif"); got != ExitError {
+		t.Errorf("invalid output: expected exit code %v got %v", ExitError, got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "util.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := lint("--output", "json"); got != ExitNotCompliant {
+		t.Errorf("not compliant: expected exit code %v got %v", ExitNotCompliant, got)
+	}
+}
+
+func Test_CLI_notices(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	license, err := os.ReadFile("../LICENSE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"lib/LICENSE": string(license),
+		"lib/NOTICE":  "Foo\nThis product includes software developed at Acme Corp.\n",
+		"lib/foo.go":  "// Copyright 2021-2022 Acme Corp. <legal@acme.example>\n// SPDX-License-Identifier: Apache-2.0\npackage foo\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outputFile := filepath.Join(t.TempDir(), "notices.md")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"notices", dir, "--quiet", "--output", "markdown", "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("notices error = %v", err)
+	}
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"## Apache-2.0",
+		"* `lib/LICENSE`\n* `lib/foo.go`\n\nCopyright holders:\n\n* Acme Corp. 2021-2022 <legal@acme.example>\n\n",
+		"```text\nApache License\n",
+		"### lib/NOTICE\n",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("expected notices containing %q got\n%v", expected, string(b))
+		}
+	}
+
+	tmpl := filepath.Join(t.TempDir(), "notices.tmpl")
+	if err := os.WriteFile(tmpl, []byte("{{range .Licenses}}{{.ID}}: {{join .Files \", \"}}{{end}}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"notices", dir, "--configPath", "../testdata/config", "--quiet", "--template", tmpl, "--outputFile", outputFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("notices --template error = %v", err)
+	}
+	if b, _ := os.ReadFile(outputFile); string(b) != "Apache-2.0: lib/LICENSE, lib/foo.go" {
+		t.Errorf("expected the notices of the --template got %q", string(b))
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"notices", dir, "--configPath", "../testdata/config", "--output", "json"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --output") {
+		t.Errorf("Expected invalid --output error got: %v", err)
+	}
+}
+
+func Test_CLI_invalid_exclude(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"--dir", "../testdata/addAll/input/text", "--exclude", "[", "--licenseFiles"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --exclude") {
+		t.Fatalf("Expected invalid --exclude error got: %v", err)
+	}
+}
+
+func Test_CLI_invalid_output(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--output", "yaml"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --output") {
+		t.Fatalf("Expected invalid --output error got: %v", err)
+	}
+}
+
 // Test_CLI_addAll_Bogus verifies that --addAll <dir-does-not-exist> returns a ErrNotExist error
 func Test_CLI_addAll_Bogus(t *testing.T) {
 	t.Parallel()
@@ -84,6 +733,16 @@ func Test_CLI_addAll_Bogus(t *testing.T) {
 	}
 }
 
+// Test_CLI_import_Bogus verifies that import <dir-does-not-exist> returns a ErrNotExist error
+func Test_CLI_import_Bogus(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"import", "../testdata/addAll/bogus/no-dir-here", "--spdx", "testing"})
+	if err := cmd.Execute(); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected ErrNotExist got: %v", err)
+	}
+}
+
 // Test_CLI_no_spdx_json_licenses tests --addAll missing the required SPDX json files
 func Test_CLI_no_spdx_json_licenses(t *testing.T) {
 	t.Parallel()
@@ -216,6 +875,15 @@ func Test_CLI_list(t *testing.T) {
 	}
 }
 
+func Test_CLI_list_cmd(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "--spdx", "default"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected nil err for list got: %v", err)
+	}
+}
+
 func Test_CLI_list_spdx(t *testing.T) {
 	t.Parallel()
 	cmd := NewRootCmd()
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+)
+
+func newScanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Scan a file or a directory to detect licenses",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newScanFileCmd())
+	cmd.AddCommand(newScanDirCmd())
+	return cmd
+}
+
+func newScanFileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "file FILE",
+		SilenceUsage: true,
+		Short:        "Scan a file, or the files of an archive, to detect licenses",
+		Long: `
+Scan a file to detect licenses. Archives are scanned file by file, including nested archives.
+
+Example usage:
+
+    $ license-scanner scan file LICENSE
+    $ license-scanner scan file --copyrights --output json LICENSE
+		`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+			cfg.Set(configurer.FileFlag, args[0])
+			return findLicensesInFile(cmd.Context(), cfg, args[0])
+		},
+	}
+	configurer.AddScanFileFlags(cmd.Flags())
+	return cmd
+}
+
+func newScanDirCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "dir DIR",
+		SilenceUsage: true,
+		Short:        "Scan the files of a directory, recursively, to detect licenses",
+		Long: `
+Scan the files of a directory, and of its subdirectories, to detect licenses.
+Files ignored by .gitignore and .licensescannerignore files are not scanned (see --noIgnore).
+
+Example usage:
+
+    $ license-scanner scan dir ./src --exclude vendor
+    $ license-scanner scan dir . --licenseFiles --format cyclonedx-json --outputFile bom.json
+		`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+			cfg.Set(configurer.DirFlag, args[0])
+			return findLicensesInDirectory(cmd.Context(), cfg)
+		},
+	}
+	configurer.AddScanDirFlags(cmd.Flags())
+	return cmd
+}
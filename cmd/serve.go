@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/server"
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "serve",
+		SilenceUsage: true,
+		Short:        "Serve license scanning over HTTP",
+		Long: `
+Load the license library once and serve license scanning over HTTP until interrupted.
+
+Endpoints:
+
+    GET  /healthz          health and license library summary
+    GET  /v1/licenses      the licenses in the library
+    POST /v1/scan/text     scan the request body as text
+    POST /v1/scan/files    scan the files (and zip or tar archive entries) of a multipart/form-data upload
+
+Scan results use the same JSON schema as --output json. The copyrights, keywords, acceptable,
+and normalized query parameters override the corresponding flags for a request.
+
+Example usage:
+
+    $ license-scanner serve --addr localhost:8080 --copyrights
+    $ curl --data-binary @LICENSE 'http://localhost:8080/v1/scan/text?name=LICENSE'
+    $ curl -F file=@src.zip http://localhost:8080/v1/scan/files
+		`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+			if err != nil {
+				return err
+			}
+			if err := licenseLibrary.Load(); err != nil {
+				return err
+			}
+			options, err := getCommandLineOptions(cfg, licenseLibrary)
+			if err != nil {
+				return err
+			}
+
+			if cfg.GetDuration(configurer.TimeoutFlag) < 0 {
+				return fmt.Errorf("invalid --%v %v (expected 0 or more)", configurer.TimeoutFlag, cfg.GetDuration(configurer.TimeoutFlag))
+			}
+
+			s := server.New(licenseLibrary, server.Config{
+				ToolName:              project,
+				ToolVersion:           currentVersion,
+				Options:               options,
+				IncludeNormalizedText: cfg.GetBool(configurer.NormalizedFlag),
+				MaxRequestBytes:       cfg.GetInt64(configurer.MaxRequestBytesFlag),
+				MaxFileBytes:          cfg.GetInt64(configurer.MaxFileBytesFlag),
+				ArchiveLimits:         options.ArchiveLimits,
+				Timeout:               cfg.GetDuration(configurer.TimeoutFlag),
+			})
+
+			// shut down gracefully on interrupt or terminate
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			addr := cfg.GetString(configurer.AddrFlag)
+			Logger.Infof("Serving %v licenses on %v", len(licenseLibrary.LicenseMap), addr)
+			if err := s.ListenAndServe(ctx, addr); err != nil {
+				return err
+			}
+			Logger.Info("Server stopped")
+			return nil
+		},
+	}
+	configurer.AddServeFlags(cmd.Flags())
+	return cmd
+}
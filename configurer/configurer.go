@@ -14,29 +14,73 @@ import (
 )
 
 const (
-	DefaultResource = "default"
-	AcceptableFlag  = "acceptable"
-	CopyrightsFlag  = "copyrights"
-	NormalizedFlag  = "normalized"
-	HashFlag        = "hash"
-	KeywordsFlag    = "keywords"
-	ListFlag        = "list"
-	AddAllFlag      = "addAll"
-	UpdateAllFlag   = "updateAll"
-	DebugFlag       = "debug"
-	QuietFlag       = "quiet"
-	LicenseFlag     = "license"
-	DirFlag         = "dir"
-	FileFlag        = "file"
-	ConfigPathFlag  = "configPath"
-	ConfigNameFlag  = "configName"
-	SpdxFlag        = "spdx"
-	SpdxPathFlag    = "spdxPath"
-	CustomFlag      = "custom"
-	CustomPathFlag  = "customPath"
-	OverwriteFlag   = "overwrite"
+	DefaultResource     = "default"
+	AcceptableFlag      = "acceptable"
+	CopyrightsFlag      = "copyrights"
+	NormalizedFlag      = "normalized"
+	HashFlag            = "hash"
+	KeywordsFlag        = "keywords"
+	ListFlag            = "list"
+	AddAllFlag          = "addAll"
+	UpdateAllFlag       = "updateAll"
+	DebugFlag           = "debug"
+	QuietFlag           = "quiet"
+	LicenseFlag         = "license"
+	DirFlag             = "dir"
+	FileFlag            = "file"
+	ConfigPathFlag      = "configPath"
+	ConfigNameFlag      = "configName"
+	SpdxFlag            = "spdx"
+	SpdxPathFlag        = "spdxPath"
+	CustomFlag          = "custom"
+	CustomPathFlag      = "customPath"
+	OverwriteFlag       = "overwrite"
+	OutputFlag          = "output"
+	OutputFileFlag      = "outputFile"
+	CacheDirFlag        = "cacheDir"
+	MinConfidenceFlag   = "minConfidence"
+	MinSimilarityFlag   = "minSimilarity"
+	ExpressionOrderFlag = "expressionOrder"
+	ArchiveDepthFlag    = "archiveDepth"
+	ArchiveEntriesFlag  = "archiveEntries"
+	ArchiveBytesFlag    = "archiveBytes"
+	IncludeFlag         = "include"
+	ExcludeFlag         = "exclude"
+	NoIgnoreFlag        = "noIgnore"
+	LicenseFilesFlag    = "licenseFiles"
+	TimeoutFlag         = "timeout"
+	FileTimeoutFlag     = "fileTimeout"
+	LibraryFlag         = "library"
+	PolicyFlag          = "policy"
+	BaselineFlag        = "baseline"
+	TemplateFlag        = "template"
+	KeywordsFileFlag    = "keywordsFile"
+
+	// serve flags
+	AddrFlag            = "addr"
+	MaxRequestBytesFlag = "maxRequestBytes"
+	MaxFileBytesFlag    = "maxFileBytes"
 )
 
+const (
+	OutputText          = "text"
+	OutputJSON          = "json"
+	OutputCycloneDXJSON = "cyclonedx-json"
+	OutputCycloneDXXML  = "cyclonedx-xml"
+	OutputSPDXJSON      = "spdx-json"
+	OutputSPDXTagValue  = "spdx-tv"
+	OutputSARIF         = "sarif"
+)
+
+// FormatFlag is accepted as an alias for OutputFlag
+const FormatFlag = "format"
+
+// KeywordRulesKey is the config file key (not a flag) of the keyword rules of --keywords
+const KeywordRulesKey = "keywordRules"
+
+// OutputFormats lists the supported values for OutputFlag
+var OutputFormats = []string{OutputText, OutputJSON, OutputCycloneDXJSON, OutputCycloneDXXML, OutputSPDXJSON, OutputSPDXTagValue, OutputSARIF}
+
 var (
 	_, thisFile, _, _ = runtime.Caller(0) // Dirs/files are relative to this file
 	thisDir           = filepath.Dir(thisFile)
@@ -134,6 +178,7 @@ func AddDefaultFlags(flagSet *pflag.FlagSet) {
 	flagSet.StringP(FileFlag, "f", "", "A file in which to identify licenses")
 	flagSet.BoolP(AcceptableFlag, "g", false, "Flag acceptable")
 	flagSet.BoolP(KeywordsFlag, "k", false, "Flag keywords")
+	flagSet.String(KeywordsFileFlag, "", "File (YAML or JSON) of keyword rules for --keywords, which add to or replace the default and configured rules by name")
 	flagSet.BoolP(CopyrightsFlag, "c", false, "Flag copyrights")
 	flagSet.BoolP(NormalizedFlag, "n", false, "Flag normalized")
 	flagSet.BoolP(HashFlag, "x", false, "Output file hash")
@@ -147,5 +192,117 @@ func AddDefaultFlags(flagSet *pflag.FlagSet) {
 	flagSet.String(SpdxPathFlag, "", "Path to external SPDX templates to use")
 	flagSet.String(CustomFlag, DefaultResource, "Custom templates to use")
 	flagSet.String(CustomPathFlag, "", "Path to external custom templates to use")
-	flagSet.Bool(OverwriteFlag, false, "Overwrite existing directories and files when using --addAll flag")
+	flagSet.Bool(OverwriteFlag, false, "Overwrite existing directories and files when importing licenses")
+	flagSet.StringP(OutputFlag, "o", OutputText, "Output format for scan results (text, json, cyclonedx-json, cyclonedx-xml, spdx-json, spdx-tv, or sarif)")
+	flagSet.String(OutputFileFlag, "", "Write results to this file instead of stdout")
+	flagSet.String(CacheDirFlag, "", "Directory in which to cache scan results between runs (no cache when empty)")
+	flagSet.Float64(MinConfidenceFlag, 0, "Omit matches with a confidence below this score (0-1, e.g. 0.5 omits alias-only matches)")
+	flagSet.Float64(MinSimilarityFlag, 0, "Report the closest license with at least this similarity (0-1, e.g. 0.8) when no license text matches (0 disables it)")
+	flagSet.String(ExpressionOrderFlag, "text", "Order of the licenses in license expressions (text or id)")
+	flagSet.Int(ArchiveDepthFlag, 5, "Levels of nested zip, jar, war, ear, whl, nupkg, tar, and tar.gz archives to scan (0 scans archives as files)")
+	flagSet.Int(ArchiveEntriesFlag, 10000, "Maximum number of files in an archive, including nested archives")
+	flagSet.Int64(ArchiveBytesFlag, 1<<30, "Maximum decompressed bytes read from an archive, including nested archives")
+	flagSet.StringSlice(IncludeFlag, nil, "Only scan the files which match these glob patterns (e.g. '**/*.go')")
+	flagSet.StringSlice(ExcludeFlag, nil, "Skip the files and subdirectories which match these glob patterns (e.g. 'vendor,*.min.js')")
+	flagSet.Bool(NoIgnoreFlag, false, "Scan files ignored by .gitignore and .licensescannerignore files, and version control directories")
+	flagSet.Bool(LicenseFilesFlag, false, "Only scan well-known license files (LICENSE*, COPYING*, NOTICE*, *.LICENSE) and package manifests")
+	flagSet.Duration(TimeoutFlag, 0, "Maximum time for a scan, or for each request with serve (e.g. 10m, 0 for no limit)")
+	flagSet.Duration(FileTimeoutFlag, 0, "Maximum time to wait for each file, which then has a note instead of matches, while the pattern searches already running finish in the background (e.g. 30s, 0 for no limit)")
+	flagSet.String(PolicyFlag, "", "Policy file (YAML or JSON) which allows, denies, or flags for review the licenses found (exits with 2 for a denied license, 3 for a license to review)")
+	flagSet.String(BaselineFlag, "", "JSON report of a previous scan to compare with, which reports the changed files and only fails for introduced licenses or copyrights (exits with 4 without a --policy)")
+	flagSet.String(LibraryFlag, "", "Load the license library from this snapshot (see the compile command) instead of the SPDX and custom templates")
+	flagSet.String(TemplateFlag, "", "Go template file which renders the notices in place of the default template of the --output format")
+	flagSet.SetNormalizeFunc(aliasFlags)
+}
+
+var (
+	// logFlags are the output logging flags of every command
+	logFlags = []string{DebugFlag, QuietFlag}
+	// resourceFlags are the flags which locate the config file and the license resources
+	resourceFlags = []string{ConfigPathFlag, ConfigNameFlag, SpdxFlag, SpdxPathFlag, CustomFlag, CustomPathFlag}
+	// scanFlags are the flags of the scan commands and serve which change how licenses are identified
+	scanFlags = []string{
+		AcceptableFlag, CopyrightsFlag, KeywordsFlag, KeywordsFileFlag, NormalizedFlag, LibraryFlag, CacheDirFlag, MinConfidenceFlag, MinSimilarityFlag, ExpressionOrderFlag,
+		ArchiveDepthFlag, ArchiveEntriesFlag, ArchiveBytesFlag, TimeoutFlag, FileTimeoutFlag,
+	}
+	// outputFlags are the flags of the scan commands which select the format and destination of the results
+	outputFlags = []string{OutputFlag, OutputFileFlag}
+	// dirFlags are the directory filter flags
+	dirFlags = []string{IncludeFlag, ExcludeFlag, NoIgnoreFlag, LicenseFilesFlag}
+)
+
+// addFlags adds the named default flags to the flag set
+func addFlags(flagSet *pflag.FlagSet, names ...[]string) {
+	defaults := NewDefaultFlags()
+	for _, n := range names {
+		for _, name := range n {
+			flagSet.AddFlag(defaults.Lookup(name))
+		}
+	}
+}
+
+// AddScanFileFlags adds the flags of the scan file command
+func AddScanFileFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags, scanFlags, outputFlags, []string{PolicyFlag, BaselineFlag, HashFlag})
+	flagSet.SetNormalizeFunc(aliasFlags)
+}
+
+// AddScanDirFlags adds the flags of the scan dir command: the scan file flags without --hash, plus the directory filter flags
+func AddScanDirFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags, scanFlags, outputFlags, []string{PolicyFlag, BaselineFlag}, dirFlags)
+	flagSet.SetNormalizeFunc(aliasFlags)
+}
+
+// AddExplainFlags adds the flags of the explain command
+func AddExplainFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags, []string{LibraryFlag})
+}
+
+// AddListFlags adds the flags of the list command
+func AddListFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags, []string{LibraryFlag})
+}
+
+// AddImportFlags adds the flags of the import command
+func AddImportFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags, []string{OverwriteFlag})
+}
+
+// AddUpdateFlags adds the flags of the update command
+func AddUpdateFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags)
+}
+
+// AddReuseLintFlags adds the flags of the reuse-lint command: the default flags for config, resources, and logging,
+// plus the output and directory filter flags
+func AddReuseLintFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags, outputFlags, []string{LibraryFlag, TimeoutFlag, IncludeFlag, ExcludeFlag, NoIgnoreFlag})
+	flagSet.SetNormalizeFunc(aliasFlags)
+}
+
+// AddNoticesFlags adds the flags of the notices command: the scan dir flags without --policy and --baseline, plus --template
+func AddNoticesFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags, scanFlags, outputFlags, dirFlags, []string{TemplateFlag})
+	flagSet.SetNormalizeFunc(aliasFlags)
+}
+
+// AddServeFlags adds the flags of the serve command: the default flags for config, resources, logging, and scan options, plus the server flags
+func AddServeFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags, scanFlags)
+	flagSet.String(AddrFlag, ":8080", "Address on which to serve HTTP requests")
+	flagSet.Int64(MaxRequestBytesFlag, 32<<20, "Maximum size of a request body in bytes")
+	flagSet.Int64(MaxFileBytesFlag, 1000000, "Maximum size in bytes of each scanned text, file, or archive entry")
+}
+
+// AddCompileFlags adds the flags of the compile command: the default flags for config, resources, and logging
+func AddCompileFlags(flagSet *pflag.FlagSet) {
+	addFlags(flagSet, logFlags, resourceFlags)
+}
+
+// aliasFlags lets --format be used in place of --output
+func aliasFlags(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == FormatFlag {
+		name = OutputFlag
+	}
+	return pflag.NormalizedName(name)
 }
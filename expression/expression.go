@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package expression
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+// Operator combines the operands of a compound expression
+type Operator string
+
+const (
+	And Operator = "AND"
+	Or  Operator = "OR"
+)
+
+const (
+	LicenseRefPrefix  = "LicenseRef-"
+	DocumentRefPrefix = "DocumentRef-"
+)
+
+// Expression is a parsed SPDX license expression.
+// A license is a leaf with a License (and optionally OrLater and an Exception).
+// A compound expression has an Operator and two or more Operands.
+type Expression struct {
+	License   string
+	OrLater   bool
+	Exception string
+	Operator  Operator
+	Operands  []*Expression
+	// Begins and Ends (inclusive) are the offsets of a license, from its ID through any exception, in the parsed text
+	Begins int
+	Ends   int
+}
+
+// IsLicense is true for a leaf license (as opposed to a compound expression)
+func (e *Expression) IsLicense() bool {
+	return e.Operator == ""
+}
+
+// String returns the expression in SPDX form, with parentheses only where they are needed
+func (e *Expression) String() string {
+	if e.IsLicense() {
+		s := e.License
+		if e.OrLater {
+			s += "+"
+		}
+		if e.Exception != "" {
+			s += " WITH " + e.Exception
+		}
+		return s
+	}
+
+	operands := make([]string, 0, len(e.Operands))
+	for _, o := range e.Operands {
+		s := o.String()
+		// OR has a lower precedence than AND
+		if !o.IsLicense() && o.Operator == Or && e.Operator == And {
+			s = "(" + s + ")"
+		}
+		operands = append(operands, s)
+	}
+	return strings.Join(operands, " "+string(e.Operator)+" ")
+}
+
+// Licenses returns the leaf licenses in the order that they appear
+func (e *Expression) Licenses() []*Expression {
+	if e.IsLicense() {
+		return []*Expression{e}
+	}
+	var leaves []*Expression
+	for _, o := range e.Operands {
+		leaves = append(leaves, o.Licenses()...)
+	}
+	return leaves
+}
+
+// Validate returns an error if a license ID is not a license in the map, or if an exception ID is not an exception in the map.
+// IDs are matched without case and are changed to the case of the map key. LicenseRef- and DocumentRef- IDs are not validated.
+func (e *Expression) Validate(licenseMap licenses.LicenseMap) error {
+	ids := make(map[string]string, len(licenseMap))
+	for id := range licenseMap {
+		ids[strings.ToLower(id)] = id
+	}
+
+	for _, leaf := range e.Licenses() {
+		if !isRef(leaf.License) {
+			id, ok := ids[strings.ToLower(leaf.License)]
+			if !ok || licenseMap[id].LicenseInfo.SPDXException {
+				return fmt.Errorf("unknown license ID %q", leaf.License)
+			}
+			leaf.License = id
+		}
+		if leaf.Exception != "" && !isRef(leaf.Exception) {
+			id, ok := ids[strings.ToLower(leaf.Exception)]
+			if !ok || !licenseMap[id].LicenseInfo.SPDXException {
+				return fmt.Errorf("unknown exception ID %q", leaf.Exception)
+			}
+			leaf.Exception = id
+		}
+	}
+	return nil
+}
+
+func isRef(id string) bool {
+	return strings.HasPrefix(id, LicenseRefPrefix) || strings.HasPrefix(id, DocumentRefPrefix) || strings.HasPrefix(id, "AdditionRef-")
+}
+
+// Join returns the operands joined by the operator. Operands with the same operator are flattened and duplicates are dropped.
+// It returns the operand if there is only one, or nil if there are none.
+func Join(op Operator, operands ...*Expression) *Expression {
+	e := &Expression{Operator: op}
+	seen := make(map[string]bool)
+	for _, o := range operands {
+		if o == nil {
+			continue
+		}
+		nested := []*Expression{o}
+		if !o.IsLicense() && o.Operator == op {
+			nested = o.Operands
+		}
+		for _, n := range nested {
+			if s := n.String(); !seen[s] {
+				seen[s] = true
+				e.Operands = append(e.Operands, n)
+			}
+		}
+	}
+	switch len(e.Operands) {
+	case 0:
+		return nil
+	case 1:
+		return e.Operands[0]
+	}
+	return e
+}
+
+// Sorted returns a copy of the expression with the operands of each compound expression sorted alphabetically
+func (e *Expression) Sorted() *Expression {
+	c := *e
+	if c.IsLicense() {
+		return &c
+	}
+	c.Operands = make([]*Expression, 0, len(e.Operands))
+	for _, o := range e.Operands {
+		c.Operands = append(c.Operands, o.Sorted())
+	}
+	sort.SliceStable(c.Operands, func(i, j int) bool {
+		return c.Operands[i].String() < c.Operands[j].String()
+	})
+	return &c
+}
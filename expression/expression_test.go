@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package expression
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "license", input: "Apache-2.0", want: "Apache-2.0"},
+		{name: "or later", input: "GPL-2.0+", want: "GPL-2.0+"},
+		{name: "with exception", input: "GPL-2.0-or-later WITH Classpath-exception-2.0", want: "GPL-2.0-or-later WITH Classpath-exception-2.0"},
+		{name: "lower case operators", input: "MIT or Apache-2.0 and BSD-3-Clause", want: "MIT OR Apache-2.0 AND BSD-3-Clause"},
+		{name: "AND binds tighter than OR", input: "MIT AND Apache-2.0 OR BSD-3-Clause", want: "MIT AND Apache-2.0 OR BSD-3-Clause"},
+		{name: "parentheses are kept where needed", input: "(MIT OR Apache-2.0) AND BSD-3-Clause", want: "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{name: "redundant parentheses are dropped", input: "((MIT)) OR (Apache-2.0 OR BSD-3-Clause)", want: "MIT OR Apache-2.0 OR BSD-3-Clause"},
+		{name: "refs", input: "LicenseRef-my-license AND DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2", want: "LicenseRef-my-license AND DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2"},
+		{name: "empty", input: " ", wantErr: true},
+		{name: "missing )", input: "(MIT OR Apache-2.0", wantErr: true},
+		{name: "extra )", input: "MIT)", wantErr: true},
+		{name: "missing operand", input: "MIT AND", wantErr: true},
+		{name: "missing operator", input: "MIT Apache-2.0", wantErr: true},
+		{name: "missing exception", input: "GPL-2.0-only WITH", wantErr: true},
+		{name: "invalid ID", input: "MIT/X11", wantErr: true},
+		{name: "detached +", input: "GPL-2.0 +", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("Parse().String() = %q want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_tree(t *testing.T) {
+	t.Parallel()
+	got, err := Parse("MIT OR (GPL-2.0+ WITH Classpath-exception-2.0 AND BSD-3-Clause)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Expression{
+		Operator: Or,
+		Operands: []*Expression{
+			{License: "MIT", Begins: 0, Ends: 2},
+			{
+				Operator: And,
+				Operands: []*Expression{
+					{License: "GPL-2.0", OrLater: true, Exception: "Classpath-exception-2.0", Begins: 8, Ends: 44},
+					{License: "BSD-3-Clause", Begins: 50, Ends: 61},
+				},
+			},
+		},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Didn't get expected result: (-want, +got): %v", d)
+	}
+}
+
+func TestExpression_Validate(t *testing.T) {
+	t.Parallel()
+	licenseMap := licenses.LicenseMap{
+		"MIT":                     {SPDXLicenseID: "MIT"},
+		"GPL-2.0-only":            {SPDXLicenseID: "GPL-2.0-only"},
+		"Classpath-exception-2.0": {SPDXLicenseID: "Classpath-exception-2.0", LicenseInfo: licenses.LicenseInfo{SPDXException: true}},
+	}
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "IDs are changed to the case of the map", input: "mit OR gpl-2.0-only with classpath-exception-2.0", want: "MIT OR GPL-2.0-only WITH Classpath-exception-2.0"},
+		{name: "refs are not validated", input: "MIT AND LicenseRef-mine", want: "MIT AND LicenseRef-mine"},
+		{name: "unknown license", input: "MIT OR NOPE-1.0", wantErr: true},
+		{name: "exception as a license", input: "Classpath-exception-2.0", wantErr: true},
+		{name: "license as an exception", input: "GPL-2.0-only WITH MIT", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = e.Validate(licenseMap)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && e.String() != tt.want {
+				t.Errorf("Validate() changed the expression to %q want %q", e.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	t.Parallel()
+	or, err := Parse("MIT OR Apache-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mit := &Expression{License: "MIT"}
+	bsd := &Expression{License: "BSD-3-Clause"}
+
+	if got := Join(And); got != nil {
+		t.Errorf("Join() of no operands = %v want nil", got)
+	}
+	if got := Join(And, nil, mit); got != mit {
+		t.Errorf("Join() of one operand = %v want %v", got, mit)
+	}
+	if got, want := Join(And, bsd, or, mit, bsd).String(), "BSD-3-Clause AND (MIT OR Apache-2.0) AND MIT"; got != want {
+		t.Errorf("Join(And) = %q want %q", got, want)
+	}
+	if got, want := Join(Or, bsd, or, mit).String(), "BSD-3-Clause OR MIT OR Apache-2.0"; got != want {
+		t.Errorf("Join(Or) = %q want %q", got, want)
+	}
+}
+
+func TestExpression_Sorted(t *testing.T) {
+	t.Parallel()
+	e, err := Parse("MIT AND (GPL-2.0-only OR Apache-2.0) AND BSD-3-Clause")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := e.Sorted().String(), "(Apache-2.0 OR GPL-2.0-only) AND BSD-3-Clause AND MIT"; got != want {
+		t.Errorf("Sorted() = %q want %q", got, want)
+	}
+	if got, want := e.String(), "MIT AND (GPL-2.0-only OR Apache-2.0) AND BSD-3-Clause"; got != want {
+		t.Errorf("Sorted() changed the expression to %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package expression
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	idRE     = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+	refRE    = regexp.MustCompile(`^(DocumentRef-[A-Za-z0-9.-]+:)?(LicenseRef|AdditionRef)-[A-Za-z0-9.-]+$`)
+	keywords = map[string]string{"AND": "AND", "and": "AND", "OR": "OR", "or": "OR", "WITH": "WITH", "with": "WITH"}
+)
+
+// token is an ID, operator, parenthesis, or "+" with its offsets in the parsed text
+type token struct {
+	text   string
+	begins int
+	ends   int
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+// Parse parses an SPDX license expression. WITH binds tighter than AND, which binds tighter than OR.
+// The AND and OR operands are flattened, so "A AND B AND C" is one expression with three operands.
+func Parse(s string) (*Expression, error) {
+	p := &parser{tokens: lex(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %w", s, err)
+	}
+	if t, ok := p.peek(); ok {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q at %v", s, t.text, t.begins)
+	}
+	return e, nil
+}
+
+// lex splits the text at whitespace, parentheses and "+"
+func lex(s string) []token {
+	var tokens []token
+	begins := -1
+	end := func(i int) {
+		if begins >= 0 {
+			tokens = append(tokens, token{text: s[begins:i], begins: begins, ends: i - 1})
+			begins = -1
+		}
+	}
+	for i, c := range s {
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			end(i)
+		case '(', ')', '+':
+			end(i)
+			tokens = append(tokens, token{text: string(c), begins: i, ends: i})
+		default:
+			if begins < 0 {
+				begins = i
+			}
+		}
+	}
+	end(len(s))
+	return tokens
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.next < len(p.tokens) {
+		return p.tokens[p.next], true
+	}
+	return token{}, false
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	t, ok := p.peek()
+	return ok && keywords[t.text] == keyword
+}
+
+func (p *parser) parseOr() (*Expression, error) {
+	return p.parseCompound(Or, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (*Expression, error) {
+	return p.parseCompound(And, p.parseWith)
+}
+
+// parseCompound parses operands joined by the operator
+func (p *parser) parseCompound(op Operator, parseOperand func() (*Expression, error)) (*Expression, error) {
+	first, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*Expression{first}
+	for p.isKeyword(string(op)) {
+		p.next++
+		o, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, o)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+
+	// flatten nested operands with the same operator, e.g. from parentheses
+	e := &Expression{Operator: op}
+	for _, o := range operands {
+		if !o.IsLicense() && o.Operator == op {
+			e.Operands = append(e.Operands, o.Operands...)
+		} else {
+			e.Operands = append(e.Operands, o)
+		}
+	}
+	return e, nil
+}
+
+// parseWith parses a parenthesized expression, or a license with an optional "+" and exception
+func (p *parser) parseWith() (*Expression, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end")
+	}
+
+	if t.text == "(" {
+		open := t
+		p.next++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.text != ")" {
+			return nil, fmt.Errorf("missing ) for ( at %v", open.begins)
+		}
+		p.next++
+		return e, nil
+	}
+
+	id, err := p.parseID()
+	if err != nil {
+		return nil, err
+	}
+	e := &Expression{License: id.text, Begins: id.begins, Ends: id.ends}
+	if t, ok := p.peek(); ok && t.text == "+" && t.begins == id.ends+1 {
+		p.next++
+		e.OrLater = true
+		e.Ends = t.ends
+	}
+	if p.isKeyword("WITH") {
+		p.next++
+		exception, err := p.parseID()
+		if err != nil {
+			return nil, err
+		}
+		e.Exception = exception.text
+		e.Ends = exception.ends
+	}
+	return e, nil
+}
+
+func (p *parser) parseID() (token, error) {
+	t, ok := p.peek()
+	if !ok {
+		return t, fmt.Errorf("unexpected end")
+	}
+	if _, isKeyword := keywords[t.text]; isKeyword || !isID(t.text) {
+		return t, fmt.Errorf("unexpected %q at %v", t.text, t.begins)
+	}
+	p.next++
+	return t, nil
+}
+
+func isID(s string) bool {
+	if strings.Contains(s, "Ref-") {
+		return refRE.MatchString(s)
+	}
+	return idRE.MatchString(s)
+}
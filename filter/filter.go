@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package filter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/CycloneDX/license-scanner/archive"
+)
+
+// IgnoreFileNames are the files with patterns of paths to skip, read from each directory unless NoIgnore is set
+var IgnoreFileNames = []string{".gitignore", ".licensescannerignore"}
+
+// vcsDirs are version control metadata directories, which are skipped unless NoIgnore is set
+var vcsDirs = []string{".git", ".hg", ".svn"}
+
+// licenseFilePrefixes are the upper case prefixes of the names of well-known license files
+var licenseFilePrefixes = []string{"LICENSE", "LICENCE", "COPYING", "COPYRIGHT", "NOTICE", "UNLICENSE", "PATENTS"}
+
+// sourceSuffixes are the lower case extensions of source files, which are not license files even when named like one (e.g. license_test.go)
+var sourceSuffixes = []string{
+	".go", ".c", ".h", ".cc", ".cpp", ".cs", ".java", ".kt", ".scala", ".js", ".ts", ".py", ".rb", ".php", ".rs", ".swift", ".sh",
+}
+
+// manifestNames are the lower case names of package manifests, which usually declare a license
+var manifestNames = []string{
+	"package.json", "bower.json", "composer.json", "pom.xml", "cargo.toml", "pyproject.toml", "setup.py", "setup.cfg",
+	"pkg-info", "metadata", "description",
+}
+
+// manifestSuffixes are the lower case extensions of package manifests
+var manifestSuffixes = []string{".pom", ".nuspec", ".gemspec", ".podspec"}
+
+// Filter selects the files of a directory to scan
+type Filter struct {
+	// Include scans only the files which match one of these glob patterns (all files when empty)
+	Include []string
+	// Exclude skips the files and directories which match one of these glob patterns
+	Exclude []string
+	// NoIgnore scans files ignored by .gitignore and .licensescannerignore files, and version control directories
+	NoIgnore bool
+	// LicenseFilesOnly scans only well-known license files and package manifests (see IsLicenseFile)
+	LicenseFilesOnly bool
+}
+
+// pattern is a glob pattern from a flag or an ignore file, with gitignore semantics
+type pattern struct {
+	glob string
+	// anchored patterns contain a slash and match the path relative to base, others match the name at any depth
+	anchored bool
+	dirOnly  bool
+	negate   bool
+	// base is the slash-separated directory of the ignore file, relative to the root ("" for the root and flags)
+	base string
+}
+
+// ValidatePatterns returns an error for the first invalid glob pattern
+func ValidatePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(strings.TrimPrefix(p, "!"), ""); err != nil {
+			return fmt.Errorf("%q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Walk calls fn with the path of each non-empty file under the root which the filter selects.
+// Well-known license files are walked first. Directories which cannot be read are logged with warn and skipped.
+func (f Filter) Walk(root string, warn func(format string, v ...interface{}), fn func(path string) error) error {
+	include := parsePatterns(f.Include, "")
+	exclude := parsePatterns(f.Exclude, "")
+	var ignores []pattern
+	var paths []string
+
+	if err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			warn("skipping %v: %v", p, err)
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == "." {
+				rel = ""
+			} else if matchAny(exclude, rel, true) || (!f.NoIgnore && (slices.Contains(vcsDirs, d.Name()) || ignored(ignores, rel, true))) {
+				return filepath.SkipDir
+			}
+			if !f.NoIgnore {
+				ignores = append(ignores, readIgnoreFiles(p, rel, warn)...)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || matchAny(exclude, rel, false) || (!f.NoIgnore && ignored(ignores, rel, false)) {
+			return nil
+		}
+		if len(include) > 0 && !matchAny(include, rel, false) {
+			return nil
+		}
+		if f.LicenseFilesOnly && !IsLicenseFile(rel) && !archive.IsArchive(rel) {
+			return nil
+		}
+		// a file which cannot be read is walked, so that it has a result
+		if info, err := d.Info(); err == nil && info.Size() == 0 {
+			return nil
+		}
+		paths = append(paths, p)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return IsLicenseFile(paths[i]) && !IsLicenseFile(paths[j])
+	})
+	for _, p := range paths {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsLicenseFile is true for well-known license file names (e.g. LICENSE, LICENSE.md, COPYING-LIB, NOTICE_APACHE, *.LICENSE, or any file in a LICENSES directory)
+// and package manifests (e.g. package.json, pom.xml, *.gemspec). The path may be a virtual path in an archive.
+// A well-known prefix must be followed by ".", "-", "_" or the end of the name, and source files (e.g. license_test.go) are not license files.
+func IsLicenseFile(p string) bool {
+	p = filepath.ToSlash(p)
+	name := path.Base(p)
+	upper := strings.ToUpper(name)
+	lower := strings.ToLower(name)
+	for _, prefix := range licenseFilePrefixes {
+		if rest := strings.TrimPrefix(upper, prefix); len(rest) < len(upper) && (rest == "" || strings.ContainsAny(rest[:1], ".-_")) {
+			if !slices.Contains(sourceSuffixes, path.Ext(lower)) {
+				return true
+			}
+		}
+	}
+	if strings.HasSuffix(upper, ".LICENSE") || strings.HasSuffix(upper, ".LICENCE") || path.Base(path.Dir(p)) == "LICENSES" {
+		return true
+	}
+	if slices.Contains(manifestNames, lower) {
+		return true
+	}
+	for _, suffix := range manifestSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match is true if the slash-separated path matches the glob pattern, where "**" matches any number of directories
+func Match(glob, name string) bool {
+	return matchSegments(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(glob, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(glob[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], name[0]); !ok {
+			return false
+		}
+		glob, name = glob[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// parsePattern parses a line of an ignore file, or a pattern from a flag. Blank lines and comments are not patterns.
+func parsePattern(line, base string) (pattern, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+	p := pattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	p.anchored = strings.Contains(line, "/")
+	p.glob = strings.TrimPrefix(line, "/")
+	return p, p.glob != ""
+}
+
+func parsePatterns(lines []string, base string) []pattern {
+	var ret []pattern
+	for _, line := range lines {
+		if p, ok := parsePattern(line, base); ok {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// matches is true if the pattern matches the slash-separated path relative to the root
+func (p pattern) matches(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, p.base+"/")
+	}
+	if !p.anchored {
+		return Match(p.glob, path.Base(rel))
+	}
+	return Match(p.glob, rel)
+}
+
+func matchAny(patterns []pattern, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.matches(rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignored is true if the last ignore pattern which matches the path is not negated
+func ignored(patterns []pattern, rel string, isDir bool) bool {
+	ret := false
+	for _, p := range patterns {
+		if p.matches(rel, isDir) {
+			ret = !p.negate
+		}
+	}
+	return ret
+}
+
+// readIgnoreFiles returns the patterns of the ignore files in a directory
+func readIgnoreFiles(dir, rel string, warn func(format string, v ...interface{})) []pattern {
+	var ret []pattern
+	for _, name := range IgnoreFileNames {
+		f, err := os.Open(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			warn("skipping %v: %v", filepath.Join(dir, name), err)
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if p, ok := parsePattern(scanner.Text(), rel); ok {
+				ret = append(ret, p)
+			}
+		}
+		f.Close()
+	}
+	return ret
+}
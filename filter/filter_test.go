@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeTree writes the files under a temporary directory and returns it
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestFilter_Walk(t *testing.T) {
+	t.Parallel()
+	root := writeTree(t, map[string]string{
+		".git/config":                      "[core]",
+		".gitignore":                       "node_modules/\n# comment\n*.log\n!keep.log\n/build\n",
+		"build/out.txt":                    "out",
+		"src/build/gen.go":                 "package build",
+		"node_modules/x/LICENSE":           "MIT",
+		"debug.log":                        "log",
+		"keep.log":                         "log",
+		"LICENSE":                          "Apache-2.0",
+		"empty.txt":                        "",
+		"image.png":                        "\x89PNG\x00\x00",
+		"lib/foo.jar":                      "PK\x03\x04\x00",
+		"src/main.go":                      "package main",
+		"src/vendor/.licensescannerignore": "*.go\n",
+		"src/vendor/dep.go":                "package dep",
+		"src/vendor/COPYING":               "GPL",
+		"docs/package.json":                "{}",
+	})
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{
+			name: "ignore files",
+			want: []string{
+				"LICENSE", "docs/package.json", "src/vendor/COPYING",
+				".gitignore", "image.png", "keep.log", "lib/foo.jar", "src/build/gen.go", "src/main.go", "src/vendor/.licensescannerignore",
+			},
+		},
+		{
+			name:   "no ignore",
+			filter: Filter{NoIgnore: true, Exclude: []string{".git"}},
+			want: []string{
+				"LICENSE", "docs/package.json", "node_modules/x/LICENSE", "src/vendor/COPYING",
+				".gitignore", "build/out.txt", "debug.log", "image.png", "keep.log", "lib/foo.jar", "src/build/gen.go", "src/main.go",
+				"src/vendor/.licensescannerignore", "src/vendor/dep.go",
+			},
+		},
+		{
+			name:   "include and exclude",
+			filter: Filter{Include: []string{"src/**/*.go", "LICENSE"}, Exclude: []string{"build"}},
+			want:   []string{"LICENSE", "src/main.go"},
+		},
+		{
+			name:   "license files only",
+			filter: Filter{LicenseFilesOnly: true},
+			want:   []string{"LICENSE", "docs/package.json", "src/vendor/COPYING", "lib/foo.jar"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			if err := tt.filter.Walk(root, t.Logf, func(p string) error {
+				rel, _ := filepath.Rel(root, p)
+				got = append(got, filepath.ToSlash(rel))
+				return nil
+			}); err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			// license files are first, in walk order
+			licenseFiles := 0
+			for _, p := range got {
+				if IsLicenseFile(p) {
+					licenseFiles++
+				}
+			}
+			sort.Strings(got[licenseFiles:])
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("Walk() Diff(-want +got) = %v", d)
+			}
+		})
+	}
+
+	if err := (Filter{}).Walk(filepath.Join(root, "missing"), t.Logf, func(string) error { return nil }); err == nil {
+		t.Error("Walk() of a missing directory expected an error")
+	}
+}
+
+func TestIsLicenseFile(t *testing.T) {
+	t.Parallel()
+	for name, want := range map[string]bool{
+		"LICENSE": true, "license.md": true, "COPYING.LIB": true, "NOTICE": true, "foo.LICENSE": true, "LICENSES/MIT.txt": true,
+		"a/package.json": true, "pom.xml": true, "x.gemspec": true, "dist.zip!/META-INF/LICENSE.txt": true,
+		"LICENSE-MIT": true, "LICENSE_APACHE.txt": true, "COPYRIGHT.txt": true,
+		"main.go": false, "README.md": false, "license/main.go": false,
+		"noticeboard.go": false, "license_test.go": false, "copyrightutil.go": false, "Licensed.txt": false, "license.go": false,
+	} {
+		if got := IsLicenseFile(name); got != want {
+			t.Errorf("IsLicenseFile(%q) = %v want %v", name, got, want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		glob, name string
+		want       bool
+	}{
+		{"*.go", "main.go", true},
+		{"src/*.go", "src/a/main.go", false},
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/a/b/main.go", true},
+		{"**/vendor", "a/vendor", true},
+		{"**", "a/b", true},
+		{"vendor", "vendor/a", false},
+	} {
+		if got := Match(tt.glob, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v want %v", tt.glob, tt.name, got, tt.want)
+		}
+	}
+	if err := ValidatePatterns([]string{"*.go", "["}); err == nil {
+		t.Error("ValidatePatterns() expected an error")
+	}
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+// ahoCorasick is an Aho-Corasick automaton, which finds every occurrence of a set of keys in a single pass over a text
+type ahoCorasick struct {
+	nodes []acNode
+	// root holds the transitions of the root node by byte, which is visited for most bytes of a text
+	root [256]int32
+}
+
+// acNode is a node of the trie of keys
+type acNode struct {
+	// edges are the transitions to the child nodes
+	edges []acEdge
+	// fail is the node of the longest proper suffix of this node which is also in the trie
+	fail int32
+	// out is the nearest node on the fail chain which ends a key, or 0
+	out int32
+	// key is the index of the key which ends at this node, or -1
+	key int32
+}
+
+type acEdge struct {
+	b  byte
+	to int32
+}
+
+// newAhoCorasick builds the automaton for the non-empty keys. Each key is reported with its index.
+func newAhoCorasick(keys []string) *ahoCorasick {
+	a := &ahoCorasick{nodes: []acNode{{key: -1}}}
+	for i, key := range keys {
+		n := int32(0)
+		for j := 0; j < len(key); j++ {
+			next := a.child(n, key[j])
+			if next == 0 {
+				next = int32(len(a.nodes))
+				a.nodes = append(a.nodes, acNode{key: -1})
+				a.nodes[n].edges = append(a.nodes[n].edges, acEdge{b: key[j], to: next})
+				if n == 0 {
+					a.root[key[j]] = next
+				}
+			}
+			n = next
+		}
+		if n != 0 {
+			a.nodes[n].key = int32(i)
+		}
+	}
+
+	// set the fail and out links breadth first, so the links of shorter suffixes are set first
+	queue := make([]int32, 0, len(a.nodes))
+	for _, e := range a.nodes[0].edges {
+		queue = append(queue, e.to)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range a.nodes[n].edges {
+			f := a.nodes[n].fail
+			for f != 0 && a.child(f, e.b) == 0 {
+				f = a.nodes[f].fail
+			}
+			fail := a.child(f, e.b)
+			a.nodes[e.to].fail = fail
+			if a.nodes[fail].key >= 0 {
+				a.nodes[e.to].out = fail
+			} else {
+				a.nodes[e.to].out = a.nodes[fail].out
+			}
+			queue = append(queue, e.to)
+		}
+	}
+	return a
+}
+
+// child returns the child of the node for the byte, or 0
+func (a *ahoCorasick) child(n int32, b byte) int32 {
+	if n == 0 {
+		return a.root[b]
+	}
+	for _, e := range a.nodes[n].edges {
+		if e.b == b {
+			return e.to
+		}
+	}
+	return 0
+}
+
+// find calls fn with the index of each key found in the text and the offset just after it, in the order in which they end
+func (a *ahoCorasick) find(text string, fn func(key int, end int)) {
+	n := int32(0)
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		next := a.child(n, b)
+		for next == 0 && n != 0 {
+			n = a.nodes[n].fail
+			next = a.child(n, b)
+		}
+		n = next
+		if n == 0 {
+			continue
+		}
+		out := n
+		if a.nodes[out].key < 0 {
+			out = a.nodes[out].out
+		}
+		for out != 0 {
+			fn(int(a.nodes[out].key), i+1)
+			out = a.nodes[out].out
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"context"
+	"os"
+
+	"github.com/CycloneDX/license-scanner/archive"
+	"github.com/CycloneDX/license-scanner/filter"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+// IdentifyLicensesInArchive identifies the licenses in each file of a zip, jar, tar, tar.gz, tgz, whl, or nupkg archive,
+// including nested archives up to options.ArchiveLimits.MaxDepth. Each result File is a virtual path such as
+// "lib/foo.jar!/META-INF/LICENSE". Files which are not scanned have a result with a Status and Notes instead of matches.
+// When a limit is exceeded or the archive is corrupt, the results so far are returned with an error result for the archive.
+// An error is only returned with the result of an unreadable archive, or when the context is done.
+func IdentifyLicensesInArchive(filePath string, options Options, licenseLibrary *licenses.LicenseLibrary) ([]IdentifierResults, error) {
+	return IdentifyLicensesInArchiveContext(context.Background(), filePath, options, licenseLibrary)
+}
+
+// IdentifyLicensesInArchiveContext is IdentifyLicensesInArchive, which stops with the error of the context when it is canceled or its deadline passes
+func IdentifyLicensesInArchiveContext(ctx context.Context, filePath string, options Options, licenseLibrary *licenses.LicenseLibrary) ([]IdentifierResults, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return []IdentifierResults{newSkippedResult(filePath, StatusUnreadable, err.Error())}, err
+	}
+	var ret []IdentifierResults
+	err := archive.WalkFile(filePath, options.ArchiveLimits, func(e archive.Entry) error {
+		if options.Filter.LicenseFilesOnly && !filter.IsLicenseFile(e.Name) {
+			return nil
+		}
+		result, err := IdentifyLicensesInEntryContext(ctx, e, options, licenseLibrary)
+		if err != nil {
+			return err
+		}
+		ret = append(ret, result)
+		return nil
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ret, ctxErr
+	}
+	if err != nil {
+		ret = append(ret, newSkippedResult(filePath, StatusError, err.Error()))
+	}
+	compareDeclared(ret)
+	return ret, nil
+}
+
+// IdentifyLicensesInEntryContext identifies the licenses in a file of an archive (see archive.Walk).
+// An entry which is not scanned has a result with a Status and Notes instead of matches.
+// An error is only returned when the context is done.
+func IdentifyLicensesInEntryContext(ctx context.Context, e archive.Entry, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	if e.Err != nil {
+		return newSkippedResult(e.Name, entryStatus(e.Err), e.Err.Error()), nil
+	}
+	if len(e.Content) == 0 {
+		return newSkippedResult(e.Name, StatusSkippedEmpty, "empty file"), nil
+	}
+	result, err := IdentifyLicensesInStringContext(ctx, string(e.Content), options, licenseLibrary)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return IdentifierResults{}, ctxErr
+	}
+	if err != nil {
+		result = newSkippedResult(e.Name, StatusError, err.Error())
+	}
+	result.File = e.Name
+	if result.Status == StatusScanned {
+		result.Declared = declaredLicenses(licenseLibrary, e.Name, e.Content)
+	}
+	return result, nil
+}
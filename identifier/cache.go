@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/CycloneDX/license-scanner/normalizer"
+)
+
+// cacheVersion is part of every cache key. Change it when the identifier results change for the same input.
+const cacheVersion = "9"
+
+// cacheEntry is the cached form of the results.
+// The match offsets are in the original text, so the entry is only used for the same original text.
+type cacheEntry struct {
+	OriginalSha256 string
+	Results        IdentifierResults
+}
+
+// cacheKey is the SHA-256 of the normalized text with the options which change the matches.
+// Notes are not part of the key because they are copied from the options.
+func cacheKey(options Options, normalizedData *normalizer.NormalizationData) string {
+	e := options.Enhancements
+	key := fmt.Sprintf("%v-v%v-%v", normalizedData.Hash.Sha256, cacheVersion, flagString(options.OmitBlocks, e.AddTextBlocks, e.FlagAcceptable, e.FlagCopyrights, e.FlagKeywords))
+	if e.FlagKeywords && len(e.KeywordRules) > 0 {
+		key = fmt.Sprintf("%v-k%v", key, keywordRulesKey(e.KeywordRules))
+	}
+	if options.MinConfidence > 0 || options.MinSimilarity > 0 {
+		key = fmt.Sprintf("%v-%v-%v", key, options.MinConfidence, options.MinSimilarity)
+	}
+	return key
+}
+
+func flagString(flags ...bool) string {
+	b := make([]byte, len(flags))
+	for i, f := range flags {
+		b[i] = '0'
+		if f {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
+
+func originalSha256(normalizedData *normalizer.NormalizationData) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(normalizedData.OriginalText)))
+}
+
+// getCachedResults returns the cached results for the text, if any.
+// Cache errors are logged and treated as a miss.
+func getCachedResults(options Options, normalizedData *normalizer.NormalizationData) (IdentifierResults, bool) {
+	b, ok, err := options.Cache.Get(cacheKey(options, normalizedData))
+	if err != nil {
+		Logger.Debugf("cache read error: %v", err)
+		return IdentifierResults{}, false
+	}
+	if !ok {
+		return IdentifierResults{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		Logger.Debugf("invalid cache entry: %v", err)
+		return IdentifierResults{}, false
+	}
+	if entry.OriginalSha256 != originalSha256(normalizedData) {
+		return IdentifierResults{}, false
+	}
+
+	results := entry.Results
+	results.OriginalText = normalizedData.OriginalText
+	results.NormalizedText = normalizedData.NormalizedText
+	results.Hash = normalizedData.Hash
+	results.Notes = options.Enhancements.AddNotes
+	if results.Matches == nil {
+		results.Matches = make(map[string][]Match)
+	}
+	return results, true
+}
+
+// putCachedResults stores the results without the texts, which the caller already has.
+// Cache errors are logged and ignored.
+func putCachedResults(options Options, normalizedData *normalizer.NormalizationData, results IdentifierResults) {
+	results.File = ""
+	results.OriginalText = ""
+	results.NormalizedText = ""
+	results.Hash = normalizer.Digest{}
+	results.Notes = ""
+	b, err := json.Marshal(cacheEntry{OriginalSha256: originalSha256(normalizedData), Results: results})
+	if err != nil {
+		Logger.Debugf("cache marshal error: %v", err)
+		return
+	}
+	if err := options.Cache.Put(cacheKey(options, normalizedData), b); err != nil {
+		Logger.Debugf("cache write error: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/cache"
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/normalizer"
+)
+
+func TestIdentify_Cache(t *testing.T) {
+	t.Parallel()
+	flags := configurer.NewDefaultFlags()
+	if err := flags.Set(configurer.ConfigPathFlag, "../testdata/config/"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := configurer.InitConfig(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	const input = "Copyright 2022 Someone\nLicensed under the Apache License, Version 2.0 (the \"License\")"
+	c := cache.NewMemoryCache()
+	options := defaultOptions()
+	options.Cache = c
+
+	uncached, err := IdentifyLicensesInString(input, defaultOptions(), licenseLibrary)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInString() error = %v", err)
+	}
+	first, err := IdentifyLicensesInString(input, options, licenseLibrary)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInString() error = %v", err)
+	}
+	if d := cmp.Diff(uncached, first); d != "" {
+		t.Errorf("results with an empty cache: Diff(-want +got) = %v", d)
+	}
+
+	// replace the cached matches to verify that the next call reads from the cache
+	normalizedData := normalizer.NormalizationData{OriginalText: input}
+	if err := normalizedData.NormalizeText(); err != nil {
+		t.Fatal(err)
+	}
+	key := cacheKey(options, &normalizedData)
+	b, ok, _ := c.Get(key)
+	if !ok {
+		t.Fatalf("expected cache entry for %v", key)
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		t.Fatalf("invalid cache entry: %v", err)
+	}
+	if entry.Results.OriginalText != "" || entry.Results.NormalizedText != "" {
+		t.Errorf("expected cache entry without texts got %+v", entry.Results)
+	}
+	entry.Results.Matches = map[string][]Match{"Cached": {{Begins: 1, Ends: 2}}}
+	b, _ = json.Marshal(entry)
+	_ = c.Put(key, b)
+
+	cached, err := IdentifyLicensesInString(input, options, licenseLibrary)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInString() error = %v", err)
+	}
+	if d := cmp.Diff(entry.Results.Matches, cached.Matches); d != "" {
+		t.Errorf("expected cached matches: Diff(-want +got) = %v", d)
+	}
+	if cached.OriginalText != input || cached.Hash != normalizedData.Hash {
+		t.Errorf("expected cached results with the input text and hash got %+v", cached)
+	}
+
+	// the same normalized text with a different original text has different offsets, so it is not read from the cache
+	spaced, err := IdentifyLicensesInString("  "+input, options, licenseLibrary)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInString() error = %v", err)
+	}
+	if _, ok := spaced.Matches["Cached"]; ok {
+		t.Errorf("expected a cache miss for different original text")
+	}
+
+	// other options are cached separately
+	options.Enhancements.FlagKeywords = true
+	if cacheKey(options, &normalizedData) == key {
+		t.Errorf("expected a different cache key for different options")
+	}
+}
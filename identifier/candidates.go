@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+// maxKeyLength is the length of the prefix of each string which is found by the automaton.
+// A found prefix is then compared with the whole string, which keeps the automaton small for long static blocks.
+const maxKeyLength = 32
+
+// indexKey is the key of each index which the identifier keeps with a license library (see licenses.LicenseLibrary.Index)
+type indexKey int
+
+const (
+	candidateIndexKey indexKey = iota
+	similarityIndexKey
+	manifestIndexKey
+)
+
+// candidateIndex finds the licenses which may match a normalized text, with a single pass over the text.
+// A license is a candidate when all the static blocks of one of its primary patterns (see licenses.LicensePreChecks),
+// or one of its aliases or URLs, are in the text. Other licenses cannot match, so they are not searched.
+type candidateIndex struct {
+	// strs are the distinct static blocks, aliases, and URLs
+	strs []string
+	// keys are the distinct prefixes of strs which the matcher finds, with the strs which have each prefix
+	keys    []string
+	keyStrs [][]int
+	matcher *ahoCorasick
+	// always are the licenses which are searched for any text
+	always   []string
+	licenses []indexedLicense
+}
+
+// indexedLicense holds the indexes in strs of the strings which make a license a candidate
+type indexedLicense struct {
+	id string
+	// patterns are the static blocks of each primary pattern, which must all be found
+	patterns [][]int
+	// any are the aliases and URLs, one of which must be found
+	any []int
+}
+
+// getCandidateIndex returns the candidateIndex of the license library, which is built again after licenses are added
+func getCandidateIndex(licenseLibrary *licenses.LicenseLibrary) *candidateIndex {
+	return licenseLibrary.Index(candidateIndexKey, func() interface{} { return newCandidateIndex(licenseLibrary) }).(*candidateIndex)
+}
+
+func newCandidateIndex(licenseLibrary *licenses.LicenseLibrary) *candidateIndex {
+	index := &candidateIndex{}
+	strIndexes := make(map[string]int)
+	keyIndexes := make(map[string]int)
+	add := func(s string) int {
+		if i, ok := strIndexes[s]; ok {
+			return i
+		}
+		i := len(index.strs)
+		strIndexes[s] = i
+		index.strs = append(index.strs, s)
+
+		key := s
+		if len(key) > maxKeyLength {
+			key = key[:maxKeyLength]
+		}
+		k, ok := keyIndexes[key]
+		if !ok {
+			k = len(index.keys)
+			keyIndexes[key] = k
+			index.keys = append(index.keys, key)
+			index.keyStrs = append(index.keyStrs, nil)
+		}
+		index.keyStrs[k] = append(index.keyStrs[k], i)
+		return i
+	}
+
+	for id, lic := range licenseLibrary.LicenseMap {
+		l := indexedLicense{id: id}
+		always := false
+		for _, pattern := range lic.PrimaryPatterns {
+			preChecks := licenseLibrary.PrimaryPatternPreCheckMap[licenses.LicensePatternKey{FilePath: pattern.FileName}]
+			if preChecks == nil || len(preChecks.StaticBlocks) == 0 {
+				always = true
+				break
+			}
+			var blocks []int
+			for _, block := range preChecks.StaticBlocks {
+				if block != "" {
+					blocks = append(blocks, add(block))
+				}
+			}
+			l.patterns = append(l.patterns, blocks)
+		}
+		for _, s := range append(append([]string{}, lic.Aliases...), lic.URLs...) {
+			if s == "" {
+				always = true
+				break
+			}
+			l.any = append(l.any, add(s))
+		}
+		if always {
+			index.always = append(index.always, id)
+		} else if len(l.patterns) > 0 || len(l.any) > 0 {
+			index.licenses = append(index.licenses, l)
+		}
+	}
+	sort.Strings(index.always)
+	sort.Slice(index.licenses, func(i, j int) bool { return index.licenses[i].id < index.licenses[j].id })
+
+	index.matcher = newAhoCorasick(index.keys)
+	return index
+}
+
+// candidates returns the IDs of the licenses which may match the normalized text
+func (index *candidateIndex) candidates(normalizedText string) []string {
+	found := make([]bool, len(index.strs))
+	index.matcher.find(normalizedText, func(key int, end int) {
+		begin := end - len(index.keys[key])
+		for _, i := range index.keyStrs[key] {
+			if !found[i] {
+				found[i] = strings.HasPrefix(normalizedText[begin:], index.strs[i])
+			}
+		}
+	})
+
+	ret := append([]string{}, index.always...)
+	for _, l := range index.licenses {
+		if l.isCandidate(found) {
+			ret = append(ret, l.id)
+		}
+	}
+	return ret
+}
+
+func (l indexedLicense) isCandidate(found []bool) bool {
+	for _, i := range l.any {
+		if found[i] {
+			return true
+		}
+	}
+	for _, blocks := range l.patterns {
+		all := true
+		for _, i := range blocks {
+			if !found[i] {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
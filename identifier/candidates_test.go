@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/exp/slices"
+
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/normalizer"
+)
+
+func TestAhoCorasick(t *testing.T) {
+	t.Parallel()
+	keys := []string{"he", "she", "his", "hers", "s"}
+	var got []string
+	newAhoCorasick(keys).find("ushers his", func(key int, end int) {
+		got = append(got, fmt.Sprintf("%v@%v", keys[key], end))
+	})
+	want := []string{"s@2", "she@4", "he@4", "hers@6", "s@6", "his@10", "s@10"}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("find() Diff(-want +got) = %v", d)
+	}
+}
+
+// newSPDXLibrary returns the license library with the SPDX and custom licenses
+func newSPDXLibrary(tb testing.TB) *licenses.LicenseLibrary {
+	tb.Helper()
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		tb.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		tb.Fatalf("AddAll() error = %v", err)
+	}
+	return licenseLibrary
+}
+
+// readNormalized returns the normalized text of a file
+func readNormalized(tb testing.TB, file string) *normalizer.NormalizationData {
+	tb.Helper()
+	b, err := os.ReadFile(file)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	normalizedData := normalizer.NormalizationData{OriginalText: string(b)}
+	if err := normalizedData.NormalizeText(); err != nil {
+		tb.Fatal(err)
+	}
+	return &normalizedData
+}
+
+func TestCandidates(t *testing.T) {
+	t.Parallel()
+	licenseLibrary := newSPDXLibrary(t)
+	index := getCandidateIndex(licenseLibrary)
+	if len(index.licenses) == 0 {
+		t.Fatal("expected licenses with prechecks in the index")
+	}
+
+	entries, err := os.ReadDir(testDataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{"../testdata/addAll/input/text/0BSD.txt", "testfiles/aml.txt", "testfiles/wcwidth.txt", "../LICENSE", "identifier.go"}
+	for i, e := range entries {
+		// a sample of the SPDX test data, which would take long to search for every license
+		if !e.IsDir() && i%25 == 0 {
+			files = append(files, path.Join(testDataDir, e.Name()))
+		}
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(file, func(t *testing.T) {
+			t.Parallel()
+			normalizedData := readNormalized(t, file)
+			candidates := index.candidates(normalizedData.NormalizedText)
+
+			// every license which matches is a candidate
+			var want, got []string
+			for id, lic := range licenseLibrary.LicenseMap {
+				matches, err := findLicenseInNormalizedData(context.Background(), lic, normalizedData, licenseLibrary)
+				if err != nil {
+					t.Fatalf("findLicenseInNormalizedData(%v) error = %v", id, err)
+				}
+				if len(matches) > 0 {
+					want = append(want, id)
+					if slices.Contains(candidates, id) {
+						got = append(got, id)
+					}
+				}
+			}
+			sort.Strings(want)
+			sort.Strings(got)
+			if d := cmp.Diff(want, got); d != "" {
+				t.Errorf("candidates() of matching licenses Diff(-want +got) = %v", d)
+			}
+			if len(candidates) >= len(licenseLibrary.LicenseMap)/2 {
+				t.Errorf("expected fewer candidates than licenses got %v of %v", len(candidates), len(licenseLibrary.LicenseMap))
+			}
+		})
+	}
+
+	// the index is built again when licenses are added
+	if got := getCandidateIndex(licenseLibrary); got != index {
+		t.Error("getCandidateIndex() expected the same index")
+	}
+	licenseLibrary.LicenseMap["Added"] = licenses.License{Aliases: []string{"added license"}}
+	licenseLibrary.ResetIndexes()
+	if got := getCandidateIndex(licenseLibrary); got == index || !slices.Contains(got.candidates("the added license"), "Added") {
+		t.Error("getCandidateIndex() expected a new index with the added license")
+	}
+}
+
+// findInAllLicenses searches every license in the library, as done before the candidate index
+func findInAllLicenses(licenseLibrary *licenses.LicenseLibrary, normalizedData *normalizer.NormalizationData) (int, error) {
+	found := 0
+	for _, lic := range licenseLibrary.LicenseMap {
+		matches, err := findLicenseInNormalizedData(context.Background(), lic, normalizedData, licenseLibrary)
+		if err != nil {
+			return found, err
+		}
+		found += len(matches)
+	}
+	return found, nil
+}
+
+func benchmarkFiles() map[string]string {
+	return map[string]string{
+		"license": path.Join(testDataDir, "Apache-2.0.txt"),
+		"source":  "identifier.go",
+	}
+}
+
+func BenchmarkFindAllLicenses(b *testing.B) {
+	licenseLibrary := newSPDXLibrary(b)
+	getCandidateIndex(licenseLibrary)
+	for name, file := range benchmarkFiles() {
+		normalizedData := readNormalized(b, file)
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := findAllLicensesInNormalizedData(context.Background(), licenseLibrary, normalizedData, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFindAllLicenses_withoutIndex(b *testing.B) {
+	licenseLibrary := newSPDXLibrary(b)
+	for name, file := range benchmarkFiles() {
+		normalizedData := readNormalized(b, file)
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := findInAllLicenses(licenseLibrary, normalizedData); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewCandidateIndex(b *testing.B) {
+	licenseLibrary := newSPDXLibrary(b)
+	for i := 0; i < b.N; i++ {
+		newCandidateIndex(licenseLibrary)
+	}
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+// ExpressionOrder is the order of the operands of a composed license expression
+type ExpressionOrder string
+
+const (
+	// ExpressionOrderText orders the operands by file name and then by where they were found in the text
+	ExpressionOrderText ExpressionOrder = "text"
+	// ExpressionOrderID orders the operands alphabetically
+	ExpressionOrderID ExpressionOrder = "id"
+)
+
+// ExpressionOrders lists the supported values of ExpressionOrder
+var ExpressionOrders = []ExpressionOrder{ExpressionOrderText, ExpressionOrderID}
+
+// ParseExpressionOrder returns the ExpressionOrder named by s, or an error if it is not supported
+func ParseExpressionOrder(s string) (ExpressionOrder, error) {
+	for _, o := range ExpressionOrders {
+		if string(o) == s {
+			return o, nil
+		}
+	}
+	return "", fmt.Errorf("invalid expression order %q (expected text or id)", s)
+}
+
+// dualLicenseWindow is how far after a dual license phrase (in bytes) the licenses which are offered as alternatives are found
+const dualLicenseWindow = 300
+
+var dualLicenseRE = regexp.MustCompile(`(?i)\bdual[\s-]*licen[cs](?:ed|ing)\b|\blicen[cs]ed\s+under\s+(?:the\s+terms\s+of\s+)?either\b|\bunder\s+either\s+of\b`)
+
+// term is an operand of a composed expression with where it was found
+type term struct {
+	expression *expression.Expression
+	file       string
+	begins     int
+}
+
+// ComposeExpression returns the AND of the licenses found in the results, or nil if none were found.
+// Each SPDX-License-Identifier tag keeps its expression, licenses which follow a dual license phrase
+// (e.g. "dual licensed under X or Y") are an OR, and a mutated license (e.g. "X WITH Y") replaces the licenses it was made from.
+// Exceptions are only included with a license.
+func ComposeExpression(licenseMap licenses.LicenseMap, order ExpressionOrder, results ...IdentifierResults) *expression.Expression {
+	var terms []term
+	for i := range results {
+		terms = append(terms, composeTerms(licenseMap, &results[i])...)
+	}
+
+	if order == ExpressionOrderID {
+		sort.SliceStable(terms, func(i, j int) bool {
+			return terms[i].expression.String() < terms[j].expression.String()
+		})
+	} else {
+		sort.SliceStable(terms, func(i, j int) bool {
+			if terms[i].file != terms[j].file {
+				return terms[i].file < terms[j].file
+			} else if terms[i].begins != terms[j].begins {
+				return terms[i].begins < terms[j].begins
+			}
+			return terms[i].expression.String() < terms[j].expression.String()
+		})
+	}
+
+	// a license which is already part of a compound term (e.g. "X OR Y") of the same file is not added on its own,
+	// but a license found alone in another file still is
+	type fileLicense struct{ file, license string }
+	inCompound := make(map[fileLicense]bool)
+	for _, t := range terms {
+		if !t.expression.IsLicense() {
+			for _, l := range t.expression.Licenses() {
+				inCompound[fileLicense{t.file, l.String()}] = true
+			}
+		}
+	}
+	operands := make([]*expression.Expression, 0, len(terms))
+	for _, t := range terms {
+		if t.expression.IsLicense() && inCompound[fileLicense{t.file, t.expression.String()}] {
+			continue
+		}
+		operands = append(operands, t.expression)
+	}
+
+	e := expression.Join(expression.And, operands...)
+	if e != nil && order == ExpressionOrderID {
+		e = e.Sorted()
+	}
+	return e
+}
+
+// composeTerms returns the tags, dual licenses, and other licenses found in the results
+func composeTerms(licenseMap licenses.LicenseMap, ir *IdentifierResults) []term {
+	var terms []term
+	for _, tag := range ir.LicenseTags {
+		terms = append(terms, term{expression: tag.Expression, file: ir.File, begins: tag.Begins})
+	}
+
+	// parse each matched ID (e.g. "X WITH Y" from a mutator) and drop the licenses which were mutated.
+	// Tag matches are left out, because each tag is a term with its expression.
+	var leaves []term
+	replaced := make(map[string]bool)
+	for id, all := range ir.Matches {
+		var matches []Match
+		for _, m := range all {
+			if m.Kind != MatchKindTag {
+				matches = append(matches, m)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		e, err := expression.Parse(id)
+		if err != nil || !e.IsLicense() {
+			e = &expression.Expression{License: id}
+		}
+		if e.Exception != "" {
+			replaced[strings.TrimSuffix(e.License, "+")] = true
+			replaced[e.Exception] = true
+		}
+		begins := matches[0].Begins
+		for _, m := range matches {
+			if m.Begins < begins {
+				begins = m.Begins
+			}
+		}
+		leaves = append(leaves, term{expression: e, file: ir.File, begins: begins})
+	}
+	var kept []term
+	for _, l := range leaves {
+		id := l.expression.String()
+		if replaced[id] || licenseMap[id].LicenseInfo.SPDXException {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].begins != kept[j].begins {
+			return kept[i].begins < kept[j].begins
+		}
+		return kept[i].expression.String() < kept[j].expression.String()
+	})
+
+	// the licenses found after a dual license phrase are alternatives
+	grouped := make([]bool, len(kept))
+	for _, ii := range dualLicenseRE.FindAllStringIndex(ir.OriginalText, -1) {
+		var group []*expression.Expression
+		var members []int
+		for i, l := range kept {
+			if !grouped[i] && l.begins >= ii[0] && l.begins < ii[1]+dualLicenseWindow {
+				group = append(group, l.expression)
+				members = append(members, i)
+			}
+		}
+		if len(members) < 2 {
+			continue
+		}
+		for _, i := range members {
+			grouped[i] = true
+		}
+		terms = append(terms, term{expression: expression.Join(expression.Or, group...), file: ir.File, begins: kept[members[0]].begins})
+	}
+	for i, l := range kept {
+		if !grouped[i] {
+			terms = append(terms, l)
+		}
+	}
+	return terms
+}
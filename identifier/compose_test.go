@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func TestComposeExpression(t *testing.T) {
+	t.Parallel()
+	licenseMap := licenses.LicenseMap{
+		"MIT":                     {SPDXLicenseID: "MIT"},
+		"Apache-2.0":              {SPDXLicenseID: "Apache-2.0"},
+		"GPL-2.0-only":            {SPDXLicenseID: "GPL-2.0-only"},
+		"Classpath-exception-2.0": {SPDXLicenseID: "Classpath-exception-2.0", LicenseInfo: licenses.LicenseInfo{SPDXException: true}},
+	}
+	tag, err := expression.Parse("MIT OR Apache-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		order   ExpressionOrder
+		results []IdentifierResults
+		want    string
+	}{
+		{
+			name:    "no licenses",
+			results: []IdentifierResults{{Matches: map[string][]Match{}}},
+		},
+		{
+			name: "independent licenses in text order",
+			results: []IdentifierResults{{Matches: map[string][]Match{
+				"MIT":        {{Begins: 100, Ends: 200}},
+				"Apache-2.0": {{Begins: 300, Ends: 400}, {Begins: 0, Ends: 50}},
+			}}},
+			want: "Apache-2.0 AND MIT",
+		},
+		{
+			name:  "independent licenses in ID order",
+			order: ExpressionOrderID,
+			results: []IdentifierResults{{Matches: map[string][]Match{
+				"MIT":          {{Begins: 0, Ends: 50}},
+				"GPL-2.0-only": {{Begins: 100, Ends: 200}},
+			}}},
+			want: "GPL-2.0-only AND MIT",
+		},
+		{
+			name: "mutated license replaces its license and exception",
+			results: []IdentifierResults{{Matches: map[string][]Match{
+				"GPL-2.0-only":                              {{Begins: 0, Ends: 50}},
+				"Classpath-exception-2.0":                   {{Begins: 51, Ends: 100}},
+				"GPL-2.0-only WITH Classpath-exception-2.0": {{Begins: 0, Ends: 100}},
+				"MIT": {{Begins: 200, Ends: 300}},
+			}}},
+			want: "GPL-2.0-only WITH Classpath-exception-2.0 AND MIT",
+		},
+		{
+			name: "exception alone",
+			results: []IdentifierResults{{Matches: map[string][]Match{
+				"Classpath-exception-2.0": {{Begins: 0, Ends: 50}},
+			}}},
+		},
+		{
+			name: "dual license phrase",
+			results: []IdentifierResults{{
+				OriginalText: "This project is dual licensed under the MIT License or the Apache License 2.0.\n\nIt includes GPL code.",
+				Matches: map[string][]Match{
+					"MIT":          {{Begins: 40, Ends: 50}},
+					"Apache-2.0":   {{Begins: 59, Ends: 76}},
+					"GPL-2.0-only": {{Begins: 800, Ends: 900}},
+				},
+			}},
+			want: "(MIT OR Apache-2.0) AND GPL-2.0-only",
+		},
+		{
+			name: "a single license after a dual license phrase",
+			results: []IdentifierResults{{
+				OriginalText: "Dual licensed under the MIT License.",
+				Matches:      map[string][]Match{"MIT": {{Begins: 24, Ends: 34}}},
+			}},
+			want: "MIT",
+		},
+		{
+			name: "tag keeps its expression",
+			results: []IdentifierResults{{
+				LicenseTags: []LicenseTag{{Begins: 20, Ends: 36, Expression: tag}},
+				Matches: map[string][]Match{
+					"MIT":          {{Begins: 20, Ends: 22, Kind: MatchKindTag}},
+					"Apache-2.0":   {{Begins: 27, Ends: 36, Kind: MatchKindTag}},
+					"GPL-2.0-only": {{Begins: 0, Ends: 10}},
+				},
+			}},
+			want: "GPL-2.0-only AND (MIT OR Apache-2.0)",
+		},
+		{
+			name:  "tag in ID order",
+			order: ExpressionOrderID,
+			results: []IdentifierResults{{
+				LicenseTags: []LicenseTag{{Begins: 20, Ends: 36, Expression: tag}},
+				Matches:     map[string][]Match{"MIT": {{Begins: 20, Ends: 22, Kind: MatchKindTag}}},
+			}},
+			want: "Apache-2.0 OR MIT",
+		},
+		{
+			name: "a license in a tag of the same file is not added on its own",
+			results: []IdentifierResults{{
+				File:        "a/README",
+				LicenseTags: []LicenseTag{{Begins: 20, Ends: 36, Expression: tag}},
+				Matches:     map[string][]Match{"MIT": {{Begins: 20, Ends: 22, Kind: MatchKindTag}, {Begins: 100, Ends: 110}}},
+			}},
+			want: "MIT OR Apache-2.0",
+		},
+		{
+			name: "a license alone in another file is kept",
+			results: []IdentifierResults{
+				{File: "b/LICENSE", Matches: map[string][]Match{"MIT": {{Begins: 0, Ends: 10}}}},
+				{
+					File:        "a/README",
+					LicenseTags: []LicenseTag{{Begins: 20, Ends: 36, Expression: tag}},
+					Matches:     map[string][]Match{"MIT": {{Begins: 20, Ends: 22, Kind: MatchKindTag}}},
+				},
+			},
+			want: "(MIT OR Apache-2.0) AND MIT",
+		},
+		{
+			name: "multiple files in file order without duplicates",
+			results: []IdentifierResults{
+				{File: "b/LICENSE", Matches: map[string][]Match{"MIT": {{Begins: 0, Ends: 10}}}},
+				{File: "a/LICENSE", Matches: map[string][]Match{"Apache-2.0": {{Begins: 0, Ends: 10}}}},
+				{File: "c/LICENSE", Matches: map[string][]Match{"Apache-2.0": {{Begins: 0, Ends: 10}}}},
+			},
+			want: "Apache-2.0 AND MIT",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := ComposeExpression(licenseMap, tt.order, tt.results...)
+			gotString := ""
+			if got != nil {
+				gotString = got.String()
+			}
+			if gotString != tt.want {
+				t.Errorf("ComposeExpression() = %q want %q", gotString, tt.want)
+			}
+		})
+	}
+
+	if tag.String() != "MIT OR Apache-2.0" {
+		t.Errorf("ComposeExpression() changed the tag expression to %q", tag.String())
+	}
+}
+
+func TestParseExpressionOrder(t *testing.T) {
+	t.Parallel()
+	if got, err := ParseExpressionOrder("id"); err != nil || got != ExpressionOrderID {
+		t.Errorf("ParseExpressionOrder(id) = %v, %v want %v", got, err, ExpressionOrderID)
+	}
+	if _, err := ParseExpressionOrder("random"); err == nil {
+		t.Error("ParseExpressionOrder(random) expected an error")
+	}
+}
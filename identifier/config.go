@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/viper"
+
+	"github.com/CycloneDX/license-scanner/archive"
+	"github.com/CycloneDX/license-scanner/cache"
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/filter"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/resources"
+)
+
+// NewOptions returns the options of the flags in the config (match filters, expression order, archive limits, file timeout,
+// file filter, enhancements, keyword rules, and cache), or an error if a flag is invalid.
+// The command and the API both build their options with it, so the flags are validated the same way.
+func NewOptions(cfg *viper.Viper, licenseLibrary *licenses.LicenseLibrary) (options Options, err error) {
+	options = Options{
+		Enhancements: Enhancements{
+			FlagAcceptable: cfg.GetBool(configurer.AcceptableFlag),
+			FlagCopyrights: cfg.GetBool(configurer.CopyrightsFlag),
+			FlagKeywords:   cfg.GetBool(configurer.KeywordsFlag),
+		},
+	}
+	if options.MinConfidence, err = scoreFlag(cfg, configurer.MinConfidenceFlag); err != nil {
+		return
+	}
+	if options.MinSimilarity, err = scoreFlag(cfg, configurer.MinSimilarityFlag); err != nil {
+		return
+	}
+	if options.ExpressionOrder, err = ParseExpressionOrder(cfg.GetString(configurer.ExpressionOrderFlag)); err != nil {
+		err = fmt.Errorf("invalid --%v %q (expected text or id)", configurer.ExpressionOrderFlag, cfg.GetString(configurer.ExpressionOrderFlag))
+		return
+	}
+	if options.ArchiveLimits, err = archiveLimits(cfg); err != nil {
+		return
+	}
+	if options.FileTimeout = cfg.GetDuration(configurer.FileTimeoutFlag); options.FileTimeout < 0 {
+		err = fmt.Errorf("invalid --%v %v (expected 0 or more)", configurer.FileTimeoutFlag, options.FileTimeout)
+		return
+	}
+	options.Filter = filter.Filter{
+		Include:          cfg.GetStringSlice(configurer.IncludeFlag),
+		Exclude:          cfg.GetStringSlice(configurer.ExcludeFlag),
+		NoIgnore:         cfg.GetBool(configurer.NoIgnoreFlag),
+		LicenseFilesOnly: cfg.GetBool(configurer.LicenseFilesFlag),
+	}
+	for flag, patterns := range map[string][]string{configurer.IncludeFlag: options.Filter.Include, configurer.ExcludeFlag: options.Filter.Exclude} {
+		if err = filter.ValidatePatterns(patterns); err != nil {
+			err = fmt.Errorf("invalid --%v %w", flag, err)
+			return
+		}
+	}
+	if options.Enhancements.KeywordRules, err = loadKeywordRules(cfg, licenseLibrary.Resources); err != nil {
+		return
+	}
+	// the persistent cache is scoped to the license library, so entries from another library are not used
+	if cacheDir := cfg.GetString(configurer.CacheDirFlag); cacheDir != "" {
+		options.Cache, err = cache.NewFileCache(cacheDir, licenseLibrary.Fingerprint())
+	}
+	return
+}
+
+// loadKeywordRules returns the default keyword rules merged with the rules of the custom resources, the config file, and the --keywordsFile, in that order,
+// compiled once for every file (see CompileKeywordRules)
+func loadKeywordRules(cfg *viper.Viper, r *resources.Resources) ([]KeywordRule, error) {
+	lists := [][]KeywordRule{DefaultKeywordRules}
+	if r != nil {
+		if b, err := r.ReadCustomKeywordRulesFile(); err == nil {
+			rules, err := ReadKeywordRules(bytes.NewReader(b))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v: %w", resources.KeywordRulesFile, err)
+			}
+			lists = append(lists, rules)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if cfg.IsSet(configurer.KeywordRulesKey) {
+		var rules []KeywordRule
+		if err := cfg.UnmarshalKey(configurer.KeywordRulesKey, &rules); err != nil {
+			return nil, fmt.Errorf("invalid %v in the config file: %w", configurer.KeywordRulesKey, err)
+		}
+		if err := ValidateKeywordRules(rules); err != nil {
+			return nil, fmt.Errorf("invalid %v in the config file: %w", configurer.KeywordRulesKey, err)
+		}
+		lists = append(lists, rules)
+	}
+	if keywordsFile := cfg.GetString(configurer.KeywordsFileFlag); keywordsFile != "" {
+		f, err := os.Open(keywordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%v: %w", configurer.KeywordsFileFlag, err)
+		}
+		defer f.Close()
+		rules, err := ReadKeywordRules(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%v %v: %w", configurer.KeywordsFileFlag, keywordsFile, err)
+		}
+		lists = append(lists, rules)
+	}
+	return CompileKeywordRules(MergeKeywordRules(lists...))
+}
+
+// scoreFlag returns the value of a score flag, or an error if it is not between 0 and 1
+func scoreFlag(cfg *viper.Viper, flag string) (float64, error) {
+	c := cfg.GetFloat64(flag)
+	if c < 0 || c > 1 {
+		return 0, fmt.Errorf("invalid --%v %v (expected 0 to 1)", flag, c)
+	}
+	return c, nil
+}
+
+// archiveLimits returns the limits of the archive flags, or an error if one is negative
+func archiveLimits(cfg *viper.Viper) (archive.Limits, error) {
+	limits := archive.Limits{
+		MaxDepth:     cfg.GetInt(configurer.ArchiveDepthFlag),
+		MaxEntries:   cfg.GetInt(configurer.ArchiveEntriesFlag),
+		MaxBytes:     cfg.GetInt64(configurer.ArchiveBytesFlag),
+		MaxFileBytes: archive.DefaultLimits.MaxFileBytes,
+	}
+	for flag, v := range map[string]int64{
+		configurer.ArchiveDepthFlag:   int64(limits.MaxDepth),
+		configurer.ArchiveEntriesFlag: int64(limits.MaxEntries),
+		configurer.ArchiveBytesFlag:   limits.MaxBytes,
+	} {
+		if v < 0 {
+			return limits, fmt.Errorf("invalid --%v %v (expected 0 or more)", flag, v)
+		}
+	}
+	return limits, nil
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func TestNewOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		flags map[string]string
+		err   string
+	}{
+		{name: "defaults"},
+		{name: "enhancements", flags: map[string]string{configurer.CopyrightsFlag: "true", configurer.KeywordsFlag: "true"}},
+		{name: "invalid score", flags: map[string]string{configurer.MinSimilarityFlag: "2"}, err: "invalid --minSimilarity 2 (expected 0 to 1)"},
+		{name: "invalid expression order", flags: map[string]string{configurer.ExpressionOrderFlag: "random"}, err: `invalid --expressionOrder "random" (expected text or id)`},
+		{name: "invalid archive limit", flags: map[string]string{configurer.ArchiveEntriesFlag: "-1"}, err: "invalid --archiveEntries -1 (expected 0 or more)"},
+		{name: "invalid file timeout", flags: map[string]string{configurer.FileTimeoutFlag: "-1s"}, err: "invalid --fileTimeout -1s (expected 0 or more)"},
+		{name: "invalid pattern", flags: map[string]string{configurer.ExcludeFlag: "["}, err: "invalid --exclude"},
+		{name: "missing keywords file", flags: map[string]string{configurer.KeywordsFileFlag: "missing.yaml"}, err: "invalid --keywordsFile"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			flags := configurer.NewDefaultFlags()
+			_ = flags.Set(configurer.SpdxFlag, "")
+			for flag, value := range tt.flags {
+				if err := flags.Set(flag, value); err != nil {
+					t.Fatalf("Set(%v) error = %v", flag, err)
+				}
+			}
+			cfg, err := configurer.InitConfig(flags)
+			if err != nil {
+				t.Fatalf("InitConfig() error = %v", err)
+			}
+			ll, err := licenses.NewLicenseLibrary(cfg)
+			if err != nil {
+				t.Fatalf("NewLicenseLibrary() error = %v", err)
+			}
+			options, err := NewOptions(cfg, ll)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("NewOptions() error = %v want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewOptions() error = %v", err)
+			}
+			if got, want := options.Enhancements.FlagCopyrights, tt.flags[configurer.CopyrightsFlag] == "true"; got != want {
+				t.Errorf("NewOptions() FlagCopyrights = %v want %v", got, want)
+			}
+			if len(options.Enhancements.KeywordRules) == 0 {
+				t.Error("NewOptions() has no keyword rules")
+			}
+		})
+	}
+}
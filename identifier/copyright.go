@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+var (
+	allRightsReservedRE = regexp.MustCompile(`(?i)all\s+rights\s+reserved\.?`)
+	emailRE             = regexp.MustCompile(`<?([A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,})>?`)
+	urlRE               = regexp.MustCompile(`(?i)<?https?://[^\s>]+>?`)
+	// copyrightMarkerRE finds the words and symbols which mark a statement as a copyright
+	copyrightMarkerRE = regexp.MustCompile(`(?i)SPDX-(?:File|Snippet)CopyrightText:|\bcopyright(?:ed)?\b|\bcopr\.|\(c\)|©`)
+	// copyrightSymbolRE finds the markers which are only used in copyright notices, unlike the word "copyright"
+	copyrightSymbolRE = regexp.MustCompile(`(?i)SPDX-(?:File|Snippet)CopyrightText:|\(c\)|©`)
+	// yearsRE finds a year or a range of years, whose end may have two digits (e.g. 2019-21)
+	yearsRE = regexp.MustCompile(`\b((?:19|20)\d{2})(?:\s*(?:-|–|to)\s*((?:19|20)\d{2}|\d{2})\b)?`)
+	// emptyBracketsRE finds the brackets which are left empty without the emails or URLs they enclosed
+	emptyBracketsRE = regexp.MustCompile(`\(\s*\)|<\s*>|\[\s*\]`)
+	// holderPunctuation is trimmed from the ends of a holder name, with comment characters
+	holderPunctuation = " \t*/#;:,-–=<>!"
+	// holderAbbreviations keep their final period (e.g. "Free Software Foundation, Inc.")
+	holderAbbreviations = []string{"inc", "ltd", "co", "corp", "llc", "gmbh", "ag", "b.v", "s.a", "e.v", "al"}
+)
+
+// CopyrightStatement is a copyright statement flagged by the copyright pattern (see CopyrightPattern),
+// with its holder, years, and emails
+type CopyrightStatement struct {
+	PatternMatch
+	// Holder is the name of the copyright holder, or "" if the statement has none (e.g. "Copyright 2023"),
+	// or has no year, email, or symbol such as "(c)" (e.g. "copyright owner that is granting the License")
+	Holder string
+	Years  []YearRange
+	Emails []string
+	// AllRightsReserved is true if the statement includes "All rights reserved"
+	AllRightsReserved bool
+}
+
+// YearRange is an inclusive range of years, or a year when From and To are the same
+type YearRange struct {
+	From int
+	To   int
+}
+
+func (y YearRange) String() string {
+	if y.From == y.To {
+		return strconv.Itoa(y.From)
+	}
+	return fmt.Sprintf("%d-%d", y.From, y.To)
+}
+
+// CopyrightHolder is a copyright holder of the statements of one or more files, which is identified by its name without case
+type CopyrightHolder struct {
+	Name string
+	// Years are the years of the statements of the holder, merged into ranges
+	Years  []YearRange
+	Emails []string
+	Files  []string
+}
+
+// ParseCopyrightStatement returns the holder, years, emails, and "All rights reserved" marker of a copyright statement
+func ParseCopyrightStatement(m PatternMatch) CopyrightStatement {
+	c := CopyrightStatement{PatternMatch: m}
+	text := m.Text
+	notice := copyrightSymbolRE.MatchString(text)
+	if allRightsReservedRE.MatchString(text) {
+		c.AllRightsReserved = true
+		text = allRightsReservedRE.ReplaceAllString(text, " ")
+	}
+	for _, match := range emailRE.FindAllStringSubmatch(text, -1) {
+		c.Emails = appendUnique(c.Emails, match[1])
+	}
+	text = emailRE.ReplaceAllString(text, " ")
+	text = urlRE.ReplaceAllString(text, " ")
+	text = copyrightMarkerRE.ReplaceAllString(text, " ")
+	for _, match := range yearsRE.FindAllStringSubmatch(text, -1) {
+		from, _ := strconv.Atoi(match[1])
+		to := from
+		switch len(match[2]) {
+		case 4:
+			to, _ = strconv.Atoi(match[2])
+		case 2:
+			// the end of a range like 2019-21 is in the century of its start, or the next one
+			to, _ = strconv.Atoi(match[2])
+			to += from / 100 * 100
+			if to < from {
+				to += 100
+			}
+		}
+		if to < from {
+			from, to = to, from
+		}
+		c.Years = append(c.Years, YearRange{From: from, To: to})
+	}
+	text = yearsRE.ReplaceAllString(text, " ")
+	// Without a year, symbol, or email, the word "copyright" is more likely in the terms of a license than in a notice
+	if notice || len(c.Years) > 0 || len(c.Emails) > 0 {
+		c.Holder = holderName(text)
+	}
+	return c
+}
+
+// ParseCopyrightStatements parses each copyright statement (see ParseCopyrightStatement)
+func ParseCopyrightStatements(matches []PatternMatch) []CopyrightStatement {
+	var ret []CopyrightStatement
+	for _, m := range matches {
+		ret = append(ret, ParseCopyrightStatement(m))
+	}
+	return ret
+}
+
+// holderName is the text which remains of a statement without its markers, years, and emails,
+// with its spaces collapsed and without punctuation or a leading "by"
+func holderName(text string) string {
+	text = strings.Join(strings.Fields(emptyBracketsRE.ReplaceAllString(text, " ")), " ")
+	text = strings.ReplaceAll(text, " ,", ",")
+	for {
+		trimmed := strings.TrimLeft(strings.Trim(text, holderPunctuation), ".")
+		if lower := strings.ToLower(trimmed); strings.HasPrefix(lower, "by ") {
+			trimmed = trimmed[3:]
+		}
+		if trimmed == text {
+			break
+		}
+		text = trimmed
+	}
+	if strings.HasSuffix(text, ".") {
+		words := strings.Fields(text)
+		last := strings.ToLower(strings.TrimSuffix(words[len(words)-1], "."))
+		if !slices.Contains(holderAbbreviations, last) {
+			text = strings.TrimRight(text, ".")
+		}
+	}
+	return text
+}
+
+// CopyrightHolders returns the holders of the copyright statements of the results, sorted by name.
+// Holders with the same name without case, spaces, or a final period are merged, with the name of the first statement.
+func CopyrightHolders(results ...IdentifierResults) []CopyrightHolder {
+	holders := make(map[string]*CopyrightHolder)
+	var keys []string
+	for _, r := range results {
+		for _, c := range r.Copyrights {
+			if c.Holder == "" {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSuffix(strings.Join(strings.Fields(c.Holder), " "), "."))
+			h, ok := holders[key]
+			if !ok {
+				h = &CopyrightHolder{Name: c.Holder}
+				holders[key] = h
+				keys = append(keys, key)
+			}
+			h.Years = append(h.Years, c.Years...)
+			for _, e := range c.Emails {
+				h.Emails = appendUnique(h.Emails, e)
+			}
+			h.Files = appendUnique(h.Files, r.File)
+		}
+	}
+	sort.Strings(keys)
+	ret := make([]CopyrightHolder, 0, len(keys))
+	for _, key := range keys {
+		h := holders[key]
+		h.Years = MergeYears(h.Years)
+		sort.Strings(h.Emails)
+		sort.Strings(h.Files)
+		ret = append(ret, *h)
+	}
+	return ret
+}
+
+// MergeYears returns the years sorted, with the ranges which overlap or are consecutive merged (e.g. 2019-2020 and 2021 into 2019-2021)
+func MergeYears(years []YearRange) []YearRange {
+	if len(years) == 0 {
+		return nil
+	}
+	sorted := append([]YearRange(nil), years...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].From < sorted[j].From })
+	ret := []YearRange{sorted[0]}
+	for _, y := range sorted[1:] {
+		last := &ret[len(ret)-1]
+		if y.From <= last.To+1 {
+			if y.To > last.To {
+				last.To = y.To
+			}
+			continue
+		}
+		ret = append(ret, y)
+	}
+	return ret
+}
+
+// appendUnique appends a value which is not already in the list
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseCopyrightStatement(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		text string
+		want CopyrightStatement
+	}{
+		{
+			text: " * Copyright (c) 2019-2021, 2023 The Foo Authors. All rights reserved.",
+			want: CopyrightStatement{Holder: "The Foo Authors", Years: []YearRange{{2019, 2021}, {2023, 2023}}, AllRightsReserved: true},
+		},
+		{
+			text: "// Copyright 2020 Google LLC",
+			want: CopyrightStatement{Holder: "Google LLC", Years: []YearRange{{2020, 2020}}},
+		},
+		{
+			text: "Copyright (C) 2007 Free Software Foundation, Inc. <https://fsf.org/>",
+			want: CopyrightStatement{Holder: "Free Software Foundation, Inc.", Years: []YearRange{{2007, 2007}}},
+		},
+		{
+			text: "© 1998-02 by Jane Doe <jane@example.com>, John Doe (john@example.org)",
+			want: CopyrightStatement{Holder: "Jane Doe, John Doe", Years: []YearRange{{1998, 2002}}, Emails: []string{"jane@example.com", "john@example.org"}},
+		},
+		{
+			text: "SPDX-FileCopyrightText: 2023 Jane Doe <jane@example.com>",
+			want: CopyrightStatement{Holder: "Jane Doe", Years: []YearRange{{2023, 2023}}, Emails: []string{"jane@example.com"}},
+		},
+		{
+			text: "Copyright 2023",
+			want: CopyrightStatement{Years: []YearRange{{2023, 2023}}},
+		},
+		{
+			text: "Copyright (c) Jane Doe",
+			want: CopyrightStatement{Holder: "Jane Doe"},
+		},
+		{
+			text: "copyright owner that is granting the License",
+			want: CopyrightStatement{},
+		},
+	}
+	for _, tt := range tests {
+		got := ParseCopyrightStatement(PatternMatch{Text: tt.text})
+		if d := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(CopyrightStatement{}, "PatternMatch")); d != "" {
+			t.Errorf("%q: Didn't get expected statement: (-want, +got): %v", tt.text, d)
+		}
+	}
+}
+
+func TestCopyrightHolders(t *testing.T) {
+	t.Parallel()
+	statements := func(texts ...string) []CopyrightStatement {
+		var ret []CopyrightStatement
+		for _, text := range texts {
+			ret = append(ret, ParseCopyrightStatement(PatternMatch{Text: text}))
+		}
+		return ret
+	}
+	results := []IdentifierResults{
+		{File: "b.go", Copyrights: statements("Copyright 2021 Acme Corp.", "Copyright 2020 Jane Doe <jane@example.com>")},
+		{File: "a.go", Copyrights: statements("Copyright 2019-2020 ACME  corp.", "Copyright 2023 acme corp")},
+		{File: "c.go", Copyrights: statements("Copyright 2023")},
+	}
+	want := []CopyrightHolder{
+		{Name: "Acme Corp.", Years: []YearRange{{2019, 2021}, {2023, 2023}}, Files: []string{"a.go", "b.go"}},
+		{Name: "Jane Doe", Years: []YearRange{{2020, 2020}}, Emails: []string{"jane@example.com"}, Files: []string{"b.go"}},
+	}
+	if d := cmp.Diff(want, CopyrightHolders(results...)); d != "" {
+		t.Errorf("Didn't get expected holders: (-want, +got): %v", d)
+	}
+}
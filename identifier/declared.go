@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/CycloneDX/license-scanner/filter"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/manifest"
+)
+
+// declaredLicenses returns the licenses declared by the file if it is a package manifest, or nil.
+// A manifest which cannot be parsed is logged and has no declared licenses.
+func declaredLicenses(licenseLibrary *licenses.LicenseLibrary, file string, content []byte) *manifest.Declaration {
+	m, err := manifest.Parse(file, content)
+	if err != nil {
+		Logger.Debugf("ignoring the declared licenses of %v: %v", file, err)
+		return nil
+	}
+	if m == nil {
+		return nil
+	}
+	return m.Resolve(ManifestIndex(licenseLibrary))
+}
+
+// ManifestIndex returns the index which resolves the licenses declared by manifests (see manifest.LicenseIndex) of the license library,
+// which is built once and again after licenses are added
+func ManifestIndex(licenseLibrary *licenses.LicenseLibrary) *manifest.LicenseIndex {
+	return licenseLibrary.Index(manifestIndexKey, func() interface{} { return manifest.NewLicenseIndex(licenseLibrary.LicenseMap) }).(*manifest.LicenseIndex)
+}
+
+// compareDeclared compares the licenses declared by each manifest with the licenses detected in the license files
+// (see filter.IsLicenseFile) of its directory, and sets the discrepancies of the declaration
+func compareDeclared(results []IdentifierResults) {
+	detected := make(map[string][]manifest.Detected)
+	for _, r := range results {
+		if r.LicenseExpression != nil && filter.IsLicenseFile(r.File) && manifest.TypeOf(r.File) == "" {
+			dir := path.Dir(filepath.ToSlash(r.File))
+			detected[dir] = append(detected[dir], manifest.Detected{File: r.File, Expression: r.LicenseExpression})
+		}
+	}
+	for _, d := range detected {
+		d := d
+		sort.Slice(d, func(i, j int) bool { return d[i].File < d[j].File })
+	}
+	for _, r := range results {
+		if r.Declared != nil {
+			r.Declared.Compare(detected[path.Dir(filepath.ToSlash(r.File))])
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func TestManifestIndex(t *testing.T) {
+	t.Parallel()
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	licenseLibrary.LicenseMap = licenses.LicenseMap{"MIT": {LicenseInfo: licenses.LicenseInfo{Name: "MIT License"}}}
+
+	// the index is built once for every manifest, and again when licenses are added
+	index := ManifestIndex(licenseLibrary)
+	if d := declaredLicenses(licenseLibrary, "package.json", []byte(`{"license": "MIT License"}`)); d == nil || d.Expression == nil || d.Expression.String() != "MIT" {
+		t.Errorf("declaredLicenses() expected MIT got %+v", d)
+	}
+	if got := ManifestIndex(licenseLibrary); got != index {
+		t.Error("ManifestIndex() expected the same index")
+	}
+	licenseLibrary.LicenseMap["Added"] = licenses.License{LicenseInfo: licenses.LicenseInfo{Name: "Added License"}}
+	licenseLibrary.ResetIndexes()
+	if got := ManifestIndex(licenseLibrary); got == index {
+		t.Error("ManifestIndex() expected a new index with the added license")
+	}
+	if d := declaredLicenses(licenseLibrary, "package.json", []byte(`{"license": "Added License"}`)); d == nil || d.Expression == nil || d.Expression.String() != "Added" {
+		t.Errorf("declaredLicenses() expected Added got %+v", d)
+	}
+}
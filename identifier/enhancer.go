@@ -3,8 +3,8 @@
 package identifier
 
 import (
+	"fmt"
 	"regexp"
-	"strings"
 
 	"github.com/CycloneDX/license-scanner/licenses"
 )
@@ -15,7 +15,8 @@ type Enhancements struct {
 	FlagAcceptable bool
 	FlagCopyrights bool
 	FlagKeywords   bool
-	// FlagKeywords   []string  // TODO: JavaScript used this as a bool and later as a list
+	// KeywordRules are the rules of FlagKeywords, or the DefaultKeywordRules when empty
+	KeywordRules []KeywordRule
 }
 
 const AlphaNumericPattern = `/[a-zA-Z0-9]+/`
@@ -51,7 +52,7 @@ func FromOptions(licenseResults *IdentifierResults, enhancements Enhancements, l
 		flagAcceptable(licenseResults, licenseLibrary)
 	}
 	if enhancements.FlagKeywords {
-		if err := flagKeywords(licenseResults); err != nil {
+		if err := flagKeywords(licenseResults, enhancements.KeywordRules); err != nil {
 			return err
 		}
 	}
@@ -70,6 +71,7 @@ func flagCopyrights(licenseResults *IdentifierResults) {
 		return
 	}
 	licenseResults.CopyRightStatements = identifyPatternInBlocks(licenseResults, CopyrightRegexp, "COPYRIGHT")
+	licenseResults.Copyrights = ParseCopyrightStatements(licenseResults.CopyRightStatements)
 }
 
 func flagAcceptable(licenseResults *IdentifierResults, licenseLibrary *licenses.LicenseLibrary) {
@@ -83,17 +85,38 @@ func flagAcceptable(licenseResults *IdentifierResults, licenseLibrary *licenses.
 	}
 }
 
-func flagKeywords(licenseResults *IdentifierResults) error {
+// flagKeywords flags the text which the keywords of each rule match, in the order of the rules
+func flagKeywords(licenseResults *IdentifierResults, rules []KeywordRule) error {
 	if licenseResults == nil {
 		return nil
 	}
-	keywords := DefaultKeywordList
+	if len(rules) == 0 {
+		rules = defaultKeywordRules()
+	}
 
-	re, err := regexp.Compile(`(?i)\b` + strings.Join(keywords, "|"+`\b`))
-	if err != nil {
-		return err
+	var keywordMatches []KeywordMatch
+	for _, rule := range rules {
+		re, err := rule.regexp()
+		if err != nil {
+			return fmt.Errorf("keyword rule %v: %w", rule.Name, err)
+		}
+		if re == nil {
+			continue
+		}
+		severity := rule.Severity
+		if severity == "" {
+			severity = SeverityInfo
+		}
+		for _, m := range identifyPatternInBlocks(licenseResults, re, "KEYWORD") {
+			keywordMatches = append(keywordMatches, KeywordMatch{PatternMatch: m, Rule: rule.Name, Severity: severity})
+		}
+	}
+	sortKeywordMatches(keywordMatches)
+	licenseResults.Keywords = keywordMatches
+	licenseResults.KeywordMatches = nil
+	for _, m := range keywordMatches {
+		licenseResults.KeywordMatches = append(licenseResults.KeywordMatches, m.PatternMatch)
 	}
-	licenseResults.KeywordMatches = identifyPatternInBlocks(licenseResults, re, "KEYWORD")
 
 	return nil
 }
@@ -84,6 +84,7 @@ func TestFromOptions(t *testing.T) {
 			want: &IdentifierResults{
 				Blocks:         []Block{{Text: "This is a "}, {Text: "xxxlicensxxx", Matches: []string{"KEYWORD"}}, {Text: " test"}},
 				KeywordMatches: []PatternMatch{{Text: "xxxlicensxxx", Begins: 10, Ends: 21}},
+				Keywords:       []KeywordMatch{{PatternMatch: PatternMatch{Text: "xxxlicensxxx", Begins: 10, Ends: 21}, Rule: DefaultKeywordRuleName, Severity: SeverityInfo}},
 			},
 			wantErr: false,
 		},
@@ -102,6 +103,7 @@ func TestFromOptions(t *testing.T) {
 			want: &IdentifierResults{
 				Blocks:         []Block{{Text: "This is a "}, {Text: "xxxlicensxxx", Matches: []string{"KEYWORD"}}, {Text: " test"}},
 				KeywordMatches: []PatternMatch{{Text: "xxxlicensxxx", Begins: 10, Ends: 21}},
+				Keywords:       []KeywordMatch{{PatternMatch: PatternMatch{Text: "xxxlicensxxx", Begins: 10, Ends: 21}, Rule: DefaultKeywordRuleName, Severity: SeverityInfo}},
 				Notes:          "A different test note",
 			},
 			wantErr: false,
@@ -146,6 +148,10 @@ func Test_flagCopyrights(t *testing.T) {
 					{Text: "Copyright (c) 2017 James Tanner", Begins: 0, Ends: 30},
 					{Text: "Copyright (c) 2017 IBM", Begins: 32, Ends: 53},
 				},
+				Copyrights: []CopyrightStatement{
+					{PatternMatch: PatternMatch{Text: "Copyright (c) 2017 James Tanner", Begins: 0, Ends: 30}, Holder: "James Tanner", Years: []YearRange{{2017, 2017}}},
+					{PatternMatch: PatternMatch{Text: "Copyright (c) 2017 IBM", Begins: 32, Ends: 53}, Holder: "IBM", Years: []YearRange{{2017, 2017}}},
+				},
 			},
 		},
 		{
@@ -164,6 +170,9 @@ func Test_flagCopyrights(t *testing.T) {
 				CopyRightStatements: []PatternMatch{
 					{Text: "Copyright (c) 2017 James Tanner", Begins: 0, Ends: 30},
 				},
+				Copyrights: []CopyrightStatement{
+					{PatternMatch: PatternMatch{Text: "Copyright (c) 2017 James Tanner", Begins: 0, Ends: 30}, Holder: "James Tanner", Years: []YearRange{{2017, 2017}}},
+				},
 			},
 		},
 		{
@@ -182,6 +191,9 @@ func Test_flagCopyrights(t *testing.T) {
 				CopyRightStatements: []PatternMatch{
 					{Text: "Copyright (c) 2017 James Tanner\nAll Rights Reserved.", Begins: 0, Ends: 51},
 				},
+				Copyrights: []CopyrightStatement{
+					{PatternMatch: PatternMatch{Text: "Copyright (c) 2017 James Tanner\nAll Rights Reserved.", Begins: 0, Ends: 51}, Holder: "James Tanner", Years: []YearRange{{2017, 2017}}, AllRightsReserved: true},
+				},
 			},
 		},
 		{
@@ -201,6 +213,9 @@ func Test_flagCopyrights(t *testing.T) {
 				CopyRightStatements: []PatternMatch{
 					{Text: "Copyright (c) 2017 James Tanner", Begins: 0, Ends: 30},
 				},
+				Copyrights: []CopyrightStatement{
+					{PatternMatch: PatternMatch{Text: "Copyright (c) 2017 James Tanner", Begins: 0, Ends: 30}, Holder: "James Tanner", Years: []YearRange{{2017, 2017}}},
+				},
 			},
 		},
 	}
@@ -306,6 +321,7 @@ func Test_flagKeywords(t *testing.T) {
 			want: &IdentifierResults{
 				Blocks:         []Block{{Text: "This is a "}, {Text: "xxxlicensxxx", Matches: []string{"KEYWORD"}}, {Text: " test"}},
 				KeywordMatches: []PatternMatch{{Text: "xxxlicensxxx", Begins: 10, Ends: 21}},
+				Keywords:       []KeywordMatch{{PatternMatch: PatternMatch{Text: "xxxlicensxxx", Begins: 10, Ends: 21}, Rule: DefaultKeywordRuleName, Severity: SeverityInfo}},
 			},
 			wantErr: false,
 		},
@@ -330,13 +346,61 @@ func Test_flagKeywords(t *testing.T) {
 					{Text: "public domain", Begins: 11, Ends: 23},
 					{Text: "license", Begins: 25, Ends: 31},
 				},
+				Keywords: []KeywordMatch{
+					{PatternMatch: PatternMatch{Text: "public domain", Begins: 11, Ends: 23}, Rule: DefaultKeywordRuleName, Severity: SeverityInfo},
+					{PatternMatch: PatternMatch{Text: "license", Begins: 25, Ends: 31}, Rule: DefaultKeywordRuleName, Severity: SeverityInfo},
+				},
+			},
+		},
+		{
+			name: "should flag the keywords of each rule with its name and severity",
+			args: args{
+				licenseResults: &IdentifierResults{
+					Blocks: []Block{{Text: "Subject to Export Control laws. Licensed under the GPL."}},
+				},
+				enhancements: Enhancements{KeywordRules: []KeywordRule{
+					{Name: "gpl", Severity: SeverityError, Keywords: []string{`gpl\b`}},
+					{Name: "export", Keywords: []string{`export\s+control`}},
+					{Name: DefaultKeywordRuleName},
+				}},
+			},
+			want: &IdentifierResults{
+				Blocks: []Block{
+					{Text: "Subject to "},
+					{Text: "Export Control", Matches: []string{"KEYWORD"}},
+					{Text: " laws. Licensed under the "},
+					{Text: "GPL", Matches: []string{"KEYWORD"}},
+					{Text: "."},
+				},
+				KeywordMatches: []PatternMatch{
+					{Text: "Export Control", Begins: 11, Ends: 24},
+					{Text: "GPL", Begins: 51, Ends: 53},
+				},
+				Keywords: []KeywordMatch{
+					{PatternMatch: PatternMatch{Text: "Export Control", Begins: 11, Ends: 24}, Rule: "export", Severity: SeverityInfo},
+					{PatternMatch: PatternMatch{Text: "GPL", Begins: 51, Ends: 53}, Rule: "gpl", Severity: SeverityError},
+				},
+			},
+		},
+		{
+			name: "each alternative of a keyword should start at a word",
+			args: args{
+				licenseResults: &IdentifierResults{
+					Blocks: []Block{{Text: "crowbar bar"}},
+				},
+				enhancements: Enhancements{KeywordRules: []KeywordRule{{Name: "alternatives", Keywords: []string{`foo|bar`}}}},
+			},
+			want: &IdentifierResults{
+				Blocks:         []Block{{Text: "crowbar "}, {Text: "bar", Matches: []string{"KEYWORD"}}},
+				KeywordMatches: []PatternMatch{{Text: "bar", Begins: 8, Ends: 10}},
+				Keywords:       []KeywordMatch{{PatternMatch: PatternMatch{Text: "bar", Begins: 8, Ends: 10}, Rule: "alternatives", Severity: SeverityInfo}},
 			},
 		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			if err := flagKeywords(tt.args.licenseResults); (err != nil) != tt.wantErr {
+			if err := flagKeywords(tt.args.licenseResults, tt.args.enhancements.KeywordRules); (err != nil) != tt.wantErr {
 				t.Errorf("flagKeywords() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if tt.want == nil {
@@ -3,33 +3,54 @@
 package identifier
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io/fs"
 	"io/ioutil"
+	"math"
 	"os"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/CycloneDX/sbom-utility/log"
 	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/CycloneDX/license-scanner/archive"
+	"github.com/CycloneDX/license-scanner/cache"
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/filter"
 	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/manifest"
 	"github.com/CycloneDX/license-scanner/normalizer"
 )
 
 var (
-	Logger     *log.MiniLogger
-	nonAlphaRE = regexp.MustCompile(`^[^A-Za-z0-9]*$`)
+	Logger     *log.MiniLogger = log.NewLogger(log.DEFAULT_LEVEL)
+	nonAlphaRE                 = regexp.MustCompile(`^[^A-Za-z0-9]*$`)
 )
 
 type Options struct {
 	ForceResult  bool
 	OmitBlocks   bool
 	Enhancements Enhancements
+	// Cache holds results by normalized text hash. It must be specific to the license library (see licenses.LicenseLibrary.Fingerprint).
+	Cache cache.Cache
+	// MinConfidence drops matches with a lower Confidence (0 keeps all matches)
+	MinConfidence float64
+	// MinSimilarity reports the most similar license (see NearMiss) when no primary pattern matches (0 disables it)
+	MinSimilarity float64
+	// ExpressionOrder is the order of the licenses in a composed license expression (see ComposeExpression)
+	ExpressionOrder ExpressionOrder
+	// ArchiveLimits limit the expansion of archives in a directory (see IdentifyLicensesInArchive). A MaxDepth of 0 scans archives as files.
+	ArchiveLimits archive.Limits
+	// Filter selects the files of a directory to scan. With LicenseFilesOnly, it also selects the files of archives.
+	Filter filter.Filter
+	// FileTimeout limits the time to identify the licenses in each text, file, or archive entry (0 for no limit).
+	// A text which times out has a result with Notes instead of matches.
+	FileTimeout time.Duration
 }
 
 type licenseMatch struct {
@@ -37,9 +58,37 @@ type licenseMatch struct {
 	Match     Match
 }
 
+// Match is a license match in the original text (Ends is inclusive)
 type Match struct {
 	Begins int
 	Ends   int
+	// Kind is the kind of license pattern or string which matched
+	Kind MatchKind
+	// Coverage is the fraction (0-1) of the original text covered by the match
+	Coverage float64
+	// Confidence is a score (0-1) for ranking matches, based on the Kind (see kindConfidence)
+	Confidence float64
+}
+
+// MatchKind is the kind of license pattern or string which matched
+type MatchKind string
+
+const (
+	MatchKindPrimary    MatchKind = "primary"
+	MatchKindAssociated MatchKind = "associated"
+	MatchKindURL        MatchKind = "url"
+	MatchKindAlias      MatchKind = "alias"
+	MatchKindTag        MatchKind = "tag"
+)
+
+// kindConfidence is the Confidence of each Kind.
+// A primary pattern matches license text and a tag declares the license, while an alias or URL may only be a reference to the license.
+var kindConfidence = map[MatchKind]float64{
+	MatchKindPrimary:    1.0,
+	MatchKindTag:        0.9,
+	MatchKindAssociated: 0.6,
+	MatchKindURL:        0.5,
+	MatchKindAlias:      0.3,
 }
 
 type PatternMatch struct {
@@ -49,16 +98,29 @@ type PatternMatch struct {
 }
 
 type IdentifierResults struct {
-	Matches                  map[string][]Match
-	Blocks                   []Block
-	File                     string
-	OriginalText             string
-	NormalizedText           string
-	Hash                     normalizer.Digest
-	Notes                    string
+	Matches        map[string][]Match
+	Blocks         []Block
+	File           string
+	OriginalText   string
+	NormalizedText string
+	Hash           normalizer.Digest
+	Status         FileStatus
+	Notes          string
+	NearMiss       *NearMiss
+	LicenseTags    []LicenseTag
+	// InvalidLicenseTags are the SPDX-License-Identifier tags which could not be parsed or validated, so they have no matches
+	InvalidLicenseTags []InvalidLicenseTag
+	// LicenseExpression is the expression composed from the matches (see ComposeExpression), or nil if no licenses were found
+	LicenseExpression        *expression.Expression
 	AcceptablePatternMatches []PatternMatch
 	KeywordMatches           []PatternMatch
-	CopyRightStatements      []PatternMatch
+	// Keywords are the KeywordMatches with the keyword rule which found each of them
+	Keywords            []KeywordMatch
+	CopyRightStatements []PatternMatch
+	// Copyrights are the CopyRightStatements parsed into their holders, years, and emails (see ParseCopyrightStatement)
+	Copyrights []CopyrightStatement
+	// Declared is the license declared by a package manifest (see manifest.Parse), or nil if the file is not one
+	Declared *manifest.Declaration
 }
 
 type Block struct {
@@ -67,9 +129,23 @@ type Block struct {
 }
 
 func Identify(options Options, licenseLibrary *licenses.LicenseLibrary, normalizedData normalizer.NormalizationData) (IdentifierResults, error) {
+	return IdentifyContext(context.Background(), options, licenseLibrary, &normalizedData)
+}
+
+// IdentifyContext is Identify, which stops with the error of the context when it is canceled or its deadline passes
+func IdentifyContext(ctx context.Context, options Options, licenseLibrary *licenses.LicenseLibrary, normalizedData *normalizer.NormalizationData) (IdentifierResults, error) {
+	// return the cached results if the same text was already identified with the same options
+	if options.Cache != nil {
+		if cached, ok := getCachedResults(options, normalizedData); ok {
+			cached.Status = StatusScanned
+			cached.LicenseExpression = ComposeExpression(licenseLibrary.LicenseMap, options.ExpressionOrder, cached)
+			return cached, nil
+		}
+	}
+
 	// find the licenses in the normalized text and return a list of SPDX IDs
 	// in case of an error, return as much as we have along with an error
-	licenseResults, err := findAllLicensesInNormalizedData(licenseLibrary, normalizedData)
+	licenseResults, err := findAllLicensesInNormalizedData(ctx, licenseLibrary, normalizedData, options.MinConfidence)
 	if err != nil {
 		return IdentifierResults{}, err
 	}
@@ -82,14 +158,40 @@ func Identify(options Options, licenseLibrary *licenses.LicenseLibrary, normaliz
 		return IdentifierResults{}, err
 	}
 
+	if err := ctx.Err(); err != nil {
+		return IdentifierResults{}, err
+	}
+
+	// a modified license text does not match any primary pattern, so look for the most similar template
+	if options.MinSimilarity > 0 && !hasKind(licenseResults.Matches, MatchKindPrimary) {
+		licenseResults.NearMiss = findNearMiss(licenseLibrary, normalizedData, options.MinSimilarity)
+	}
+
+	licenseResults.LicenseExpression = ComposeExpression(licenseLibrary.LicenseMap, options.ExpressionOrder, licenseResults)
+	licenseResults.Status = StatusScanned
+
 	if options.OmitBlocks {
 		licenseResults.Blocks = []Block{}
 	}
 
+	if options.Cache != nil {
+		putCachedResults(options, normalizedData, licenseResults)
+	}
+
 	return licenseResults, err
 }
 
 func IdentifyLicensesInString(input string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	return IdentifyLicensesInStringContext(context.Background(), input, options, licenseLibrary)
+}
+
+// IdentifyLicensesInStringContext is IdentifyLicensesInString, which stops with the error of the context when it is canceled or its deadline passes.
+// A binary text, or a text which takes longer than the options.FileTimeout, is not scanned and its result has a Status and Notes instead of matches.
+func IdentifyLicensesInStringContext(ctx context.Context, input string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	if normalizer.ControlCharactersRE.MatchString(input) {
+		return newSkippedResult("", StatusSkippedBinary, "binary file"), nil
+	}
+
 	// instantiate normalizedData with the input license text
 	normalizedData := normalizer.NormalizationData{
 		OriginalText: input,
@@ -100,91 +202,104 @@ func IdentifyLicensesInString(input string, options Options, licenseLibrary *lic
 		return IdentifierResults{}, err
 	}
 
-	return Identify(options, licenseLibrary, normalizedData)
+	fileCtx := ctx
+	if options.FileTimeout > 0 {
+		var cancel context.CancelFunc
+		fileCtx, cancel = context.WithTimeout(ctx, options.FileTimeout)
+		defer cancel()
+	}
+	result, err := IdentifyContext(fileCtx, options, licenseLibrary, &normalizedData)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return newSkippedResult("", StatusError, fmt.Sprintf("timed out (> %v)", options.FileTimeout)), nil
+	}
+	return result, err
 }
 
 func IdentifyLicensesInFile(filePath string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	return IdentifyLicensesInFileContext(context.Background(), filePath, options, licenseLibrary)
+}
+
+// IdentifyLicensesInFileContext is IdentifyLicensesInFile, which stops with the error of the context when it is canceled or its deadline passes.
+// A file which is not scanned has a result with a Status and Notes instead of matches. For an unreadable file or another error, the error is also returned.
+func IdentifyLicensesInFileContext(ctx context.Context, filePath string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
 	fi, err := os.Stat(filePath)
 	if err != nil {
-		return IdentifierResults{}, err
+		return newSkippedResult(filePath, StatusUnreadable, err.Error()), err
 	}
-	if fi.Size() > 1000000 {
-		Logger.Errorf("file too large (%v > 1000000)", fi.Size()) // log error, but return nil
-		return IdentifierResults{}, nil
+	if fi.Size() > maxFileBytes {
+		return newSkippedResult(filePath, StatusSkippedTooLarge, fmt.Sprintf("file too large (%v > %v)", fi.Size(), maxFileBytes)), nil
 	}
 
 	b, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		return IdentifierResults{}, err
+		return newSkippedResult(filePath, StatusUnreadable, err.Error()), err
+	}
+	if len(b) == 0 {
+		return newSkippedResult(filePath, StatusSkippedEmpty, "empty file"), nil
 	}
 	input := string(b)
 
-	result, err := IdentifyLicensesInString(input, options, licenseLibrary)
+	result, err := IdentifyLicensesInStringContext(ctx, input, options, licenseLibrary)
+	if err != nil && ctx.Err() == nil {
+		result = newSkippedResult(filePath, StatusError, err.Error())
+	}
 	result.File = filePath
+	if result.Status == StatusScanned {
+		result.Declared = declaredLicenses(licenseLibrary, filePath, b)
+	}
 	return result, err
 }
 
+// IdentifyLicensesInDirectory identifies the licenses in each file of the directory which the options.Filter selects, in walk order (license files first).
+// Every file has a result with a Status, so an error is only returned when the directory cannot be walked.
 func IdentifyLicensesInDirectory(dirPath string, options Options, licenseLibrary *licenses.LicenseLibrary) (ret []IdentifierResults, err error) {
-	var lfs []string
+	return IdentifyLicensesInDirectoryContext(context.Background(), dirPath, options, licenseLibrary)
+}
 
-	if err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			fmt.Printf("prevent panic by handling failure accessing a path %q: %v\n", path, err)
-			return err
-		}
-		if !d.IsDir() {
-			info, _ := d.Info()
-			if info.Size() > 0 {
-				lfs = append(lfs, path)
-			}
-		}
+// IdentifyLicensesInDirectoryContext is IdentifyLicensesInDirectory, which stops with the error of the context when it is canceled or its deadline passes
+func IdentifyLicensesInDirectoryContext(ctx context.Context, dirPath string, options Options, licenseLibrary *licenses.LicenseLibrary) (ret []IdentifierResults, err error) {
+	var lfs []string
+	if err := options.Filter.Walk(dirPath, Logger.Warningf, func(path string) error {
+		lfs = append(lfs, path)
 		return nil
 	}); err != nil {
-		fmt.Printf("error walking the path %v: %v\n", dirPath, err)
 		return nil, err
 	}
 
 	// errGroup to do the work in parallel until error
-	workers := errgroup.Group{}
+	workers, ctx := errgroup.WithContext(ctx)
 	workers.SetLimit(10)
-	ch := make(chan IdentifierResults, 10)
-
-	// WaitGroup to know when we have all the results
-	waitForResults := sync.WaitGroup{}
-	waitForResults.Add(1)
 
-	// Start receiving the results until channel closes
-	go func() {
-		for ir := range ch {
-			ret = append(ret, ir)
-		}
-		waitForResults.Done()
-	}()
-
-	// Loop using a worker to send results to a channel
-	for _, lf := range lfs {
-		lf := lf
+	// the results of each file are kept by its index, so they are returned in walk order (license files first)
+	results := make([][]IdentifierResults, len(lfs))
+	for i, lf := range lfs {
+		i, lf := i, lf
 		workers.Go(func() error {
-			ir, err := IdentifyLicensesInFile(lf, options, licenseLibrary)
-			if err == nil {
-				ch <- ir
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if options.ArchiveLimits.MaxDepth > 0 && archive.IsArchive(lf) {
+				results[i], _ = IdentifyLicensesInArchiveContext(ctx, lf, options, licenseLibrary)
+				return ctx.Err()
 			}
-			return err
+			// the error of a file is in its result, so only the context stops the scan
+			ir, _ := IdentifyLicensesInFileContext(ctx, lf, options, licenseLibrary)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			results[i] = []IdentifierResults{ir}
+			return nil
 		})
 	}
-
-	// Close the channel when done or error
-	go func() {
-		err = workers.Wait()
-		close(ch)
-	}()
-
-	// Make sure we got all the results
-	waitForResults.Wait()
+	err = workers.Wait()
+	for _, irs := range results {
+		ret = append(ret, irs...)
+	}
+	compareDeclared(ret)
 	return ret, err
 }
 
-func findAllLicensesInNormalizedData(licenseLibrary *licenses.LicenseLibrary, normalizedData normalizer.NormalizationData) (IdentifierResults, error) {
+func findAllLicensesInNormalizedData(ctx context.Context, licenseLibrary *licenses.LicenseLibrary, normalizedData *normalizer.NormalizationData, minConfidence float64) (IdentifierResults, error) {
 	// initialize the result with original license text, normalized license text, and hash (md5, sha256, and sha512)
 	ret := IdentifierResults{
 		OriginalText:   normalizedData.OriginalText,
@@ -197,31 +312,61 @@ func findAllLicensesInNormalizedData(licenseLibrary *licenses.LicenseLibrary, no
 	// List with LicenseID and indexes for generating text blocks
 	var licensesMatched []licenseMatch
 
-	for id, lic := range licenseLibrary.LicenseMap {
-		matches, err := findLicenseInNormalizedData(lic, normalizedData, licenseLibrary)
+	// only the candidate licenses can match, so the others are not searched
+	for _, id := range getCandidateIndex(licenseLibrary).candidates(normalizedData.NormalizedText) {
+		if err := ctx.Err(); err != nil {
+			return ret, err
+		}
+		matches, err := findLicenseInNormalizedData(ctx, licenseLibrary.LicenseMap[id], normalizedData, licenseLibrary)
 		if err != nil {
 			return ret, err
 		}
 
-		// Sort the matches slice by start and end index.
+		// Sort the matches slice by start and end index, with the most confident first.
 		sort.Slice(matches, func(i, j int) bool {
 			if matches[i].Begins != matches[j].Begins {
 				return matches[i].Begins < matches[j].Begins
-			} else {
+			} else if matches[i].Ends != matches[j].Ends {
 				return matches[i].Ends < matches[j].Ends
+			} else {
+				return matches[i].Confidence > matches[j].Confidence
 			}
 		})
 
 		for i := range matches {
-			if i > 0 && matches[i] == matches[i-1] {
+			if i > 0 && sameOffsets(matches[i], matches[i-1]) {
 				continue // remove duplicates
 			}
+			if matches[i].Confidence < minConfidence {
+				continue // remove weak matches
+			}
 			licensesMatched = append(licensesMatched, licenseMatch{LicenseId: id, Match: matches[i]})
 			ret.Matches[id] = append(ret.Matches[id], matches[i])
 		}
 	}
 
-	// Generate Blocks.
+	// Add the licenses in SPDX-License-Identifier tags, by license ID.
+	ret.LicenseTags, ret.InvalidLicenseTags = findLicenseTags(licenseLibrary, normalizedData.OriginalText)
+	for id, matches := range tagMatches(ret.LicenseTags, normalizedData.OriginalText) {
+		for _, m := range matches {
+			if m.Confidence < minConfidence {
+				continue
+			}
+			licensesMatched = append(licensesMatched, licenseMatch{LicenseId: id, Match: m})
+			ret.Matches[id] = append(ret.Matches[id], m)
+		}
+	}
+
+	// Generate Blocks in the order of the matches.
+	sort.SliceStable(licensesMatched, func(i, j int) bool {
+		a, b := licensesMatched[i], licensesMatched[j]
+		if a.Match.Begins != b.Match.Begins {
+			return a.Match.Begins < b.Match.Begins
+		} else if a.Match.Ends != b.Match.Ends {
+			return a.Match.Ends < b.Match.Ends
+		}
+		return a.LicenseId < b.LicenseId
+	})
 	blocks, err := generateTextBlocks(normalizedData.OriginalText, licensesMatched)
 	if err != nil {
 		return ret, err
@@ -231,10 +376,10 @@ func findAllLicensesInNormalizedData(licenseLibrary *licenses.LicenseLibrary, no
 	return ret, nil
 }
 
-func findLicenseInNormalizedData(lic licenses.License, normalizedData normalizer.NormalizationData, ll *licenses.LicenseLibrary) (licenseMatches []Match, err error) {
+func findLicenseInNormalizedData(ctx context.Context, lic licenses.License, normalizedData *normalizer.NormalizationData, ll *licenses.LicenseLibrary) (licenseMatches []Match, err error) {
 	// TODO: If we are not using the match blocks, etc, then do the faster alias checks first.
 	// Get the license pattern matches.
-	licenseMatches, err = findPatterns(lic.PrimaryPatterns, normalizedData, licenseMatches, ll)
+	licenseMatches, err = findPatterns(ctx, lic.PrimaryPatterns, MatchKindPrimary, normalizedData, licenseMatches, ll)
 	if err != nil {
 		return licenseMatches, err
 	}
@@ -255,11 +400,40 @@ func findLicenseInNormalizedData(lic licenses.License, normalizedData normalizer
 	}
 
 	// If there are associated patterns, check those.
-	return findPatterns(lic.AssociatedPatterns, normalizedData, licenseMatches, ll)
+	// They only support the other matches, so they are no more confident than the best of those.
+	found := len(licenseMatches)
+	licenseMatches, err = findPatterns(ctx, lic.AssociatedPatterns, MatchKindAssociated, normalizedData, licenseMatches, ll)
+	best := 0.0
+	for _, m := range licenseMatches[:found] {
+		best = math.Max(best, m.Confidence)
+	}
+	for i := found; i < len(licenseMatches); i++ {
+		licenseMatches[i].Confidence = math.Min(licenseMatches[i].Confidence, best)
+	}
+	return licenseMatches, err
+}
+
+// scoreMatch sets the Kind, Coverage, and Confidence of a match in the original text
+func scoreMatch(m Match, kind MatchKind, originalText string) Match {
+	m.Kind = kind
+	m.Confidence = kindConfidence[kind]
+	if len(originalText) > 0 && m.Ends >= m.Begins {
+		m.Coverage = float64(m.Ends-m.Begins+1) / float64(len(originalText))
+	}
+	return m
+}
+
+// sameOffsets is true when the matches cover the same text (regardless of the kind)
+func sameOffsets(a Match, b Match) bool {
+	return a.Begins == b.Begins && a.Ends == b.Ends
 }
 
 // findAny finds one matching string which meets word boundary conditions (and url conditions)
-func findAny(ss []string, normalized normalizer.NormalizationData, isURL bool, licenseMatches []Match) []Match {
+func findAny(ss []string, normalized *normalizer.NormalizationData, isURL bool, licenseMatches []Match) []Match {
+	kind := MatchKindAlias
+	if isURL {
+		kind = MatchKindURL
+	}
 	for _, s := range ss {
 		next := 0
 		for i := strings.Index(normalized.NormalizedText, s); i > -1; i = strings.Index(normalized.NormalizedText[next:], s) {
@@ -268,14 +442,14 @@ func findAny(ss []string, normalized normalizer.NormalizationData, isURL bool, l
 
 			begin, end, found := findBoundaries(i, s, normalized, isURL)
 			if found {
-				return appendIndexMappedMatch(begin, end, normalized, licenseMatches)
+				return appendIndexMappedMatch(begin, end, kind, normalized, licenseMatches)
 			}
 		}
 	}
 	return licenseMatches
 }
 
-func findBoundaries(start int, s string, nd normalizer.NormalizationData, isURL bool) (begin int, end int, ok bool) {
+func findBoundaries(start int, s string, nd *normalizer.NormalizationData, isURL bool) (begin int, end int, ok bool) {
 	begin, ok = findBeginBoundary(start, nd, isURL)
 	if !ok {
 		return -1, -1, false
@@ -289,7 +463,7 @@ func findBoundaries(start int, s string, nd normalizer.NormalizationData, isURL
 	return begin, end, true
 }
 
-func findBeginBoundary(start int, nd normalizer.NormalizationData, isURL bool) (begin int, ok bool) {
+func findBeginBoundary(start int, nd *normalizer.NormalizationData, isURL bool) (begin int, ok bool) {
 	// Starting at position zero is always an ok boundary
 	if start == 0 {
 		return 0, true
@@ -328,7 +502,7 @@ func findBeginBoundary(start int, nd normalizer.NormalizationData, isURL bool) (
 	return begin, true // Space-paren word boundary
 }
 
-func findEndBoundary(start int, s string, nd normalizer.NormalizationData, isURL bool) (end int, ok bool) {
+func findEndBoundary(start int, s string, nd *normalizer.NormalizationData, isURL bool) (end int, ok bool) {
 	end = start + len(s)
 	max := len(nd.NormalizedText)
 
@@ -372,7 +546,7 @@ func findEndBoundary(start int, s string, nd normalizer.NormalizationData, isURL
 	return end, true // found an ok boundary
 }
 
-func includeURLPrefix(begin int, nd normalizer.NormalizationData) int {
+func includeURLPrefix(begin int, nd *normalizer.NormalizationData) int {
 	wwwDot := "www."
 	length := len(wwwDot)
 	if begin >= length && wwwDot == nd.NormalizedText[begin-length:begin] {
@@ -389,74 +563,78 @@ func includeURLPrefix(begin int, nd normalizer.NormalizationData) int {
 	return begin
 }
 
-func appendIndexMappedMatch(begin int, end int, normalizedData normalizer.NormalizationData, licenseMatches []Match) []Match {
+func appendIndexMappedMatch(begin int, end int, kind MatchKind, normalizedData *normalizer.NormalizationData, licenseMatches []Match) []Match {
 	indexMapLen := len(normalizedData.IndexMap)
+	m := Match{Begins: normalizedData.IndexMap[begin]}
 	if end < indexMapLen {
-		return append(licenseMatches, Match{Begins: normalizedData.IndexMap[begin], Ends: normalizedData.IndexMap[end]})
+		m.Ends = normalizedData.IndexMap[end]
 	} else {
 		// End of map is out of range, so use the last index in the map
-		return append(licenseMatches, Match{Begins: normalizedData.IndexMap[begin], Ends: normalizedData.IndexMap[indexMapLen-1]})
+		m.Ends = normalizedData.IndexMap[indexMapLen-1]
 	}
+	return append(licenseMatches, scoreMatch(m, kind, normalizedData.OriginalText))
 }
 
-func findAnyAlias(urls []string, normalized normalizer.NormalizationData, licenseMatches []Match) []Match {
+func findAnyAlias(urls []string, normalized *normalizer.NormalizationData, licenseMatches []Match) []Match {
 	return findAny(urls, normalized, false, licenseMatches)
 }
 
-func findAnyURL(urls []string, normalized normalizer.NormalizationData, licenseMatches []Match) []Match {
+func findAnyURL(urls []string, normalized *normalizer.NormalizationData, licenseMatches []Match) []Match {
 	return findAny(urls, normalized, true, licenseMatches)
 }
 
-func findPatterns(patterns []*licenses.PrimaryPatterns, normalizedData normalizer.NormalizationData, licenseMatches []Match, ll *licenses.LicenseLibrary) ([]Match, error) {
-	// errGroup to do the work in parallel until error
-	workers := errgroup.Group{}
+// findPatterns returns the matches of the patterns in parallel. It returns the error of the context as soon as it is done,
+// without waiting for the regular expressions which are running, which finish in the background and whose matches are dropped.
+// A regular expression cannot be interrupted, so the context limits the wait and not the CPU, but no other pattern is started.
+func findPatterns(ctx context.Context, patterns []*licenses.PrimaryPatterns, kind MatchKind, normalizedData *normalizer.NormalizationData, licenseMatches []Match, ll *licenses.LicenseLibrary) ([]Match, error) {
+	// errGroup to do the work in parallel until error or cancellation
+	workers, workersCtx := errgroup.WithContext(ctx)
 	workers.SetLimit(10)
-	ch := make(chan []Match, 10)
-
-	// WaitGroup to know when we have all the results
-	waitForResults := sync.WaitGroup{}
-	waitForResults.Add(1)
+	// the channel holds every result, so that a worker never blocks after findPatterns returned
+	ch := make(chan []Match, len(patterns))
+	done := make(chan error, 1)
 
-	// Start receiving the results until channel closes
+	// Start the workers, which may block while 10 are running, and send the error of the group when they are all done
 	go func() {
-		for patternMatches := range ch {
-			if len(patternMatches) > 0 {
-				licenseMatches = append(licenseMatches, patternMatches...)
+		for _, pattern := range patterns {
+			if workersCtx.Err() != nil {
+				break
+			}
+			ppk := licenses.LicensePatternKey{
+				FilePath: pattern.FileName,
+			}
+			preChecksRequired := ll.PrimaryPatternPreCheckMap[ppk]
+			if preChecksRequired != nil && !PassedStaticBlocksChecks(preChecksRequired.StaticBlocks, *normalizedData) {
+				continue
 			}
+			p := pattern
+			nD := normalizedData
+			workers.Go(func() error {
+				if err := workersCtx.Err(); err != nil {
+					return err
+				}
+				patternMatches, err := FindMatchingPatternInNormalizedData(p, *nD)
+				if err == nil {
+					ch <- patternMatches
+				}
+				return err
+			})
 		}
-		waitForResults.Done()
+		done <- workers.Wait()
+		close(ch)
 	}()
 
-	// Loop with the slow part using a worker to send results to a channel
-	for _, pattern := range patterns {
-		ppk := licenses.LicensePatternKey{
-			FilePath: pattern.FileName,
-		}
-		preChecksRequired := ll.PrimaryPatternPreCheckMap[ppk]
-		if preChecksRequired != nil && !PassedStaticBlocksChecks(preChecksRequired.StaticBlocks, normalizedData) {
-			continue
-		}
-		p := pattern
-		nD := normalizedData
-		workers.Go(func() error {
-			patternMatches, err := FindMatchingPatternInNormalizedData(p, nD)
-			if err == nil {
-				ch <- patternMatches
+	select {
+	case <-ctx.Done():
+		return licenseMatches, ctx.Err()
+	case err := <-done:
+		for patternMatches := range ch {
+			for _, m := range patternMatches {
+				licenseMatches = append(licenseMatches, scoreMatch(m, kind, normalizedData.OriginalText))
 			}
-			return err
-		})
+		}
+		return licenseMatches, err
 	}
-
-	// Close the channel when done or error
-	var err error
-	go func() {
-		err = workers.Wait()
-		close(ch)
-	}()
-
-	// Make sure we got all the results
-	waitForResults.Wait()
-	return licenseMatches, err
 }
 
 func FindMatchingPatternInNormalizedData(matchingPattern *licenses.PrimaryPatterns, normalized normalizer.NormalizationData) (results []Match, err error) {
@@ -589,7 +767,10 @@ func applyMutatorLicenses(allLicenses licenses.LicenseMap, licenseResults *Ident
 
 		// Collate all the matches into licenses and mutators.
 		for _, m := range b.Matches {
-			lic := allLicenses[m]
+			lic, ok := allLicenses[m]
+			if !ok {
+				continue // e.g. a tag with an exception or LicenseRef-
+			}
 			if lic.LicenseInfo.IsMutator {
 				// If the match is a mutator, add it to the mutators.
 				if !containsLicID(currentMutators, m) {
@@ -801,13 +982,41 @@ func recalculateMatchesFromBlocks(licenseResults IdentifierResults) map[string][
 			case "", "COPYRIGHT", "KEYWORD", "ACCEPTABLE":
 				continue
 			default:
-				newMatches[licenseId] = append(newMatches[licenseId], Match{Begins: begins, Ends: ends})
+				best := bestMatch(licenseResults.Matches, begins, ends)
+				m := scoreMatch(Match{Begins: begins, Ends: ends}, best.Kind, licenseResults.OriginalText)
+				m.Confidence = best.Confidence
+				newMatches[licenseId] = append(newMatches[licenseId], m)
 			}
 		}
 	}
 	return newMatches
 }
 
+// hasKind is true if any of the matches is of the kind
+func hasKind(matches map[string][]Match, kind MatchKind) bool {
+	for _, ms := range matches {
+		for _, m := range ms {
+			if m.Kind == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bestMatch returns the most confident of the matches which overlap the offsets
+func bestMatch(matches map[string][]Match, begins int, ends int) Match {
+	var best Match
+	for _, ms := range matches {
+		for _, m := range ms {
+			if m.Begins <= ends && m.Ends >= begins && m.Confidence > best.Confidence {
+				best = m
+			}
+		}
+	}
+	return best
+}
+
 func containsLicID(lics []licenses.License, id string) bool {
 	if id == "" {
 		return false
@@ -16,11 +16,11 @@ import (
 )
 
 const (
-	resources = "../resources"
-	spdx      = "default"
+	resourcesDir = "../resources"
+	spdx         = "default"
 )
 
-var testDataDir = path.Join(resources, "spdx", spdx, "testdata")
+var testDataDir = path.Join(resourcesDir, "spdx", spdx, "testdata")
 var options = Options{
 	ForceResult: false,
 	Enhancements: Enhancements{
@@ -103,7 +103,7 @@ func Test_identifyLicensesInSPDXTestDataFiles(t *testing.T) {
 	})
 
 	if err != nil {
-		fmt.Printf("error walking the path %v: %v\n", resources, err)
+		fmt.Printf("error walking the path %v: %v\n", resourcesDir, err)
 		return
 	}
 
@@ -5,11 +5,19 @@
 package identifier
 
 import (
+	"context"
 	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/CycloneDX/license-scanner/configurer"
 	"github.com/CycloneDX/license-scanner/licenses"
@@ -514,7 +522,7 @@ func Test_identifyLicensesInString(t *testing.T) {
 			got, err := IdentifyLicensesInString(tt.args.input, options, licenseLibrary)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("identifyLicensesInString() error = %v, wantErr %v", err, tt.wantErr)
-			} else if d := cmp.Diff(tt.want.Matches, got.Matches, cmp.AllowUnexported(Match{})); d != "" {
+			} else if d := cmp.Diff(tt.want.Matches, got.Matches, cmpopts.IgnoreFields(Match{}, "Kind", "Coverage", "Confidence")); d != "" {
 				t.Errorf("Didn't get expected result: (-want, +got): %v", d)
 			} else if d := cmp.Diff(tt.want.CopyRightStatements, got.CopyRightStatements); d != "" {
 				t.Errorf("Didn't get expected result: (-want, +got): %v", d)
@@ -607,7 +615,7 @@ func Test_identifyLicensesInStringPreChecks(t *testing.T) {
 			got, err := IdentifyLicensesInString(tt.input, options, ll)
 			if err != nil {
 				t.Errorf("identifyLicensesInString() error = %v", err)
-			} else if d := cmp.Diff(tt.want.Matches, got.Matches, cmp.AllowUnexported(Match{})); d != "" {
+			} else if d := cmp.Diff(tt.want.Matches, got.Matches, cmpopts.IgnoreFields(Match{}, "Kind", "Coverage", "Confidence")); d != "" {
 				t.Errorf("Didn't get expected result: (-want, +got): %v", d)
 			} else if d := cmp.Diff(tt.want.Blocks, got.Blocks); d != "" {
 				t.Errorf("Didn't get expected result: (-want, +got): %v", d)
@@ -794,3 +802,239 @@ func Test_mutatorsAreCompatible(t *testing.T) {
 		})
 	}
 }
+
+func Test_identifyLicensesInStringMatchKinds(t *testing.T) {
+	alias := "This is under the Apache License Version 2.0 so there."
+	url := "See http://www.apache.org/licenses/LICENSE-2.0 for apache terms."
+	primary := "whatever noprechecktext whatever passes"
+	tests := []struct {
+		name          string
+		configPath    string
+		input         string
+		minConfidence float64
+		want          map[string][]Match
+	}{
+		{
+			name:       "primary pattern",
+			configPath: "../testdata/duplicates/",
+			input:      primary,
+			want:       map[string][]Match{"DuplicateMatchTest": {{Begins: 9, Ends: 22, Kind: MatchKindPrimary, Coverage: 14.0 / float64(len(primary)), Confidence: 1}}},
+		},
+		{
+			name:       "alias",
+			configPath: "../testdata/config/",
+			input:      alias,
+			want:       map[string][]Match{"Apache-2.0": {{Begins: 17, Ends: 44, Kind: MatchKindAlias, Coverage: 28.0 / float64(len(alias)), Confidence: 0.3}}},
+		},
+		{
+			name:       "url",
+			configPath: "../testdata/config/",
+			input:      url,
+			want:       map[string][]Match{"Apache-2.0": {{Begins: 3, Ends: 46, Kind: MatchKindURL, Coverage: 44.0 / float64(len(url)), Confidence: 0.5}}},
+		},
+		{
+			name:          "alias below minConfidence",
+			configPath:    "../testdata/config/",
+			input:         alias,
+			minConfidence: 0.5,
+			want:          map[string][]Match{},
+		},
+		{
+			name:          "url at minConfidence",
+			configPath:    "../testdata/config/",
+			input:         url,
+			minConfidence: 0.5,
+			want:          map[string][]Match{"Apache-2.0": {{Begins: 3, Ends: 46, Kind: MatchKindURL, Coverage: 44.0 / float64(len(url)), Confidence: 0.5}}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			flagSet := configurer.NewDefaultFlags()
+			_ = flagSet.Set(configurer.ConfigPathFlag, tt.configPath)
+			config, err := configurer.InitConfig(flagSet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ll, err := licenses.NewLicenseLibrary(config)
+			if err != nil {
+				t.Fatalf("NewLicenseLibrary(config) error = %v", err)
+			}
+			if err := ll.AddAll(); err != nil {
+				t.Fatalf("AddAll() error = %v", err)
+			}
+
+			options := defaultOptions()
+			options.MinConfidence = tt.minConfidence
+			got, err := IdentifyLicensesInString(tt.input, options, ll)
+			if err != nil {
+				t.Errorf("IdentifyLicensesInString() error = %v", err)
+			} else if d := cmp.Diff(tt.want, got.Matches); d != "" {
+				t.Errorf("Didn't get expected result: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestIdentifyContext(t *testing.T) {
+	flagSet := configurer.NewDefaultFlags()
+	_ = flagSet.Set(configurer.ConfigPathFlag, "../testdata/config")
+	config, err := configurer.InitConfig(flagSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll, err := licenses.NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary(config) error = %v", err)
+	}
+	if err := ll.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	const text = "Licensed under the Apache License, Version 2.0"
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := IdentifyLicensesInStringContext(canceled, text, defaultOptions(), ll); !errors.Is(err, context.Canceled) {
+		t.Errorf("IdentifyLicensesInStringContext() with a canceled context error = %v want %v", err, context.Canceled)
+	}
+	if _, err := IdentifyLicensesInDirectoryContext(canceled, "../testdata/addAll/input/text", defaultOptions(), ll); !errors.Is(err, context.Canceled) {
+		t.Errorf("IdentifyLicensesInDirectoryContext() with a canceled context error = %v want %v", err, context.Canceled)
+	}
+
+	// a file which times out has a note, and the scan goes on
+	options := defaultOptions()
+	options.FileTimeout = time.Nanosecond
+	got, err := IdentifyLicensesInStringContext(context.Background(), text, options, ll)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInStringContext() error = %v", err)
+	}
+	if len(got.Matches) != 0 || !strings.Contains(got.Notes, "timed out") {
+		t.Errorf("IdentifyLicensesInStringContext() with a file timeout = %v matches and notes %q", len(got.Matches), got.Notes)
+	}
+	results, err := IdentifyLicensesInDirectoryContext(context.Background(), "../testdata/addAll/input/text", options, ll)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInDirectoryContext() error = %v", err)
+	}
+	if len(results) == 0 {
+		t.Error("IdentifyLicensesInDirectoryContext() with a file timeout expected results")
+	}
+	for _, r := range results {
+		if r.File == "" || !strings.Contains(r.Notes, "timed out") {
+			t.Errorf("IdentifyLicensesInDirectoryContext() with a file timeout = %q with notes %q", r.File, r.Notes)
+		}
+	}
+}
+
+func Test_findPatterns_timeout(t *testing.T) {
+	t.Parallel()
+	// the pattern does not match, and takes a few seconds to search the text
+	normalizedData := normalizer.NormalizationData{NormalizedText: strings.Repeat("alpha beta gamma ", 250000)}
+	patterns := []*licenses.PrimaryPatterns{{Text: "alpha <<match=.*>> zulu"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := findPatterns(ctx, patterns, MatchKindPrimary, &normalizedData, nil, &licenses.LicenseLibrary{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("findPatterns() error = %v want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("findPatterns() returned %v after the deadline, expected it not to wait for the running patterns", elapsed)
+	}
+}
+
+func TestIdentifyLicensesInDirectory_Status(t *testing.T) {
+	flagSet := configurer.NewDefaultFlags()
+	_ = flagSet.Set(configurer.ConfigPathFlag, "../testdata/config")
+	config, err := configurer.InitConfig(flagSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll, err := licenses.NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary(config) error = %v", err)
+	}
+	if err := ll.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"LICENSE":   "Licensed under the Apache License, Version 2.0",
+		"image.png": "\x89PNG\x00\x00",
+		"big.txt":   strings.Repeat("x", maxFileBytes+1),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := IdentifyLicensesInDirectory(dir, defaultOptions(), ll)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInDirectory() error = %v", err)
+	}
+	got := make(map[string]FileStatus)
+	for _, r := range results {
+		got[filepath.Base(r.File)] = r.Status
+		if r.Status.Skipped() && (r.Notes == "" || len(r.Matches) != 0) {
+			t.Errorf("expected notes and no matches for skipped %v got %q and %v", r.File, r.Notes, r.Matches)
+		}
+	}
+	want := map[string]FileStatus{"LICENSE": StatusScanned, "image.png": StatusSkippedBinary, "big.txt": StatusSkippedTooLarge}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("IdentifyLicensesInDirectory() statuses Diff(-want +got) = %v", d)
+	}
+
+	missing := filepath.Join(dir, "missing")
+	if r, err := IdentifyLicensesInFile(missing, defaultOptions(), ll); err == nil || r.Status != StatusUnreadable || r.File != missing {
+		t.Errorf("IdentifyLicensesInFile() of a missing file = %v, %v want %v", r.Status, err, StatusUnreadable)
+	}
+}
+
+func TestIdentifyLicensesInDirectory_Order(t *testing.T) {
+	flagSet := configurer.NewDefaultFlags()
+	_ = flagSet.Set(configurer.ConfigPathFlag, "../testdata/config")
+	config, err := configurer.InitConfig(flagSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll, err := licenses.NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary(config) error = %v", err)
+	}
+	if err := ll.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	dir := t.TempDir()
+	for i := 0; i < 50; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), []byte(strings.Repeat("some text ", 10*(50-i))), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"LICENSE", "package.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("Licensed under the Apache License, Version 2.0"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	options := defaultOptions()
+	var want []string
+	if err := options.Filter.Walk(dir, t.Logf, func(p string) error {
+		want = append(want, p)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	results, err := IdentifyLicensesInDirectory(dir, options, ll)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInDirectory() error = %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.File)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("IdentifyLicensesInDirectory() files are not in walk order: Diff(-want +got) = %v", d)
+	}
+	if len(got) < 2 || filepath.Base(got[0]) != "LICENSE" || filepath.Base(got[1]) != "package.json" {
+		t.Errorf("IdentifyLicensesInDirectory() expected the license files first got %v", got)
+	}
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// The severities of keyword rules
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// DefaultKeywordRuleName is the name of the rule of the DefaultKeywordList
+const DefaultKeywordRuleName = "default"
+
+// Severities lists the severities of keyword rules, from the least to the most severe
+var Severities = []string{SeverityInfo, SeverityWarning, SeverityError}
+
+// DefaultKeywordRules are the keyword rules used when no others are configured
+var DefaultKeywordRules = []KeywordRule{{Name: DefaultKeywordRuleName, Severity: SeverityInfo, Keywords: DefaultKeywordList}}
+
+// KeywordRule is a named set of keywords, which flags the text that they match with the severity of the rule
+type KeywordRule struct {
+	Name string `yaml:"name" json:"name"`
+	// Severity is info (when empty), warning, or error
+	Severity string `yaml:"severity" json:"severity"`
+	// Keywords are regular expressions which match without case at the start of a word (e.g. `export\s+control`).
+	// A rule without keywords flags nothing, which disables a rule with the same name.
+	Keywords []string `yaml:"keywords" json:"keywords"`
+	// Regexp is the expression of the Keywords (see CompileKeywordRules), which is compiled for each text when it is nil
+	Regexp *regexp.Regexp `yaml:"-" json:"-" mapstructure:"-"`
+}
+
+// KeywordRules is a file of keyword rules
+type KeywordRules struct {
+	Rules []KeywordRule `yaml:"rules" json:"rules"`
+}
+
+// KeywordMatch is a keyword found by a keyword rule
+type KeywordMatch struct {
+	PatternMatch
+	Rule     string
+	Severity string
+}
+
+// ReadKeywordRules reads and validates keyword rules in YAML (or JSON) format. Unknown fields are errors.
+func ReadKeywordRules(r io.Reader) ([]KeywordRule, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var k KeywordRules
+	if err := dec.Decode(&k); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := ValidateKeywordRules(k.Rules); err != nil {
+		return nil, err
+	}
+	return k.Rules, nil
+}
+
+// ValidateKeywordRules returns an error for a rule without a name, an unknown severity, or an invalid keyword
+func ValidateKeywordRules(rules []KeywordRule) error {
+	for i, r := range rules {
+		where := fmt.Sprintf("rules[%v]", i)
+		if r.Name == "" {
+			return fmt.Errorf("%v: no name", where)
+		}
+		if r.Severity != "" && !slices.Contains(Severities, r.Severity) {
+			return fmt.Errorf("%v: invalid severity %q (expected one of: %v)", where, r.Severity, strings.Join(Severities, ", "))
+		}
+		if _, err := r.regexp(); err != nil {
+			return fmt.Errorf("%v: invalid keywords: %w", where, err)
+		}
+	}
+	return nil
+}
+
+// MergeKeywordRules returns the rules of each list in order, where a rule replaces an earlier rule with the same name
+func MergeKeywordRules(lists ...[]KeywordRule) []KeywordRule {
+	var ret []KeywordRule
+	for _, rules := range lists {
+		for _, r := range rules {
+			if i := slices.IndexFunc(ret, func(k KeywordRule) bool { return k.Name == r.Name }); i >= 0 {
+				ret[i] = r
+			} else {
+				ret = append(ret, r)
+			}
+		}
+	}
+	return ret
+}
+
+// CompileKeywordRules returns a copy of the rules with the Regexp of each rule, so the keywords are compiled once for every text
+func CompileKeywordRules(rules []KeywordRule) ([]KeywordRule, error) {
+	ret := make([]KeywordRule, len(rules))
+	for i, r := range rules {
+		re, err := r.regexp()
+		if err != nil {
+			return nil, fmt.Errorf("keyword rule %v: %w", r.Name, err)
+		}
+		r.Regexp = re
+		ret[i] = r
+	}
+	return ret, nil
+}
+
+// regexp is the expression of the keywords of a rule, or nil if it has none.
+// Each keyword is a group, so the alternatives of a keyword (e.g. `foo|bar`) all start at a word.
+func (r KeywordRule) regexp() (*regexp.Regexp, error) {
+	if r.Regexp != nil {
+		return r.Regexp, nil
+	}
+	if len(r.Keywords) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile(`(?i)\b(?:(?:` + strings.Join(r.Keywords, `)|(?:`) + `))`)
+}
+
+var (
+	compiledDefaultKeywordRules []KeywordRule
+	compileDefaultKeywordRules  sync.Once
+)
+
+// defaultKeywordRules returns the DefaultKeywordRules, compiled once (see CompileKeywordRules)
+func defaultKeywordRules() []KeywordRule {
+	compileDefaultKeywordRules.Do(func() {
+		var err error
+		if compiledDefaultKeywordRules, err = CompileKeywordRules(DefaultKeywordRules); err != nil {
+			compiledDefaultKeywordRules = DefaultKeywordRules
+		}
+	})
+	return compiledDefaultKeywordRules
+}
+
+// keywordRulesKey identifies the keyword rules in cache keys
+func keywordRulesKey(rules []KeywordRule) string {
+	b, _ := json.Marshal(rules)
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:16]
+}
+
+// sortKeywordMatches sorts the matches of all the rules by offset
+func sortKeywordMatches(matches []KeywordMatch) {
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Begins < matches[j].Begins })
+}
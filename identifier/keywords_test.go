@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadKeywordRules(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected []KeywordRule
+		err      string
+	}{
+		{
+			name:     "yaml",
+			input:    "rules:\n  - name: export\n    severity: warning\n    keywords: ['export\\s+control']\n",
+			expected: []KeywordRule{{Name: "export", Severity: SeverityWarning, Keywords: []string{`export\s+control`}}},
+		},
+		{
+			name:     "json",
+			input:    `{"rules": [{"name": "default", "keywords": []}]}`,
+			expected: []KeywordRule{{Name: DefaultKeywordRuleName, Keywords: []string{}}},
+		},
+		{name: "empty", input: ""},
+		{name: "unknown field", input: "rules:\n  - name: a\n    keyword: [b]\n", err: "field keyword not found"},
+		{name: "no name", input: "rules:\n  - keywords: [b]\n", err: "rules[0]: no name"},
+		{name: "invalid severity", input: "rules:\n  - name: a\n    severity: fatal\n", err: `rules[0]: invalid severity "fatal"`},
+		{name: "invalid keyword", input: "rules:\n  - name: a\n    keywords: ['(']\n", err: "rules[0]: invalid keywords"},
+	}
+	for _, tt := range tests {
+		got, err := ReadKeywordRules(strings.NewReader(tt.input))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%v: ReadKeywordRules() expected error %q got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: ReadKeywordRules() error = %v", tt.name, err)
+		}
+		if d := cmp.Diff(tt.expected, got); d != "" {
+			t.Errorf("%v: ReadKeywordRules() didn't get expected rules (-want, +got): %v", tt.name, d)
+		}
+	}
+}
+
+func TestMergeKeywordRules(t *testing.T) {
+	t.Parallel()
+	got := MergeKeywordRules(
+		DefaultKeywordRules,
+		[]KeywordRule{{Name: "export", Keywords: []string{"export"}}, {Name: "gpl", Keywords: []string{"gpl"}}},
+		[]KeywordRule{{Name: DefaultKeywordRuleName}, {Name: "export", Severity: SeverityError, Keywords: []string{"export"}}},
+	)
+	expected := []KeywordRule{
+		{Name: DefaultKeywordRuleName},
+		{Name: "export", Severity: SeverityError, Keywords: []string{"export"}},
+		{Name: "gpl", Keywords: []string{"gpl"}},
+	}
+	if d := cmp.Diff(expected, got); d != "" {
+		t.Errorf("MergeKeywordRules() didn't get expected rules (-want, +got): %v", d)
+	}
+}
+
+func TestCompileKeywordRules(t *testing.T) {
+	t.Parallel()
+	rules := []KeywordRule{{Name: "export", Keywords: []string{`export\s+control`, `ear|itar`}}, {Name: DefaultKeywordRuleName}}
+	got, err := CompileKeywordRules(rules)
+	if err != nil {
+		t.Fatalf("CompileKeywordRules() error = %v", err)
+	}
+	if got[0].Regexp == nil || got[1].Regexp != nil {
+		t.Errorf("CompileKeywordRules() expected a Regexp for the rule with keywords only got %v and %v", got[0].Regexp, got[1].Regexp)
+	}
+	if rules[0].Regexp != nil {
+		t.Error("CompileKeywordRules() changed the rules")
+	}
+	if matches := got[0].Regexp.FindAllString("clear guitar itar", -1); len(matches) != 1 || matches[0] != "itar" {
+		t.Errorf("CompileKeywordRules() Regexp expected to match itar only got %v", matches)
+	}
+	if _, err := CompileKeywordRules([]KeywordRule{{Name: "invalid", Keywords: []string{"("}}}); err == nil || !strings.Contains(err.Error(), "keyword rule invalid") {
+		t.Errorf("CompileKeywordRules() error = %v want keyword rule invalid", err)
+	}
+}
@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/normalizer"
+)
+
+const (
+	// minTemplateTokens skips short templates, which are too similar to any short text
+	minTemplateTokens = 20
+	// maxDiffCells limits the size of the token diff (template tokens * text tokens)
+	maxDiffCells = 4000000
+)
+
+var tokenRE = regexp.MustCompile(`[a-z0-9]+`)
+
+// NearMiss is the license template which is most similar to a text which did not match any primary pattern
+type NearMiss struct {
+	LicenseId string
+	// Similarity is the Sørensen–Dice coefficient (0-1) of the word pairs in the text and the template
+	Similarity float64
+	// Differences are the parts of the text which differ from the template
+	Differences []Difference
+}
+
+// Difference is text which was added to or removed from a template.
+// Begins and Ends (inclusive) are the offsets of the added text in the original text, or -1 when text was only removed.
+// Expected is the template text which was removed or replaced, if any.
+type Difference struct {
+	Begins   int
+	Ends     int
+	Expected string
+}
+
+// token is a word of the normalized text with its offsets in the normalized text
+type token struct {
+	text   string
+	begins int
+	ends   int
+}
+
+// templateTokens holds the words of the static blocks of a template, with the index of the block of each word, and the word pairs
+type templateTokens struct {
+	licenseId string
+	tokens    []string
+	blocks    []int
+	pairs     map[string]int
+	numPairs  int
+}
+
+// similarityIndex holds the templateTokens of each primary pattern with prechecks
+type similarityIndex struct {
+	templates []templateTokens
+}
+
+// getSimilarityIndex returns the similarityIndex of the license library, which is built again after licenses are added
+func getSimilarityIndex(licenseLibrary *licenses.LicenseLibrary) *similarityIndex {
+	return licenseLibrary.Index(similarityIndexKey, func() interface{} { return newSimilarityIndex(licenseLibrary) }).(*similarityIndex)
+}
+
+func newSimilarityIndex(licenseLibrary *licenses.LicenseLibrary) *similarityIndex {
+	index := &similarityIndex{}
+	for id, lic := range licenseLibrary.LicenseMap {
+		for _, pattern := range lic.PrimaryPatterns {
+			preChecks := licenseLibrary.PrimaryPatternPreCheckMap[licenses.LicensePatternKey{FilePath: pattern.FileName}]
+			if preChecks == nil {
+				continue
+			}
+			if t := newTemplateTokens(id, preChecks.StaticBlocks); len(t.tokens) >= minTemplateTokens {
+				index.templates = append(index.templates, t)
+			}
+		}
+	}
+	return index
+}
+
+// findNearMiss returns the license template which is most similar to the text, or nil if none has at least minSimilarity.
+// Templates are the static blocks of the primary patterns with prechecks.
+func findNearMiss(licenseLibrary *licenses.LicenseLibrary, normalizedData *normalizer.NormalizationData, minSimilarity float64) *NearMiss {
+	textTokens := tokenize(normalizedData.NormalizedText)
+	texts := make([]string, len(textTokens))
+	for i, t := range textTokens {
+		texts[i] = t.text
+	}
+	textPairs := wordPairs(texts, nil)
+	numTextPairs := len(textTokens) - 1
+
+	var best *NearMiss
+	var bestTemplate templateTokens
+	for _, template := range getSimilarityIndex(licenseLibrary).templates {
+		// the similarity is at most 2*min(a,b)/(a+b), so skip templates which are too short or too long
+		a, b := float64(template.numPairs), float64(numTextPairs)
+		if b <= 0 || 2*math.Min(a, b)/(a+b) < minSimilarity {
+			continue
+		}
+
+		similarity := dice(template.pairs, template.numPairs, textPairs, numTextPairs)
+		if similarity < minSimilarity {
+			continue
+		}
+		if best == nil || similarity > best.Similarity || (similarity == best.Similarity && template.licenseId < best.LicenseId) {
+			best = &NearMiss{LicenseId: template.licenseId, Similarity: similarity}
+			bestTemplate = template
+		}
+	}
+
+	if best != nil {
+		best.Differences = diffTokens(bestTemplate, textTokens, normalizedData)
+	}
+	return best
+}
+
+func newTemplateTokens(id string, staticBlocks []string) templateTokens {
+	t := templateTokens{licenseId: id}
+	for i, b := range staticBlocks {
+		for _, word := range tokenRE.FindAllString(b, -1) {
+			t.tokens = append(t.tokens, word)
+			t.blocks = append(t.blocks, i)
+		}
+	}
+	t.pairs = wordPairs(t.tokens, t.blocks)
+	for _, n := range t.pairs {
+		t.numPairs += n
+	}
+	return t
+}
+
+func tokenize(normalizedText string) []token {
+	var tokens []token
+	for _, ii := range tokenRE.FindAllStringIndex(normalizedText, -1) {
+		tokens = append(tokens, token{text: normalizedText[ii[0]:ii[1]], begins: ii[0], ends: ii[1] - 1})
+	}
+	return tokens
+}
+
+// wordPairs counts the pairs of adjacent words. Words in different blocks are not paired.
+func wordPairs(words []string, blocks []int) map[string]int {
+	pairs := make(map[string]int, len(words))
+	for i := 1; i < len(words); i++ {
+		if blocks != nil && blocks[i] != blocks[i-1] {
+			continue
+		}
+		pairs[words[i-1]+" "+words[i]]++
+	}
+	return pairs
+}
+
+// dice is the Sørensen–Dice coefficient of two multisets with sizes sizeA and sizeB
+func dice(a map[string]int, sizeA int, b map[string]int, sizeB int) float64 {
+	if sizeA+sizeB <= 0 {
+		return 0
+	}
+	shared := 0
+	for k, n := range a {
+		if m := b[k]; m < n {
+			shared += m
+		} else {
+			shared += n
+		}
+	}
+	return 2 * float64(shared) / float64(sizeA+sizeB)
+}
+
+// diffTokens returns the differences between the template and the text using the longest common subsequence of words.
+// Text added before or after the template, or between its static blocks (where the template has variable text), is not a difference.
+func diffTokens(template templateTokens, text []token, normalizedData *normalizer.NormalizationData) []Difference {
+	n, m := len(template.tokens), len(text)
+	if n*m > maxDiffCells {
+		return nil
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of template.tokens[i:] and text[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if template.tokens[i] == text[j].text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diffs []Difference
+	var removed []string
+	added := -1 // index of the first added text token of the current difference
+	addedEnd := -1
+	flush := func(i int) {
+		if len(removed) == 0 && added < 0 {
+			return
+		}
+		// added text alone is expected where the template has variable text (between blocks, or before or after the template)
+		variable := i == 0 || i == n || template.blocks[i] != template.blocks[i-1]
+		if len(removed) > 0 || !variable {
+			d := Difference{Begins: -1, Ends: -1, Expected: strings.Join(removed, " ")}
+			if added >= 0 {
+				d.Begins, d.Ends = originalRange(text[added].begins, text[addedEnd].ends, normalizedData)
+			}
+			diffs = append(diffs, d)
+		}
+		removed, added, addedEnd = nil, -1, -1
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && template.tokens[i] == text[j].text:
+			flush(i)
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			if added < 0 {
+				added = j
+			}
+			addedEnd = j
+			j++
+		default:
+			removed = append(removed, template.tokens[i])
+			i++
+		}
+	}
+	flush(i)
+	return diffs
+}
+
+// originalRange maps inclusive offsets in the normalized text to the original text
+func originalRange(begins int, ends int, normalizedData *normalizer.NormalizationData) (int, int) {
+	last := len(normalizedData.IndexMap) - 1
+	if begins > last {
+		begins = last
+	}
+	if ends > last {
+		ends = last
+	}
+	return normalizedData.IndexMap[begins], normalizedData.IndexMap[ends]
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func TestIdentify_NearMiss(t *testing.T) {
+	const (
+		simple   = "Copyright 2022 Someone\n\nPermission to use, copy, modify, and distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.\n\nTHE SOFTWARE IS PROVIDED BY SOMEONE \"AS IS\" AND ANY EXPRESS OR IMPLIED WARRANTIES ARE DISCLAIMED.\n"
+		modified = "Copyright 2022 Someone\n\nPermission to use, copy, and distribute this software for any non-commercial purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.\n\nTHE SOFTWARE IS PROVIDED BY SOMEONE \"AS IS\" AND ANY EXPRESS OR IMPLIED WARRANTIES ARE DISCLAIMED.\n"
+	)
+
+	tests := []struct {
+		name          string
+		input         string
+		minSimilarity float64
+		want          *NearMiss
+	}{
+		{
+			name:          "matching license text",
+			input:         simple,
+			minSimilarity: 0.8,
+		},
+		{
+			name:          "modified license text",
+			input:         modified,
+			minSimilarity: 0.8,
+			want: &NearMiss{
+				LicenseId:  "Simple",
+				Similarity: 0.875,
+				Differences: []Difference{
+					{Begins: -1, Ends: -1, Expected: "modify"},
+					{Begins: 86, Ends: 99},
+				},
+			},
+		},
+		{
+			name:          "modified license text below minSimilarity",
+			input:         modified,
+			minSimilarity: 0.9,
+		},
+		{
+			name:  "disabled",
+			input: modified,
+		},
+		{
+			name:          "unrelated text",
+			input:         "Nothing to see here.",
+			minSimilarity: 0.8,
+		},
+	}
+
+	flagSet := configurer.NewDefaultFlags()
+	_ = flagSet.Set(configurer.ConfigPathFlag, "../testdata/similarity")
+	config, err := configurer.InitConfig(flagSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll, err := licenses.NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary(config) error = %v", err)
+	}
+	if err := ll.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IdentifyLicensesInString(tt.input, Options{MinSimilarity: tt.minSimilarity}, ll)
+			if err != nil {
+				t.Fatalf("IdentifyLicensesInString() error = %v", err)
+			}
+			if d := cmp.Diff(tt.want, got.NearMiss, cmpopts.EquateApprox(0, 0.001)); d != "" {
+				t.Errorf("Didn't get expected result: (-want, +got): %v", d)
+			}
+		})
+	}
+
+	if got := modified[86:100]; got != "non-commercial" {
+		t.Errorf("expected the added text to be non-commercial, got %q", got)
+	}
+}
+
+func Test_getSimilarityIndex(t *testing.T) {
+	flagSet := configurer.NewDefaultFlags()
+	_ = flagSet.Set(configurer.ConfigPathFlag, "../testdata/similarity")
+	config, err := configurer.InitConfig(flagSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll, err := licenses.NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary(config) error = %v", err)
+	}
+	if err := ll.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	index := getSimilarityIndex(ll)
+	if len(index.templates) == 0 {
+		t.Fatal("expected the templates of the license library")
+	}
+	if getSimilarityIndex(ll) != index {
+		t.Error("expected the index to be kept with the license library")
+	}
+	delete(ll.LicenseMap, "Simple")
+	ll.ResetIndexes()
+	rebuilt := getSimilarityIndex(ll)
+	if rebuilt == index {
+		t.Fatal("expected the index to be built again when the license library changed")
+	}
+	for _, template := range rebuilt.templates {
+		if template.licenseId == "Simple" {
+			t.Error("expected the index to be built without the removed license")
+		}
+	}
+}
+
+func Test_dice(t *testing.T) {
+	t.Parallel()
+	a := wordPairs([]string{"a", "b", "c", "d"}, nil)
+	b := wordPairs([]string{"a", "b", "x", "c", "d"}, nil)
+	// 2 shared pairs ("a b" and "c d") of 3 + 4 pairs
+	if got, want := dice(a, 3, b, 4), 4.0/7.0; got != want {
+		t.Errorf("dice() = %v want %v", got, want)
+	}
+	if got := dice(nil, 0, nil, 0); got != 0 {
+		t.Errorf("dice() of empty sets = %v want 0", got)
+	}
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"errors"
+
+	"github.com/CycloneDX/license-scanner/archive"
+)
+
+// FileStatus tells whether a text or file was scanned, and if not, why
+type FileStatus string
+
+const (
+	StatusScanned         FileStatus = "scanned"
+	StatusSkippedTooLarge FileStatus = "skipped-too-large"
+	StatusSkippedTooDeep  FileStatus = "skipped-too-deep"
+	StatusSkippedBinary   FileStatus = "skipped-binary"
+	StatusSkippedEmpty    FileStatus = "skipped-empty"
+	StatusUnreadable      FileStatus = "unreadable"
+	StatusError           FileStatus = "error"
+)
+
+// maxFileBytes is the largest file which is scanned
+const maxFileBytes = 1000000
+
+// Skipped is true for any status except StatusScanned
+func (s FileStatus) Skipped() bool {
+	return s != StatusScanned
+}
+
+// newSkippedResult returns the result of a file which was not scanned, with the reason in the Notes
+func newSkippedResult(file string, status FileStatus, notes string) IdentifierResults {
+	return IdentifierResults{File: file, Matches: map[string][]Match{}, Status: status, Notes: notes}
+}
+
+// entryStatus returns the status of an archive entry which was not read
+func entryStatus(err error) FileStatus {
+	switch {
+	case errors.Is(err, archive.ErrTooLarge):
+		return StatusSkippedTooLarge
+	case errors.Is(err, archive.ErrTooDeep):
+		return StatusSkippedTooDeep
+	default:
+		return StatusError
+	}
+}
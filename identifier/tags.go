@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+var (
+	spdxTagRE = regexp.MustCompile(`SPDX-License-Identifier:[ \t]*([^\r\n]*)`)
+	// commentEnds are cut from the end of a tag line
+	commentEnds = []string{"*/", "-->", "--%>", "%>", "#}", "*)", `"""`, "'''"}
+)
+
+// LicenseTag is an SPDX-License-Identifier tag with its parsed license expression.
+// Begins and Ends (inclusive) are the offsets of the expression in the original text.
+type LicenseTag struct {
+	Begins     int
+	Ends       int
+	Expression *expression.Expression
+}
+
+// tagValue is the value of an SPDX-License-Identifier tag, which begins at an offset of the original text
+type tagValue struct {
+	begins int
+	value  string
+}
+
+// findTagValues returns the values of the SPDX-License-Identifier tags in the text, without any comment end (e.g. "*/")
+func findTagValues(originalText string) []tagValue {
+	var values []tagValue
+	for _, ii := range spdxTagRE.FindAllStringSubmatchIndex(originalText, -1) {
+		begins, ends := ii[2], ii[3]
+		value := originalText[begins:ends]
+		for _, end := range commentEnds {
+			if i := strings.Index(value, end); i >= 0 {
+				value = value[:i]
+			}
+		}
+		value = strings.TrimRight(value, " \t")
+		if value != "" {
+			values = append(values, tagValue{begins: begins, value: value})
+		}
+	}
+	return values
+}
+
+// LicenseTagValues returns the license expressions of the SPDX-License-Identifier tags in the text, which are not parsed or validated
+func LicenseTagValues(originalText string) []string {
+	var values []string
+	for _, v := range findTagValues(originalText) {
+		values = append(values, v.value)
+	}
+	return values
+}
+
+// InvalidLicenseTag is an SPDX-License-Identifier tag whose expression is invalid or has an unknown license or exception ID.
+// Begins and Ends (inclusive) are the offsets of the value in the original text.
+type InvalidLicenseTag struct {
+	Begins int
+	Ends   int
+	Value  string
+	Error  string
+}
+
+// findLicenseTags returns the SPDX-License-Identifier tags with valid expressions in the text,
+// and the tags with invalid expressions or unknown license IDs, which are not matches
+func findLicenseTags(licenseLibrary *licenses.LicenseLibrary, originalText string) ([]LicenseTag, []InvalidLicenseTag) {
+	var tags []LicenseTag
+	var invalid []InvalidLicenseTag
+	for _, v := range findTagValues(originalText) {
+		e, err := expression.Parse(v.value)
+		if err == nil {
+			err = e.Validate(licenseLibrary.LicenseMap)
+		}
+		if err != nil {
+			Logger.Debugf("invalid SPDX-License-Identifier at %v: %v", v.begins, err)
+			invalid = append(invalid, InvalidLicenseTag{Begins: v.begins, Ends: v.begins + len(v.value) - 1, Value: v.value, Error: err.Error()})
+			continue
+		}
+		tags = append(tags, LicenseTag{Begins: v.begins, Ends: v.begins + len(v.value) - 1, Expression: e})
+	}
+	return tags, invalid
+}
+
+// tagMatches returns a match for each license in the tags, by license ID.
+// Any "+" and exception are left out of the ID, and are kept in the expression of the tag.
+func tagMatches(tags []LicenseTag, originalText string) map[string][]Match {
+	matches := make(map[string][]Match)
+	for _, tag := range tags {
+		for _, l := range tag.Expression.Licenses() {
+			m := Match{Begins: tag.Begins + l.Begins, Ends: tag.Begins + l.Ends}
+			matches[l.License] = append(matches[l.License], scoreMatch(m, MatchKindTag, originalText))
+		}
+	}
+	return matches
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func TestIdentify_LicenseTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        map[string][]Match
+		wantTags    []string
+		wantTagEnds int
+		wantInvalid []InvalidLicenseTag
+	}{
+		{
+			name:  "tag in a C comment",
+			input: "/* SPDX-License-Identifier: apache-2.0 */\nint x;\n",
+			want: map[string][]Match{"Apache-2.0": {
+				{Begins: 26, Ends: 38, Kind: MatchKindAlias, Confidence: 0.3},
+				{Begins: 28, Ends: 37, Kind: MatchKindTag, Confidence: 0.9},
+			}},
+			wantTags:    []string{"Apache-2.0"},
+			wantTagEnds: 37,
+		},
+		{
+			name:  "expression with a LicenseRef",
+			input: "# SPDX-License-Identifier: Apache-2.0 OR LicenseRef-mine\n",
+			want: map[string][]Match{
+				"Apache-2.0": {
+					{Begins: 25, Ends: 37, Kind: MatchKindAlias, Confidence: 0.3},
+					{Begins: 27, Ends: 36, Kind: MatchKindTag, Confidence: 0.9},
+				},
+				"LicenseRef-mine": {{Begins: 41, Ends: 55, Kind: MatchKindTag, Confidence: 0.9}},
+			},
+			wantTags:    []string{"Apache-2.0 OR LicenseRef-mine"},
+			wantTagEnds: 55,
+		},
+		{
+			name:  "or later is kept in the tag",
+			input: "# SPDX-License-Identifier: Apache-2.0+\n",
+			want: map[string][]Match{"Apache-2.0": {
+				{Begins: 25, Ends: 37, Kind: MatchKindAlias, Confidence: 0.3},
+				{Begins: 27, Ends: 37, Kind: MatchKindTag, Confidence: 0.9},
+			}},
+			wantTags:    []string{"Apache-2.0+"},
+			wantTagEnds: 37,
+		},
+		{
+			name:        "unknown license ID",
+			input:       "// SPDX-License-Identifier: NOPE-1.0\n",
+			want:        map[string][]Match{},
+			wantInvalid: []InvalidLicenseTag{{Begins: 28, Ends: 35, Value: "NOPE-1.0", Error: `unknown license ID "NOPE-1.0"`}},
+		},
+		{
+			name:        "unknown license ID with a valid reference",
+			input:       "// SPDX-License-Identifier: GPL-2.0+ OR LicenseRef-foo\n",
+			want:        map[string][]Match{},
+			wantInvalid: []InvalidLicenseTag{{Begins: 28, Ends: 53, Value: "GPL-2.0+ OR LicenseRef-foo", Error: `unknown license ID "GPL-2.0"`}},
+		},
+		{
+			name:        "invalid expression",
+			input:       "// SPDX-License-Identifier: (Foo-1.0 OR\n",
+			want:        map[string][]Match{},
+			wantInvalid: []InvalidLicenseTag{{Begins: 28, Ends: 38, Value: "(Foo-1.0 OR", Error: `invalid license expression "(Foo-1.0 OR": unexpected end`}},
+		},
+	}
+
+	flagSet := configurer.NewDefaultFlags()
+	_ = flagSet.Set(configurer.ConfigPathFlag, "../testdata/config")
+	config, err := configurer.InitConfig(flagSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll, err := licenses.NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary(config) error = %v", err)
+	}
+	if err := ll.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IdentifyLicensesInString(tt.input, Options{}, ll)
+			if err != nil {
+				t.Fatalf("IdentifyLicensesInString() error = %v", err)
+			}
+			if d := cmp.Diff(tt.want, got.Matches, cmpopts.IgnoreFields(Match{}, "Coverage")); d != "" {
+				t.Errorf("Didn't get expected result: (-want, +got): %v", d)
+			}
+			var tags []string
+			for _, tag := range got.LicenseTags {
+				tags = append(tags, tag.Expression.String())
+				if tag.Ends != tt.wantTagEnds {
+					t.Errorf("tag Ends = %v want %v", tag.Ends, tt.wantTagEnds)
+				}
+			}
+			if d := cmp.Diff(tt.wantTags, tags); d != "" {
+				t.Errorf("Didn't get expected tags: (-want, +got): %v", d)
+			}
+			if d := cmp.Diff(tt.wantInvalid, got.InvalidLicenseTags); d != "" {
+				t.Errorf("Didn't get expected invalid tags: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestLicenseTagValues(t *testing.T) {
+	t.Parallel()
+	text := "/* SPDX-License-Identifier: Foo OR (MIT */\n# SPDX-License-Identifier:\n<!-- SPDX-License-Identifier: LicenseRef-mine -->\n"
+	want := []string{"Foo OR (MIT", "LicenseRef-mine"}
+	if d := cmp.Diff(want, LicenseTagValues(text)); d != "" {
+		t.Errorf("Didn't get expected tag values: (-want, +got): %v", d)
+	}
+}
@@ -20,8 +20,8 @@ import (
 var Logger *log.MiniLogger = log.NewLogger(log.DEFAULT_LEVEL)
 
 // Import validates, preprocesses, and copies templates into resources (or into external paths)
-// This implements --addAll (which probably be changed to license-scanner import ...)
-// The --addAll <string> value is the input dir. Output is determined by spdxPath/spdx/customPath/custom flags.
+// This implements the import command (and the deprecated --addAll flag)
+// The --addAll <string> value is the input dir (the DIR argument of import). Output is determined by spdxPath/spdx/customPath/custom flags.
 func Import(cfg *viper.Viper) error {
 	input := cfg.GetString(configurer.AddAllFlag)
 	if input == "" {
@@ -41,7 +41,7 @@ func Import(cfg *viper.Viper) error {
 }
 
 // Update validates, preprocesses, and updates preprocessed prechecks in resources (or into external paths)
-// This implements --updateAll (which probably be changed to license-scanner update ...)
+// This implements the update command (and the deprecated --updateAll flag)
 // The args spdxPath/spdx/customPath/custom are used to determine which resources (or external dir) are updated in-place.
 func Update(cfg *viper.Viper) error {
 	doUpdate := cfg.GetBool(configurer.UpdateAllFlag)
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Fingerprint returns a SHA-256 of the SPDX license list version and the content of the loaded licenses
+// (license info, pattern sources, aliases, URLs, prechecks, and acceptable patterns).
+// It changes whenever a change to the library could change the scan results, so it is used to invalidate cached results.
+func (ll *LicenseLibrary) Fingerprint() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "spdx:%q\n", ll.SPDXVersion)
+
+	ids := make([]string, 0, len(ll.LicenseMap))
+	for id := range ll.LicenseMap {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		l := ll.LicenseMap[id]
+		info, _ := json.Marshal(l.LicenseInfo)
+		fmt.Fprintf(h, "license:%q %q %s\n", id, l.SPDXLicenseID, info)
+		writeSources(h, "primary", l.PrimaryPatternsSources)
+		writeSources(h, "associated", l.AssociatedPatternsSources)
+		for _, a := range l.Aliases {
+			fmt.Fprintf(h, "alias:%q\n", a)
+		}
+		for _, u := range l.URLs {
+			fmt.Fprintf(h, "url:%q\n", u)
+		}
+		fmt.Fprintf(h, "text:%q\n", l.Text.Content)
+	}
+
+	keys := make([]LicensePatternKey, 0, len(ll.PrimaryPatternPreCheckMap))
+	for k := range ll.PrimaryPatternPreCheckMap {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].FilePath < keys[j].FilePath })
+	for _, k := range keys {
+		if pc := ll.PrimaryPatternPreCheckMap[k]; pc != nil {
+			fmt.Fprintf(h, "precheck:%q %q\n", k.FilePath, pc.StaticBlocks)
+		}
+	}
+
+	names := make([]string, 0, len(ll.AcceptablePatternsMap))
+	for name := range ll.AcceptablePatternsMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if re := ll.AcceptablePatternsMap[name]; re != nil {
+			fmt.Fprintf(h, "acceptable:%q %q\n", name, re.String())
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func writeSources(w io.Writer, kind string, sources []PrimaryPatternsSources) {
+	for _, s := range sources {
+		fmt.Fprintf(w, "%v:%q %q\n", kind, s.Filename, s.SourceText)
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package licenses
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+)
+
+func TestLicenseLibrary_Fingerprint(t *testing.T) {
+	t.Parallel()
+	newLibrary := func() *LicenseLibrary {
+		flags := configurer.NewDefaultFlags()
+		if err := flags.Set(configurer.ConfigPathFlag, "../testdata/config/"); err != nil {
+			t.Fatal(err)
+		}
+		config, err := configurer.InitConfig(flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ll, err := NewLicenseLibrary(config)
+		if err != nil {
+			t.Fatalf("NewLicenseLibrary() error = %v", err)
+		}
+		if err := ll.AddAll(); err != nil {
+			t.Fatalf("AddAll() error = %v", err)
+		}
+		return ll
+	}
+
+	ll := newLibrary()
+	fingerprint := ll.Fingerprint()
+	if len(fingerprint) != 64 {
+		t.Fatalf("expected a SHA-256 hex fingerprint got %q", fingerprint)
+	}
+	if got := newLibrary().Fingerprint(); got != fingerprint {
+		t.Errorf("expected the same fingerprint for the same library got %v and %v", fingerprint, got)
+	}
+
+	l := ll.LicenseMap["Apache-2.0"]
+	l.PrimaryPatternsSources = append([]PrimaryPatternsSources{}, l.PrimaryPatternsSources...)
+	l.PrimaryPatternsSources[0].SourceText += " changed"
+	ll.LicenseMap["Apache-2.0"] = l
+	changedPattern := ll.Fingerprint()
+	if changedPattern == fingerprint {
+		t.Errorf("expected a new fingerprint when a pattern changes")
+	}
+
+	ll.SPDXVersion = "9.99"
+	if ll.Fingerprint() == changedPattern {
+		t.Errorf("expected a new fingerprint when the SPDX version changes")
+	}
+}
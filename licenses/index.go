@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+import "sync"
+
+// libraryIndex is a value built from the licenses of a library, once when it is first needed
+type libraryIndex struct {
+	once  sync.Once
+	value interface{}
+}
+
+// Index returns the index of the key (e.g. the candidate index of the identifier), which build returns when it is first needed.
+// Indexes are kept with the library, so they are freed with it, and are built again after licenses are added (see ResetIndexes).
+func (ll *LicenseLibrary) Index(key interface{}, build func() interface{}) interface{} {
+	ll.indexLock.Lock()
+	if ll.indexes == nil {
+		ll.indexes = make(map[interface{}]*libraryIndex)
+	}
+	index, ok := ll.indexes[key]
+	if !ok {
+		index = &libraryIndex{}
+		ll.indexes[key] = index
+	}
+	ll.indexLock.Unlock()
+	index.once.Do(func() { index.value = build() })
+	return index.value
+}
+
+// ResetIndexes drops the indexes of the library, which are built again when next needed.
+// It is called when licenses are added, and must be called after the maps of the library are changed directly.
+func (ll *LicenseLibrary) ResetIndexes() {
+	ll.indexLock.Lock()
+	defer ll.indexLock.Unlock()
+	ll.indexes = nil
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package licenses
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+)
+
+func TestLicenseLibrary_Index(t *testing.T) {
+	t.Parallel()
+	flags := configurer.NewDefaultFlags()
+	if err := flags.Set(configurer.ConfigPathFlag, "../testdata/config/"); err != nil {
+		t.Fatal(err)
+	}
+	config, err := configurer.InitConfig(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll, err := NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := ll.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	builds := 0
+	build := func() interface{} {
+		builds++
+		return builds
+	}
+	if got := ll.Index("test", build); got != 1 {
+		t.Errorf("Index() = %v want 1", got)
+	}
+	if got := ll.Index("test", build); got != 1 || builds != 1 {
+		t.Errorf("Index() = %v after %v builds, expected the index to be kept", got, builds)
+	}
+
+	// adding the same licenses again keeps the number of licenses, but the index is built again
+	numLicenses := len(ll.LicenseMap)
+	if err := ll.AddCustomLicenses(); err != nil {
+		t.Fatalf("AddCustomLicenses() error = %v", err)
+	}
+	if len(ll.LicenseMap) != numLicenses {
+		t.Fatalf("expected %v licenses got %v", numLicenses, len(ll.LicenseMap))
+	}
+	if got := ll.Index("test", build); got != 2 {
+		t.Errorf("Index() = %v want 2 after licenses were added", got)
+	}
+}
@@ -58,6 +58,10 @@ type LicenseLibrary struct {
 	AcceptablePatternsMap     PatternsMap
 	Config                    *viper.Viper
 	Resources                 *resources.Resources
+
+	// indexes are built from the licenses by other packages (see Index)
+	indexLock sync.Mutex
+	indexes   map[interface{}]*libraryIndex
 }
 
 type LicensePreChecks struct {
@@ -133,6 +137,8 @@ type PrimaryPatterns struct {
 	re            *regexp.Regexp
 	CaptureGroups []*normalizer.CaptureGroup
 	FileName      string
+	// regexp is the regular expression of a pattern from a snapshot, which is compiled in place of the normalized Text
+	regexp string
 }
 
 type PrimaryPatternsSources struct {
@@ -246,6 +252,14 @@ func (l License) GetID() string {
 	}
 }
 
+// Load adds the licenses of the snapshot in the library flag (see AddSnapshot), or else all the SPDX and custom licenses (see AddAll)
+func (ll *LicenseLibrary) Load() error {
+	if snapshot := ll.Config.GetString(configurer.LibraryFlag); snapshot != "" {
+		return ll.AddSnapshotFile(snapshot)
+	}
+	return ll.AddAll()
+}
+
 func (ll *LicenseLibrary) AddAll() error {
 	if err := ll.AddAllSPDX(); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		// not exist is okay for now. Assuming legacy resources
@@ -262,6 +276,7 @@ func (ll *LicenseLibrary) AddAllSPDX() error {
 	}
 
 	ll.SPDXVersion = licenseList.LicenseListVersion
+	defer ll.ResetIndexes()
 
 	for _, sl := range licenseList.Licenses {
 		id := sl.LicenseID
@@ -433,6 +448,7 @@ func (ll *LicenseLibrary) AddCustomLicenses() error {
 }
 
 func AddLicense(id string, ll *LicenseLibrary) error {
+	defer ll.ResetIndexes()
 	l, existed := ll.LicenseMap[id]
 
 	des, idPath, err := ll.Resources.ReadCustomLicensePatternsDir(id)
@@ -596,6 +612,10 @@ func AddPrimaryPatternAndSource(fileContents string, filePath string, l *License
 func GenerateMatchingPatternFromSourceText(pp *PrimaryPatterns) (*regexp.Regexp, error) {
 	var err error
 	pp.doOnce.Do(func() {
+		if pp.regexp != "" {
+			pp.re, err = regexp.Compile(pp.regexp)
+			return
+		}
 		// Normalize the input text.
 		normalizedData := normalizer.NewNormalizationData(pp.Text, true)
 		err = normalizedData.NormalizeText()
@@ -666,7 +686,7 @@ func List(config *viper.Viper) (lics []Detail, deprecatedLics []Detail, exceptio
 		return
 	}
 
-	err = ll.AddAll()
+	err = ll.Load()
 	if err != nil {
 		return
 	}
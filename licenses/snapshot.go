@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"regexp"
+	"runtime/debug"
+
+	"github.com/CycloneDX/license-scanner/normalizer"
+)
+
+// SnapshotVersion is the version of the snapshot format.
+// Change it when the fields change, or when a change to the normalizer changes the patterns generated from the same templates.
+const SnapshotVersion = 2
+
+// modulePath is the path of this module, whose version is found in the build info when it is a dependency
+const modulePath = "github.com/CycloneDX/license-scanner"
+
+const snapshotFormat = "license-scanner-library"
+
+var snapshotTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrStaleSnapshot is returned when a snapshot has another version, or was written from other or changed resources
+var ErrStaleSnapshot = errors.New("stale license library snapshot")
+
+// snapshotHeader is written before the gob encoded snapshot, so that a snapshot of another version is not decoded
+type snapshotHeader struct {
+	Format  string
+	Version int
+	// ToolVersion is the version of license-scanner which wrote the snapshot (see toolVersion)
+	ToolVersion string
+	// Resources is the digest of the resource files the library was loaded from (see resources.Resources.Digest)
+	Resources string
+	// Checksum is the CRC-32 (Castagnoli) of the encoded snapshot
+	Checksum uint32
+}
+
+// snapshot is the serialized form of a loaded LicenseLibrary
+type snapshot struct {
+	SPDXVersion        string
+	Licenses           map[string]snapshotLicense
+	PreChecks          map[string][]string
+	AcceptablePatterns map[string]string
+}
+
+type snapshotLicense struct {
+	SPDXLicenseID      string
+	LicenseInfo        LicenseInfo
+	PrimaryPatterns    []snapshotPattern
+	AssociatedPatterns []snapshotPattern
+	Aliases            []string
+	URLs               []string
+	Text               LicenseText
+}
+
+// snapshotPattern is a pattern with the regular expression generated from its normalized text
+type snapshotPattern struct {
+	FileName      string
+	SourceText    string
+	Regexp        string
+	CaptureGroups []*normalizer.CaptureGroup
+}
+
+// WriteSnapshotFile writes the snapshot of the library to a file (see WriteSnapshot)
+func (ll *LicenseLibrary) WriteSnapshotFile(filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if err := ll.WriteSnapshot(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteSnapshot writes the loaded library in a binary (gob) format, with the regular expression of each pattern.
+// Loading it with AddSnapshot is faster than loading the templates again, and patterns are not normalized again.
+func (ll *LicenseLibrary) WriteSnapshot(w io.Writer) error {
+	digest, err := ll.Resources.Digest()
+	if err != nil {
+		return err
+	}
+	s := snapshot{
+		SPDXVersion:        ll.SPDXVersion,
+		Licenses:           make(map[string]snapshotLicense, len(ll.LicenseMap)),
+		PreChecks:          make(map[string][]string, len(ll.PrimaryPatternPreCheckMap)),
+		AcceptablePatterns: make(map[string]string, len(ll.AcceptablePatternsMap)),
+	}
+	for id, l := range ll.LicenseMap {
+		sl := snapshotLicense{
+			SPDXLicenseID: l.SPDXLicenseID,
+			LicenseInfo:   l.LicenseInfo,
+			Aliases:       l.Aliases,
+			URLs:          l.URLs,
+			Text:          l.Text,
+		}
+		if sl.PrimaryPatterns, err = newSnapshotPatterns(l.PrimaryPatterns); err != nil {
+			return fmt.Errorf("license %v: %w", id, err)
+		}
+		if sl.AssociatedPatterns, err = newSnapshotPatterns(l.AssociatedPatterns); err != nil {
+			return fmt.Errorf("license %v: %w", id, err)
+		}
+		s.Licenses[id] = sl
+	}
+	for k, pc := range ll.PrimaryPatternPreCheckMap {
+		if pc != nil {
+			s.PreChecks[k.FilePath] = pc.StaticBlocks
+		}
+	}
+	for name, re := range ll.AcceptablePatternsMap {
+		if re != nil {
+			s.AcceptablePatterns[name] = re.String()
+		}
+	}
+
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(s); err != nil {
+		return err
+	}
+	header := snapshotHeader{Format: snapshotFormat, Version: SnapshotVersion, ToolVersion: toolVersion(), Resources: digest, Checksum: crc32.Checksum(b.Bytes(), snapshotTable)}
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+	return enc.Encode(b.Bytes())
+}
+
+func newSnapshotPatterns(patterns []*PrimaryPatterns) ([]snapshotPattern, error) {
+	ret := make([]snapshotPattern, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := GenerateMatchingPatternFromSourceText(p)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %v: %w", p.FileName, err)
+		}
+		sp := snapshotPattern{FileName: p.FileName, SourceText: p.Text, CaptureGroups: p.CaptureGroups}
+		if re != nil {
+			sp.Regexp = re.String()
+		}
+		ret = append(ret, sp)
+	}
+	return ret, nil
+}
+
+// AddSnapshotFile adds the licenses of a snapshot file (see AddSnapshot)
+func (ll *LicenseLibrary) AddSnapshotFile(filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := ll.AddSnapshot(f); err != nil {
+		return fmt.Errorf("%v: %w", filePath, err)
+	}
+	return nil
+}
+
+// AddSnapshot adds the licenses, prechecks, and acceptable patterns of a snapshot written by WriteSnapshot, in place of AddAll.
+// It returns an ErrStaleSnapshot when the snapshot has another version, or when the resources of the library were changed since it was written.
+func (ll *LicenseLibrary) AddSnapshot(r io.Reader) error {
+	dec := gob.NewDecoder(bufio.NewReader(r))
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("not a license library snapshot: %w", err)
+	}
+	if header.Format != snapshotFormat {
+		return fmt.Errorf("not a license library snapshot: format %q", header.Format)
+	}
+	if header.Version != SnapshotVersion {
+		return fmt.Errorf("%w: version %v (expected %v)", ErrStaleSnapshot, header.Version, SnapshotVersion)
+	}
+	if v := toolVersion(); header.ToolVersion != v {
+		return fmt.Errorf("%w: written by license-scanner %q (expected %q)", ErrStaleSnapshot, header.ToolVersion, v)
+	}
+	digest, err := ll.Resources.Digest()
+	if err != nil {
+		return err
+	}
+	if header.Resources != digest {
+		return fmt.Errorf("%w: the license resources are not the ones it was compiled from", ErrStaleSnapshot)
+	}
+	var b []byte
+	if err := dec.Decode(&b); err != nil {
+		return fmt.Errorf("corrupt license library snapshot: %w", err)
+	}
+	if checksum := crc32.Checksum(b, snapshotTable); checksum != header.Checksum {
+		return fmt.Errorf("corrupt license library snapshot: checksum %08x (expected %08x)", checksum, header.Checksum)
+	}
+	var s snapshot
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&s); err != nil {
+		return fmt.Errorf("corrupt license library snapshot: %w", err)
+	}
+
+	ll.SPDXVersion = s.SPDXVersion
+	defer ll.ResetIndexes()
+	for id, sl := range s.Licenses {
+		l := License{
+			SPDXLicenseID: sl.SPDXLicenseID,
+			LicenseInfo:   sl.LicenseInfo,
+			Aliases:       sl.Aliases,
+			URLs:          sl.URLs,
+			Text:          sl.Text,
+		}
+		l.PrimaryPatterns, l.PrimaryPatternsSources = newPatterns(sl.PrimaryPatterns)
+		l.AssociatedPatterns, l.AssociatedPatternsSources = newPatterns(sl.AssociatedPatterns)
+		ll.LicenseMap[id] = l
+	}
+	for filePath, staticBlocks := range s.PreChecks {
+		ll.PrimaryPatternPreCheckMap[LicensePatternKey{FilePath: filePath}] = &LicensePreChecks{StaticBlocks: staticBlocks}
+	}
+	for name, source := range s.AcceptablePatterns {
+		re, err := regexp.Compile(source)
+		if err != nil {
+			return fmt.Errorf("acceptable pattern %v: %w", name, err)
+		}
+		ll.AcceptablePatternsMap[name] = re
+	}
+	Logger.Debugf("Loaded %v licenses from a snapshot", len(ll.LicenseMap))
+	return nil
+}
+
+// newPatterns returns the patterns of a snapshot, which are compiled from their regular expression when first used
+func newPatterns(patterns []snapshotPattern) ([]*PrimaryPatterns, []PrimaryPatternsSources) {
+	var ret []*PrimaryPatterns
+	var sources []PrimaryPatternsSources
+	for _, sp := range patterns {
+		ret = append(ret, &PrimaryPatterns{
+			Text:          sp.SourceText,
+			FileName:      sp.FileName,
+			CaptureGroups: sp.CaptureGroups,
+			regexp:        sp.Regexp,
+		})
+		sources = append(sources, PrimaryPatternsSources{SourceText: sp.SourceText, Filename: sp.FileName})
+	}
+	return ret, sources
+}
+
+// toolVersion returns the version of license-scanner and the VCS revision it was built from, when the build info has them.
+// A snapshot written by another build is stale, because its normalizer or matcher may differ.
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	v := info.Main.Version
+	if info.Main.Path != modulePath {
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				v = dep.Version
+			}
+		}
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+			v += " " + setting.Value
+		}
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package licenses
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+)
+
+func newTestLibrary(t *testing.T, spdx string) *LicenseLibrary {
+	t.Helper()
+	flags := configurer.NewDefaultFlags()
+	if err := flags.Set(configurer.ConfigPathFlag, "../testdata/config/"); err != nil {
+		t.Fatal(err)
+	}
+	if spdx != "" {
+		if err := flags.Set(configurer.SpdxFlag, spdx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config, err := configurer.InitConfig(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll, err := NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	return ll
+}
+
+func TestLicenseLibrary_Snapshot(t *testing.T) {
+	t.Parallel()
+	ll := newTestLibrary(t, "")
+	if err := ll.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	var b bytes.Buffer
+	if err := ll.WriteSnapshot(&b); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+	snapshot := b.Bytes()
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+		got := newTestLibrary(t, "")
+		if err := got.AddSnapshot(bytes.NewReader(snapshot)); err != nil {
+			t.Fatalf("AddSnapshot() error = %v", err)
+		}
+		if got.Fingerprint() != ll.Fingerprint() {
+			t.Errorf("expected the fingerprint of the snapshot %v got %v", ll.Fingerprint(), got.Fingerprint())
+		}
+		if len(got.PrimaryPatternPreCheckMap) != len(ll.PrimaryPatternPreCheckMap) || len(got.AcceptablePatternsMap) != len(ll.AcceptablePatternsMap) {
+			t.Errorf("expected %v prechecks and %v acceptable patterns got %v and %v",
+				len(ll.PrimaryPatternPreCheckMap), len(ll.AcceptablePatternsMap), len(got.PrimaryPatternPreCheckMap), len(got.AcceptablePatternsMap))
+		}
+		for id, l := range ll.LicenseMap {
+			for i, p := range l.PrimaryPatterns {
+				want, err := GenerateMatchingPatternFromSourceText(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				re, err := GenerateMatchingPatternFromSourceText(got.LicenseMap[id].PrimaryPatterns[i])
+				if err != nil {
+					t.Fatalf("GenerateMatchingPatternFromSourceText(%v) error = %v", p.FileName, err)
+				}
+				if re.String() != want.String() {
+					t.Errorf("%v: expected regexp %v got %v", p.FileName, want, re)
+				}
+			}
+		}
+	})
+
+	tests := []struct {
+		name     string
+		spdx     string
+		snapshot func() []byte
+		stale    bool
+		wantErr  string
+	}{
+		{
+			name:     "other resources",
+			spdx:     "default",
+			snapshot: func() []byte { return snapshot },
+			stale:    true,
+		},
+		{
+			name: "other version",
+			snapshot: func() []byte {
+				var b bytes.Buffer
+				if err := gob.NewEncoder(&b).Encode(snapshotHeader{Format: snapshotFormat, Version: SnapshotVersion + 1}); err != nil {
+					t.Fatal(err)
+				}
+				return b.Bytes()
+			},
+			stale: true,
+		},
+		{
+			name: "other tool version",
+			snapshot: func() []byte {
+				var b bytes.Buffer
+				if err := gob.NewEncoder(&b).Encode(snapshotHeader{Format: snapshotFormat, Version: SnapshotVersion, ToolVersion: "v0.0.1 other"}); err != nil {
+					t.Fatal(err)
+				}
+				return b.Bytes()
+			},
+			stale: true,
+		},
+		{
+			name: "corrupt",
+			snapshot: func() []byte {
+				b := append([]byte{}, snapshot...)
+				b[len(b)-10] ^= 0xff
+				return b
+			},
+			wantErr: "corrupt license library snapshot",
+		},
+		{
+			name:     "not a snapshot",
+			snapshot: func() []byte { return []byte("not a snapshot") },
+			wantErr:  "not a license library snapshot",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := newTestLibrary(t, tt.spdx)
+			err := got.AddSnapshot(bytes.NewReader(tt.snapshot()))
+			if err == nil {
+				t.Fatal("AddSnapshot() expected an error")
+			}
+			if errors.Is(err, ErrStaleSnapshot) != tt.stale {
+				t.Errorf("AddSnapshot() error = %v, expected stale %v", err, tt.stale)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("AddSnapshot() error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/CycloneDX/license-scanner/importer"
 	"github.com/CycloneDX/license-scanner/licenses"
 	"github.com/CycloneDX/license-scanner/normalizer"
+	"github.com/CycloneDX/license-scanner/reuse"
 )
 
 var (
@@ -34,6 +35,7 @@ func init() {
 	importer.Logger = Logger
 	normalizer.Logger = Logger
 	licenses.Logger = Logger
+	reuse.Logger = Logger
 }
 
 func main() {
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Type is the package manager of a manifest.
+// There is no Go type: a go.mod declares no license, so the license of a Go module is the one detected in its license files.
+type Type string
+
+const (
+	NPM      Type = "npm"
+	Maven    Type = "maven"
+	PyPI     Type = "pypi"
+	Cargo    Type = "cargo"
+	NuGet    Type = "nuget"
+	Gem      Type = "gem"
+	Composer Type = "composer"
+)
+
+// packageManagers are the names of the package managers (and PURL types) of each manifest type
+var packageManagers = map[string]Type{
+	"npm":       NPM,
+	"yarn":      NPM,
+	"pnpm":      NPM,
+	"maven":     Maven,
+	"gradle":    Maven,
+	"pypi":      PyPI,
+	"pip":       PyPI,
+	"poetry":    PyPI,
+	"cargo":     Cargo,
+	"crates":    Cargo,
+	"nuget":     NuGet,
+	"gem":       Gem,
+	"rubygems":  Gem,
+	"bundler":   Gem,
+	"composer":  Composer,
+	"packagist": Composer,
+}
+
+// gemMetadataPrefix starts the YAML metadata of a gem, which has the same file name as the metadata of a Python wheel
+var gemMetadataPrefix = []byte("--- !ruby/object:Gem::Specification")
+
+// License is a license declared by a manifest, by name (or SPDX expression), by URL, or both
+type License struct {
+	Name string
+	URL  string
+}
+
+// Manifest holds the licenses declared by a package manifest
+type Manifest struct {
+	Type     Type
+	Licenses []License
+	// Choice is true when the licenses are alternatives (OR), and false when they all apply (AND)
+	Choice bool
+}
+
+// ParseType returns the manifest type of a package manager or PURL type (e.g. "npm", "pip", "rubygems")
+func ParseType(packageManager string) (Type, bool) {
+	t, ok := packageManagers[strings.ToLower(strings.TrimSpace(packageManager))]
+	return t, ok
+}
+
+// TypeOf returns the type of the manifest with this file name, or "" if it is not a package manifest.
+// The path may be a virtual path in an archive.
+func TypeOf(file string) Type {
+	name := strings.ToLower(path.Base(filepath.ToSlash(file)))
+	switch name {
+	case "package.json":
+		return NPM
+	case "pom.xml":
+		return Maven
+	case "setup.cfg", "pyproject.toml", "pkg-info", "metadata":
+		return PyPI
+	case "cargo.toml":
+		return Cargo
+	case "composer.json":
+		return Composer
+	}
+	switch path.Ext(name) {
+	case ".pom":
+		return Maven
+	case ".nuspec":
+		return NuGet
+	case ".gemspec":
+		return Gem
+	}
+	return ""
+}
+
+// Parse returns the licenses declared by the manifest with this file name and content,
+// or nil if the file is not a package manifest or declares no license
+func Parse(file string, content []byte) (*Manifest, error) {
+	t := TypeOf(file)
+	name := strings.ToLower(path.Base(filepath.ToSlash(file)))
+	if name == "metadata" && bytes.HasPrefix(content, gemMetadataPrefix) {
+		t = Gem
+	}
+
+	var m *Manifest
+	var err error
+	switch {
+	case t == NPM:
+		m, err = parsePackageJSON(content)
+	case t == Composer:
+		m, err = parseComposerJSON(content)
+	case t == Maven:
+		m, err = parsePOM(content)
+	case t == NuGet:
+		m, err = parseNuspec(content)
+	case t == Cargo:
+		m, err = parseCargoToml(content)
+	case t == Gem && name == "metadata":
+		m, err = parseGemMetadata(content)
+	case t == Gem:
+		m, err = parseGemspec(content)
+	case name == "setup.cfg":
+		m, err = parseSetupCfg(content)
+	case name == "pyproject.toml":
+		m, err = parsePyprojectToml(content)
+	case t == PyPI:
+		m, err = parsePKGInfo(content)
+	default:
+		return nil, nil
+	}
+	if err != nil || m == nil || len(m.Licenses) == 0 {
+		return nil, err
+	}
+	m.Type = t
+	return m, nil
+}
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package manifest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		file    string
+		content string
+		want    *Manifest
+		wantErr bool
+	}{
+		{
+			file:    "package.json",
+			content: `{"name": "a", "license": "(MIT OR Apache-2.0)"}`,
+			want:    &Manifest{Type: NPM, Licenses: []License{{Name: "(MIT OR Apache-2.0)"}}},
+		},
+		{
+			file:    "node_modules/b/package.json",
+			content: `{"licenses": [{"type": "MIT", "url": "https://opensource.org/licenses/MIT"}, "ISC"]}`,
+			want:    &Manifest{Type: NPM, Licenses: []License{{Name: "MIT", URL: "https://opensource.org/licenses/MIT"}, {Name: "ISC"}}},
+		},
+		{file: "package.json", content: `{"license": "SEE LICENSE IN LICENSE.txt"}`},
+		{file: "package.json", content: `{"name": "no license"}`},
+		{file: "package.json", content: `{`, wantErr: true},
+		{
+			file:    "composer.json",
+			content: `{"license": ["LGPL-2.1-only", "GPL-3.0-or-later"]}`,
+			want:    &Manifest{Type: Composer, Licenses: []License{{Name: "LGPL-2.1-only"}, {Name: "GPL-3.0-or-later"}}, Choice: true},
+		},
+		{
+			file: "pom.xml",
+			content: `<project xmlns="http://maven.apache.org/POM/4.0.0"><licenses><license>
+				<name>The Apache Software License, Version 2.0</name>
+				<url>https://www.apache.org/licenses/LICENSE-2.0.txt</url>
+			</license></licenses></project>`,
+			want: &Manifest{Type: Maven, Licenses: []License{{Name: "The Apache Software License, Version 2.0", URL: "https://www.apache.org/licenses/LICENSE-2.0.txt"}}},
+		},
+		{
+			file:    "lib/foo-1.0.pom",
+			content: `<project><licenses><license><url>https://spdx.org/licenses/EPL-2.0.html</url></license></licenses></project>`,
+			want:    &Manifest{Type: Maven, Licenses: []License{{URL: "https://spdx.org/licenses/EPL-2.0.html"}}},
+		},
+		{
+			file:    "Foo.nuspec",
+			content: `<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd"><metadata><license type="expression">MIT</license><licenseUrl>https://licenses.nuget.org/MIT</licenseUrl></metadata></package>`,
+			want:    &Manifest{Type: NuGet, Licenses: []License{{Name: "MIT"}}},
+		},
+		{
+			file:    "Bar.nuspec",
+			content: `<package><metadata><license type="file">LICENSE.txt</license><licenseUrl>https://aka.ms/deprecateLicenseUrl</licenseUrl></metadata></package>`,
+		},
+		{
+			file:    "Cargo.toml",
+			content: "[package]\nname = \"foo\"\nlicense = \"MIT/Apache-2.0\"\n",
+			want:    &Manifest{Type: Cargo, Licenses: []License{{Name: "MIT OR Apache-2.0"}}},
+		},
+		{
+			file:    "foo.gemspec",
+			content: "Gem::Specification.new do |s|\n  s.name = 'foo'\n  s.licenses = ['MIT', \"Ruby\"]\nend\n",
+			want:    &Manifest{Type: Gem, Licenses: []License{{Name: "MIT"}, {Name: "Ruby"}}},
+		},
+		{
+			file:    "bar.gemspec",
+			content: "Gem::Specification.new do |spec|\n  spec.license = %w[BSD-2-Clause]\nend\n",
+			want:    &Manifest{Type: Gem, Licenses: []License{{Name: "BSD-2-Clause"}}},
+		},
+		{
+			file:    "foo-1.0.gem!/metadata",
+			content: "--- !ruby/object:Gem::Specification\nname: foo\nlicenses:\n- MIT\n",
+			want:    &Manifest{Type: Gem, Licenses: []License{{Name: "MIT"}}},
+		},
+		{
+			file:    "foo-1.0.dist-info/METADATA",
+			content: "Metadata-Version: 2.1\nName: foo\nLicense: BSD\nClassifier: License :: OSI Approved :: MIT License\n\nLicense: body\n",
+			want:    &Manifest{Type: PyPI, Licenses: []License{{Name: "BSD"}}},
+		},
+		{
+			file:    "PKG-INFO",
+			content: "Name: foo\nLicense: Copyright (c) 2020 Someone\n        Permission is hereby granted\nClassifier: License :: OSI Approved\nClassifier: License :: OSI Approved :: MIT License\n",
+			want:    &Manifest{Type: PyPI, Licenses: []License{{Name: "MIT License"}}},
+		},
+		{
+			file:    "PKG-INFO",
+			content: "Name: foo\nLicense-Expression: MIT OR Apache-2.0\nLicense: MIT\n",
+			want:    &Manifest{Type: PyPI, Licenses: []License{{Name: "MIT OR Apache-2.0"}}},
+		},
+		{
+			file:    "setup.cfg",
+			content: "[metadata]\nname = foo\nclassifiers =\n    Programming Language :: Python\n    License :: OSI Approved :: Apache Software License\n\n[options]\nlicense = ignored\n",
+			want:    &Manifest{Type: PyPI, Licenses: []License{{Name: "Apache Software License"}}},
+		},
+		{
+			file:    "pyproject.toml",
+			content: "[project]\nname = \"foo\"\nlicense = {text = \"MIT\"}\n",
+			want:    &Manifest{Type: PyPI, Licenses: []License{{Name: "MIT"}}},
+		},
+		{
+			file:    "pyproject.toml",
+			content: "[tool.poetry]\nname = \"foo\"\nlicense = \"Apache-2.0\"\n",
+			want:    &Manifest{Type: PyPI, Licenses: []License{{Name: "Apache-2.0"}}},
+		},
+		{file: "go.mod", content: "module example.com/foo\n"},
+		{file: "LICENSE", content: "MIT License"},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.file, []byte(tt.content))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: Parse() error = %v, wantErr %v", tt.file, err, tt.wantErr)
+			continue
+		}
+		if d := cmp.Diff(tt.want, got); d != "" {
+			t.Errorf("%v: Didn't get expected manifest: (-want, +got): %v", tt.file, d)
+		}
+	}
+}
+
+func TestParseType(t *testing.T) {
+	t.Parallel()
+	for name, want := range map[string]Type{"npm": NPM, "PyPI": PyPI, " rubygems ": Gem, "golang": ""} {
+		if got, _ := ParseType(name); got != want {
+			t.Errorf("%q: expected %q got %q", name, want, got)
+		}
+	}
+}
+
+func TestManifest_Resolve(t *testing.T) {
+	t.Parallel()
+	licenseMap := licenses.LicenseMap{
+		"MIT":                     {LicenseInfo: licenses.LicenseInfo{Name: "MIT License"}},
+		"Apache-2.0":              {LicenseInfo: licenses.LicenseInfo{Name: "Apache License 2.0"}, Aliases: []string{"apache license, version 2.0"}, URLs: []string{"www.apache.org/licenses/license-2.0"}},
+		"EPL-2.0":                 {LicenseInfo: licenses.LicenseInfo{Name: "Eclipse Public License 2.0"}},
+		"GPL-2.0":                 {LicenseInfo: licenses.LicenseInfo{Name: "GNU General Public License v2.0 only", IsDeprecated: true}},
+		"GPL-2.0-only":            {LicenseInfo: licenses.LicenseInfo{Name: "GNU General Public License v2.0 only"}},
+		"Classpath-exception-2.0": {LicenseInfo: licenses.LicenseInfo{Name: "Classpath exception 2.0", SPDXException: true}},
+	}
+	tests := []struct {
+		name           string
+		manifest       Manifest
+		wantExpression string
+		wantUnresolved []string
+	}{
+		{name: "expression", manifest: Manifest{Licenses: []License{{Name: "(mit OR Apache-2.0)"}}}, wantExpression: "MIT OR Apache-2.0"},
+		{name: "name", manifest: Manifest{Licenses: []License{{Name: "The MIT  License"}}}, wantExpression: "MIT"},
+		{name: "alias", manifest: Manifest{Licenses: []License{{Name: "Apache License, Version 2.0"}}}, wantExpression: "Apache-2.0"},
+		{name: "not deprecated", manifest: Manifest{Licenses: []License{{Name: "GNU General Public License v2.0 only"}}}, wantExpression: "GPL-2.0-only"},
+		{name: "URL", manifest: Manifest{Licenses: []License{{Name: "ASL 2", URL: "http://apache.org/licenses/LICENSE-2.0.txt"}}}, wantExpression: "Apache-2.0"},
+		{name: "SPDX URL", manifest: Manifest{Licenses: []License{{URL: "https://spdx.org/licenses/EPL-2.0.html"}}}, wantExpression: "EPL-2.0"},
+		{name: "exception", manifest: Manifest{Licenses: []License{{Name: "Classpath exception 2.0"}}}, wantUnresolved: []string{"Classpath exception 2.0"}},
+		{
+			name:           "all apply",
+			manifest:       Manifest{Licenses: []License{{Name: "MIT"}, {Name: "Foo"}, {Name: "EPL-2.0"}}},
+			wantExpression: "MIT AND EPL-2.0",
+			wantUnresolved: []string{"Foo"},
+		},
+		{name: "choice", manifest: Manifest{Licenses: []License{{Name: "MIT"}, {Name: "EPL-2.0"}}, Choice: true}, wantExpression: "MIT OR EPL-2.0"},
+	}
+	index := NewLicenseIndex(licenseMap)
+	for _, tt := range tests {
+		d := tt.manifest.Resolve(index)
+		got := ""
+		if d.Expression != nil {
+			got = d.Expression.String()
+		}
+		if got != tt.wantExpression {
+			t.Errorf("%v: expected expression %q got %q", tt.name, tt.wantExpression, got)
+		}
+		if d := cmp.Diff(tt.wantUnresolved, d.Unresolved); d != "" {
+			t.Errorf("%v: Didn't get expected unresolved values: (-want, +got): %v", tt.name, d)
+		}
+	}
+}
+
+func TestDeclaration_Compare(t *testing.T) {
+	t.Parallel()
+	mustParse := func(s string) *expression.Expression {
+		e, err := expression.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	d := &Declaration{Expression: mustParse("MIT AND Apache-2.0"), Unresolved: []string{"Foo"}}
+	d.Compare([]Detected{{File: "LICENSE", Expression: mustParse("MIT AND ISC")}, {File: "NOTICE"}})
+	want := []string{
+		`declared "Foo" is not a known license`,
+		"declared Apache-2.0 is not detected in a license file",
+		"detected ISC in LICENSE is not declared",
+	}
+	if diff := cmp.Diff(want, d.Discrepancies); diff != "" {
+		t.Errorf("Didn't get expected discrepancies: (-want, +got): %v", diff)
+	}
+
+	// without detected licenses, only the unresolved values are discrepancies
+	d.Compare(nil)
+	if diff := cmp.Diff(want[:1], d.Discrepancies); diff != "" {
+		t.Errorf("Didn't get expected discrepancies: (-want, +got): %v", diff)
+	}
+}
@@ -0,0 +1,301 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// gemspecLicenseRE finds the license or licenses assigned in a gemspec (e.g. s.licenses = ["MIT"])
+	gemspecLicenseRE = regexp.MustCompile(`\.licen[cs]es?\s*=\s*(\[[^\]]*\]|%w[\[(][^\])]*[\])]|"[^"]*"|'[^']*')`)
+	quotedRE         = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+)
+
+// seeLicenseIn is an npm license which refers to a file, whose text is detected instead
+const seeLicenseIn = "see license in"
+
+// parsePackageJSON reads the license, or the deprecated licenses, of an npm package.json
+func parsePackageJSON(content []byte) (*Manifest, error) {
+	var pkg struct {
+		License  json.RawMessage   `json:"license"`
+		Licenses []json.RawMessage `json:"licenses"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	for _, raw := range append([]json.RawMessage{pkg.License}, pkg.Licenses...) {
+		if l, ok := npmLicense(raw); ok && !strings.HasPrefix(strings.ToLower(l.Name), seeLicenseIn) {
+			m.Licenses = append(m.Licenses, l)
+		}
+	}
+	return m, nil
+}
+
+// npmLicense reads a license string, or a {"type", "url"} object
+func npmLicense(raw json.RawMessage) (License, bool) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return License{Name: strings.TrimSpace(s)}, strings.TrimSpace(s) != ""
+	}
+	var o struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}
+	if err := json.Unmarshal(raw, &o); err == nil {
+		l := License{Name: strings.TrimSpace(o.Type), URL: strings.TrimSpace(o.URL)}
+		return l, l.Name != "" || l.URL != ""
+	}
+	return License{}, false
+}
+
+// parseComposerJSON reads the license of a PHP composer.json, where several licenses are alternatives
+func parseComposerJSON(content []byte) (*Manifest, error) {
+	var pkg struct {
+		License json.RawMessage `json:"license"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, err
+	}
+	var names []string
+	var name string
+	if err := json.Unmarshal(pkg.License, &name); err == nil {
+		names = append(names, name)
+	} else {
+		_ = json.Unmarshal(pkg.License, &names)
+	}
+	return &Manifest{Licenses: namedLicenses(names), Choice: true}, nil
+}
+
+// parsePOM reads the licenses of a Maven pom.xml
+func parsePOM(content []byte) (*Manifest, error) {
+	var pom struct {
+		Licenses []struct {
+			Name string `xml:"name"`
+			URL  string `xml:"url"`
+		} `xml:"licenses>license"`
+	}
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	for _, l := range pom.Licenses {
+		if l := (License{Name: strings.TrimSpace(l.Name), URL: strings.TrimSpace(l.URL)}); l.Name != "" || l.URL != "" {
+			m.Licenses = append(m.Licenses, l)
+		}
+	}
+	return m, nil
+}
+
+// parseNuspec reads the license expression, or the deprecated license URL, of a NuGet .nuspec
+func parseNuspec(content []byte) (*Manifest, error) {
+	var nuspec struct {
+		License struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"metadata>license"`
+		LicenseURL string `xml:"metadata>licenseUrl"`
+	}
+	if err := xml.Unmarshal(content, &nuspec); err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if value := strings.TrimSpace(nuspec.License.Value); value != "" && nuspec.License.Type == "expression" {
+		m.Licenses = append(m.Licenses, License{Name: value})
+	} else if u := strings.TrimSpace(nuspec.LicenseURL); u != "" && !strings.Contains(u, "aka.ms/deprecateLicenseUrl") {
+		m.Licenses = append(m.Licenses, License{URL: u})
+	}
+	return m, nil
+}
+
+// parseCargoToml reads the license expression of a Rust Cargo.toml, where "/" is the deprecated form of OR
+func parseCargoToml(content []byte) (*Manifest, error) {
+	var cargo struct {
+		Package struct {
+			License string `toml:"license"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal(content, &cargo); err != nil {
+		return nil, err
+	}
+	expression := strings.ReplaceAll(cargo.Package.License, "/", " OR ")
+	return &Manifest{Licenses: namedLicenses([]string{expression})}, nil
+}
+
+// parseGemspec reads the licenses assigned in a Ruby .gemspec
+func parseGemspec(content []byte) (*Manifest, error) {
+	var names []string
+	for _, match := range gemspecLicenseRE.FindAllSubmatch(content, -1) {
+		value := string(match[1])
+		if strings.HasPrefix(value, "%w") {
+			names = append(names, strings.Fields(value[3:len(value)-1])...)
+			continue
+		}
+		for _, q := range quotedRE.FindAllStringSubmatch(value, -1) {
+			names = append(names, q[1]+q[2])
+		}
+	}
+	return &Manifest{Licenses: namedLicenses(names)}, nil
+}
+
+// parseGemMetadata reads the licenses of the YAML metadata of a gem
+func parseGemMetadata(content []byte) (*Manifest, error) {
+	var gem struct {
+		Licenses []string `yaml:"licenses"`
+	}
+	if err := yaml.Unmarshal(content, &gem); err != nil {
+		return nil, err
+	}
+	return &Manifest{Licenses: namedLicenses(gem.Licenses)}, nil
+}
+
+// parsePKGInfo reads the License-Expression, or else the License, or else the license classifiers of Python
+// PKG-INFO or METADATA headers. A License which spans several lines is the text of the license, which is detected instead.
+func parsePKGInfo(content []byte) (*Manifest, error) {
+	var expression, license, key string
+	var classifiers []string
+	multiline := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break // the headers end at the first blank line
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			// a continuation line of the previous header
+			multiline = multiline || key == "license"
+			continue
+		}
+		var value string
+		key, value, _ = strings.Cut(line, ":")
+		key, value = strings.ToLower(key), strings.TrimSpace(value)
+		switch key {
+		case "license-expression":
+			expression = value
+		case "license":
+			license = value
+		case "classifier":
+			classifiers = append(classifiers, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pythonManifest(expression, license, multiline, classifiers), nil
+}
+
+// parseSetupCfg reads the license, or else the license classifiers, of the [metadata] of a Python setup.cfg
+func parseSetupCfg(content []byte) (*Manifest, error) {
+	var license, key string
+	var classifiers []string
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			section, key = strings.Trim(trimmed, "[]"), ""
+			continue
+		case section != "metadata":
+			continue
+		case line[0] == ' ' || line[0] == '\t':
+			// a continuation line of a multi-line value
+			if key == "classifiers" {
+				classifiers = append(classifiers, trimmed)
+			}
+			continue
+		}
+		var value string
+		key, value, _ = strings.Cut(trimmed, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "license":
+			license = value
+		case "classifiers":
+			if value != "" {
+				classifiers = append(classifiers, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pythonManifest("", license, false, classifiers), nil
+}
+
+// parsePyprojectToml reads the license, or else the license classifiers, of the [project] or [tool.poetry] of a Python pyproject.toml
+func parsePyprojectToml(content []byte) (*Manifest, error) {
+	type project struct {
+		License     interface{} `toml:"license"`
+		Classifiers []string    `toml:"classifiers"`
+	}
+	var pyproject struct {
+		Project project `toml:"project"`
+		Tool    struct {
+			Poetry project `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(content, &pyproject); err != nil {
+		return nil, err
+	}
+	for _, p := range []project{pyproject.Project, pyproject.Tool.Poetry} {
+		var expression, license string
+		switch l := p.License.(type) {
+		case string:
+			expression = l
+		case map[string]interface{}:
+			// the license text, or a license file whose text is detected instead
+			license, _ = l["text"].(string)
+		}
+		if m := pythonManifest(expression, license, strings.Contains(strings.TrimSpace(license), "\n"), p.Classifiers); len(m.Licenses) > 0 {
+			return m, nil
+		}
+	}
+	return &Manifest{}, nil
+}
+
+// pythonManifest returns the license expression, or else the single-line license, or else the licenses of the
+// "License :: ..." classifiers (e.g. "License :: OSI Approved :: MIT License")
+func pythonManifest(expression, license string, multiline bool, classifiers []string) *Manifest {
+	if expression != "" {
+		return &Manifest{Licenses: namedLicenses([]string{expression})}
+	}
+	if license != "" && !multiline && !strings.EqualFold(license, "UNKNOWN") {
+		return &Manifest{Licenses: namedLicenses([]string{license})}
+	}
+	var names []string
+	for _, c := range classifiers {
+		parts := strings.Split(c, "::")
+		if len(parts) < 2 || strings.TrimSpace(parts[0]) != "License" {
+			continue
+		}
+		// "License :: OSI Approved" alone names no license
+		if name := strings.TrimSpace(parts[len(parts)-1]); name != "OSI Approved" {
+			names = append(names, name)
+		}
+	}
+	return &Manifest{Licenses: namedLicenses(names)}
+}
+
+// namedLicenses returns a license for each name which is not empty
+func namedLicenses(names []string) []License {
+	var ret []License
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			ret = append(ret, License{Name: name})
+		}
+	}
+	return ret
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+var (
+	// licenseURLRE finds the license ID in an SPDX or OSI license URL key (e.g. https://spdx.org/licenses/MIT.html)
+	licenseURLRE = regexp.MustCompile(`^(?:spdx\.org/licenses|opensource\.org/licenses)/([^/]+)$`)
+	// urlExtensions are the file extensions of license URLs, which are ignored
+	urlExtensions = []string{".html", ".htm", ".json", ".txt", ".php"}
+)
+
+// Declaration is the license expression resolved from the licenses declared by a manifest
+type Declaration struct {
+	Type Type
+	// Values are the declared names (or SPDX expressions), or the URLs of the licenses without a name
+	Values []string
+	// Expression is the license expression of the values which were resolved, or nil if none was
+	Expression *expression.Expression
+	// Unresolved are the values which are not an SPDX expression, or the name, alias, or URL of a license in the library
+	Unresolved []string
+	// Discrepancies explain the unresolved values, and the differences from the detected licenses (see Compare)
+	Discrepancies []string
+}
+
+// Detected is the license expression detected in the text of a license file
+type Detected struct {
+	File       string
+	Expression *expression.Expression
+}
+
+// Resolve resolves each declared license to a license expression with the IDs, names, aliases, and URLs of the licenses of the index.
+// A declared name is first parsed as an SPDX expression, and a license with a name which is not resolved is resolved by its URL.
+func (m *Manifest) Resolve(index *LicenseIndex) *Declaration {
+	d := &Declaration{Type: m.Type}
+	var resolved []*expression.Expression
+	for _, l := range m.Licenses {
+		value := l.Name
+		if value == "" {
+			value = l.URL
+		}
+		d.Values = append(d.Values, value)
+		if e := index.resolve(l); e != nil {
+			resolved = append(resolved, e)
+		} else {
+			d.Unresolved = append(d.Unresolved, value)
+		}
+	}
+	op := expression.And
+	if m.Choice {
+		op = expression.Or
+	}
+	d.Expression = expression.Join(op, resolved...)
+	d.Compare(nil)
+	return d
+}
+
+// Compare sets the Discrepancies of the declaration: the unresolved values, and when licenses were detected in license files,
+// the declared licenses which were not detected and the detected licenses which were not declared
+func (d *Declaration) Compare(detected []Detected) {
+	d.Discrepancies = nil
+	for _, value := range d.Unresolved {
+		d.Discrepancies = append(d.Discrepancies, fmt.Sprintf("declared %q is not a known license", value))
+	}
+	if len(detected) == 0 {
+		return
+	}
+
+	declaredIDs := make(map[string]bool)
+	if d.Expression != nil {
+		for _, l := range d.Expression.Licenses() {
+			declaredIDs[l.License] = true
+		}
+	}
+	detectedIDs := make(map[string]bool)
+	var undeclared []string
+	for _, det := range detected {
+		if det.Expression == nil {
+			continue
+		}
+		for _, l := range det.Expression.Licenses() {
+			if !detectedIDs[l.License] && !declaredIDs[l.License] {
+				undeclared = append(undeclared, fmt.Sprintf("detected %v in %v is not declared", l.License, det.File))
+			}
+			detectedIDs[l.License] = true
+		}
+	}
+	var ids []string
+	for id := range declaredIDs {
+		if !detectedIDs[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		d.Discrepancies = append(d.Discrepancies, fmt.Sprintf("declared %v is not detected in a license file", id))
+	}
+	d.Discrepancies = append(d.Discrepancies, undeclared...)
+}
+
+// LicenseIndex finds license IDs by name, alias, or URL.
+// Build it once for a license map and use it to resolve every manifest.
+type LicenseIndex struct {
+	licenseMap licenses.LicenseMap
+	// names and urls are the keys (see nameKey and urlKey) of the IDs, names, aliases, and URLs of the licenses
+	names map[string]string
+	urls  map[string]string
+}
+
+// NewLicenseIndex returns the index of the licenses of the map, without the exceptions
+func NewLicenseIndex(licenseMap licenses.LicenseMap) *LicenseIndex {
+	index := &LicenseIndex{licenseMap: licenseMap, names: make(map[string]string), urls: make(map[string]string)}
+	// the first license with a key keeps it, so IDs come first and deprecated licenses last
+	ids := make([]string, 0, len(licenseMap))
+	for id, l := range licenseMap {
+		if !l.LicenseInfo.SPDXException {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		di, dj := licenseMap[ids[i]].LicenseInfo.IsDeprecated, licenseMap[ids[j]].LicenseInfo.IsDeprecated
+		if di != dj {
+			return dj
+		}
+		return ids[i] < ids[j]
+	})
+	add := func(m map[string]string, key, id string) {
+		if _, ok := m[key]; key != "" && !ok {
+			m[key] = id
+		}
+	}
+	for _, id := range ids {
+		add(index.names, nameKey(id), id)
+	}
+	for _, id := range ids {
+		l := licenseMap[id]
+		add(index.names, nameKey(l.LicenseInfo.Name), id)
+		for _, a := range l.Aliases {
+			add(index.names, nameKey(a), id)
+		}
+		for _, u := range l.URLs {
+			add(index.urls, urlKey(u), id)
+		}
+	}
+	return index
+}
+
+// resolve returns the license expression of the declared license, or nil
+func (index *LicenseIndex) resolve(l License) *expression.Expression {
+	if l.Name != "" {
+		if e, err := expression.Parse(l.Name); err == nil && e.Validate(index.licenseMap) == nil {
+			return e
+		}
+		if id, ok := index.names[nameKey(l.Name)]; ok {
+			return &expression.Expression{License: id}
+		}
+		if id, ok := index.names[nameKey(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(l.Name)), "the "))]; ok {
+			return &expression.Expression{License: id}
+		}
+	}
+	for _, u := range []string{l.URL, l.Name} {
+		if !strings.Contains(u, "/") {
+			continue
+		}
+		key := urlKey(u)
+		if id, ok := index.urls[key]; ok {
+			return &expression.Expression{License: id}
+		}
+		if m := licenseURLRE.FindStringSubmatch(key); m != nil {
+			if id, ok := index.names[nameKey(m[1])]; ok {
+				return &expression.Expression{License: id}
+			}
+		}
+	}
+	return nil
+}
+
+// nameKey is the lower case name with its spaces collapsed
+func nameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// urlKey is the lower case URL without its scheme, "www.", a trailing slash, or a file extension (e.g. LICENSE-2.0.txt)
+func urlKey(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	if _, after, found := strings.Cut(u, "://"); found {
+		u = after
+	}
+	u = strings.TrimSuffix(strings.TrimPrefix(u, "www."), "/")
+	for _, ext := range urlExtensions {
+		u = strings.TrimSuffix(u, ext)
+	}
+	return u
+}
@@ -0,0 +1,335 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/filter"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+// Decision is the classification of a license by a policy
+type Decision string
+
+const (
+	Allow  Decision = "allow"
+	Review Decision = "review"
+	Deny   Decision = "deny"
+)
+
+// severity orders the decisions from the most to the least permissive
+var severity = map[Decision]int{Allow: 0, Review: 1, Deny: 2}
+
+var (
+	// ErrDenied is returned by Check when a denied license was found
+	ErrDenied = errors.New("denied licenses were found")
+	// ErrReview is returned by Check when a license which needs review, and no denied license, was found
+	ErrReview = errors.New("licenses which need review were found")
+)
+
+// Policy classifies the licenses found by a scan as allowed, denied, or needing review
+type Policy struct {
+	// Default is the decision for a license which no rule matches (Review when empty)
+	Default Decision `yaml:"default" json:"default"`
+	// Rules are tried in order, and the first rule which matches a license decides it
+	Rules []Rule `yaml:"rules" json:"rules"`
+	// Overrides have rules which are tried before Rules, and a default, for some paths
+	Overrides []Override `yaml:"overrides" json:"overrides"`
+}
+
+// Rule matches a license when all of its conditions match. A rule without conditions matches every license.
+type Rule struct {
+	// Name is shown with the violations of the rule
+	Name     string   `yaml:"name" json:"name"`
+	Decision Decision `yaml:"decision" json:"decision"`
+	// IDs are glob patterns (e.g. "GPL-*") of which one must match the license ID, or the license with its exception (e.g. "GPL-2.0-only WITH Classpath-exception-2.0")
+	IDs []string `yaml:"ids" json:"ids"`
+	// Families are license families (e.g. "GPL") of which one must be the family of the license, without case
+	Families []string `yaml:"families" json:"families"`
+	// OSIApproved, FSFLibre, and Deprecated must be the flags of the license when they are set
+	OSIApproved *bool `yaml:"osiApproved" json:"osiApproved"`
+	FSFLibre    *bool `yaml:"fsfLibre" json:"fsfLibre"`
+	Deprecated  *bool `yaml:"deprecated" json:"deprecated"`
+}
+
+// Override has the rules which are tried first, and the default, for the files which match one of its paths
+type Override struct {
+	// Paths are glob patterns of paths relative to the scanned directory, where "**" matches any number of directories.
+	// A pattern without a slash matches a name at any depth, and a pattern which matches a directory matches the files under it.
+	Paths   []string `yaml:"paths" json:"paths"`
+	Default Decision `yaml:"default" json:"default"`
+	Rules   []Rule   `yaml:"rules" json:"rules"`
+}
+
+// Violation is a license of a file which is not allowed
+type Violation struct {
+	// File is relative to the base path given to Evaluate
+	File string
+	// License is a license of the license expression of the file, with its exception if any (e.g. "GPL-2.0-only WITH Classpath-exception-2.0")
+	License  string
+	Decision Decision
+	// Rule is the name (or position) of the rule which decided the license, or "default"
+	Rule string
+}
+
+// Load reads and validates a policy file in YAML (or JSON) format
+func Load(filePath string) (*Policy, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("policy %v: %w", filePath, err)
+	}
+	return p, nil
+}
+
+// Read reads and validates a policy in YAML (or JSON) format. Unknown fields are errors.
+func Read(r io.Reader) (*Policy, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var p Policy
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate returns an error for an unknown decision or an invalid pattern
+func (p *Policy) Validate() error {
+	if err := validateDecision(p.Default, "default"); err != nil {
+		return err
+	}
+	if err := validateRules(p.Rules, "rules"); err != nil {
+		return err
+	}
+	for i, o := range p.Overrides {
+		where := fmt.Sprintf("overrides[%v]", i)
+		if len(o.Paths) == 0 {
+			return fmt.Errorf("%v: no paths", where)
+		}
+		if err := filter.ValidatePatterns(o.Paths); err != nil {
+			return fmt.Errorf("%v: paths: %w", where, err)
+		}
+		if err := validateDecision(o.Default, where+".default"); err != nil {
+			return err
+		}
+		if err := validateRules(o.Rules, where+".rules"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateDecision(d Decision, where string) error {
+	if _, ok := severity[d]; d != "" && !ok {
+		return fmt.Errorf("%v: invalid decision %q (expected allow, review, or deny)", where, d)
+	}
+	return nil
+}
+
+func validateRules(rules []Rule, where string) error {
+	for i, r := range rules {
+		where := fmt.Sprintf("%v[%v]", where, i)
+		if r.Decision == "" {
+			return fmt.Errorf("%v: no decision", where)
+		}
+		if err := validateDecision(r.Decision, where); err != nil {
+			return err
+		}
+		for _, id := range r.IDs {
+			if _, err := path.Match(id, ""); err != nil {
+				return fmt.Errorf("%v: invalid ID pattern %q: %w", where, id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the violations of the license expression of each result, sorted by file.
+// A license is decided by the first matching rule. An OR expression has the most permissive decision of its
+// operands (so a choice of an allowed license is allowed), and an AND expression has the least permissive.
+func (p *Policy) Evaluate(licenseMap licenses.LicenseMap, basePath string, results ...identifier.IdentifierResults) []Violation {
+	var violations []Violation
+	for _, result := range results {
+		if result.LicenseExpression == nil {
+			continue
+		}
+		file := relativePath(basePath, result.File)
+		rules, def := p.rulesFor(file)
+		e := evaluator{licenseMap: licenseMap, rules: rules, def: def, file: file}
+		_, v := e.evaluate(result.LicenseExpression)
+		violations = append(violations, v...)
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].File < violations[j].File })
+	return violations
+}
+
+// Check returns ErrDenied if a violation is a denied license, ErrReview if a violation needs review, or nil
+func Check(violations []Violation) error {
+	counts := Count(violations)
+	if counts[Deny] > 0 {
+		return fmt.Errorf("%w: %v denied, %v to review", ErrDenied, counts[Deny], counts[Review])
+	}
+	if counts[Review] > 0 {
+		return fmt.Errorf("%w: %v to review", ErrReview, counts[Review])
+	}
+	return nil
+}
+
+// Count returns the number of violations with each decision
+func Count(violations []Violation) map[Decision]int {
+	counts := make(map[Decision]int)
+	for _, v := range violations {
+		counts[v.Decision]++
+	}
+	return counts
+}
+
+// rulesFor returns the rules of the overrides which match the file followed by the policy rules, and the default decision
+func (p *Policy) rulesFor(file string) ([]Rule, Decision) {
+	var rules []Rule
+	def := Decision("")
+	for _, o := range p.Overrides {
+		if matchAnyPath(o.Paths, file) {
+			rules = append(rules, o.Rules...)
+			if def == "" {
+				def = o.Default
+			}
+		}
+	}
+	rules = append(rules, p.Rules...)
+	if def == "" {
+		def = p.Default
+	}
+	if def == "" {
+		def = Review
+	}
+	return rules, def
+}
+
+// relativePath returns the slash-separated path of the file relative to the base path, or the file if it is not under it
+func relativePath(basePath, file string) string {
+	if basePath != "" {
+		if rel, err := filepath.Rel(basePath, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+func matchAnyPath(patterns []string, file string) bool {
+	for _, p := range patterns {
+		p = strings.TrimSuffix(p, "/")
+		if !strings.Contains(p, "/") {
+			p = "**/" + p
+		}
+		p = strings.TrimPrefix(p, "/")
+		if filter.Match(p, file) || filter.Match(p+"/**", file) {
+			return true
+		}
+	}
+	return false
+}
+
+type evaluator struct {
+	licenseMap licenses.LicenseMap
+	rules      []Rule
+	def        Decision
+	file       string
+}
+
+// evaluate returns the decision of the expression and the violations which cause it
+func (e evaluator) evaluate(x *expression.Expression) (Decision, []Violation) {
+	if x.IsLicense() {
+		d, rule := e.decide(x)
+		if d == Allow {
+			return d, nil
+		}
+		return d, []Violation{{File: e.file, License: x.String(), Decision: d, Rule: rule}}
+	}
+
+	var decision Decision
+	decisions := make([]Decision, len(x.Operands))
+	violations := make([][]Violation, len(x.Operands))
+	for i, o := range x.Operands {
+		decisions[i], violations[i] = e.evaluate(o)
+		if i == 0 || (x.Operator == expression.Or) == (severity[decisions[i]] < severity[decision]) {
+			decision = decisions[i]
+		}
+	}
+	// the violations of an OR are those of the operands which could be chosen, and of an AND those of every operand
+	var ret []Violation
+	for i := range x.Operands {
+		if x.Operator != expression.Or || decisions[i] == decision {
+			ret = append(ret, violations[i]...)
+		}
+	}
+	return decision, ret
+}
+
+// decide returns the decision of the first rule which matches the license, and the name of the rule
+func (e evaluator) decide(x *expression.Expression) (Decision, string) {
+	lic, known := e.licenseMap[x.License]
+	for i, r := range e.rules {
+		if r.matches(x, lic.LicenseInfo, known) {
+			if r.Name != "" {
+				return r.Decision, r.Name
+			}
+			return r.Decision, fmt.Sprintf("rule %v", i+1)
+		}
+	}
+	return e.def, "default"
+}
+
+func (r Rule) matches(x *expression.Expression, info licenses.LicenseInfo, known bool) bool {
+	if len(r.IDs) > 0 && !r.matchesID(x) {
+		return false
+	}
+	if len(r.Families) > 0 {
+		found := false
+		for _, f := range r.Families {
+			found = found || (known && strings.EqualFold(f, info.Family))
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, flag := range []struct {
+		want *bool
+		got  bool
+	}{{r.OSIApproved, info.OSIApproved}, {r.FSFLibre, info.IsFSFLibre}, {r.Deprecated, info.IsDeprecated}} {
+		if flag.want != nil && (!known || *flag.want != flag.got) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Rule) matchesID(x *expression.Expression) bool {
+	for _, pattern := range r.IDs {
+		for _, s := range []string{x.License, x.String()} {
+			if ok, _ := path.Match(pattern, s); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
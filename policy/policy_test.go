@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func TestPolicy_Evaluate(t *testing.T) {
+	t.Parallel()
+	p, err := Load("../testdata/policy/policy.yaml")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p.Rules = append(p.Rules,
+		Rule{Name: "not OSI approved", Decision: Review, OSIApproved: new(bool)},
+		Rule{Name: "custom family", Decision: Allow, Families: []string{"custom"}},
+	)
+	licenseMap := licenses.LicenseMap{
+		"MIT":                     {LicenseInfo: licenses.LicenseInfo{OSIApproved: true}},
+		"GPL-2.0-only":            {LicenseInfo: licenses.LicenseInfo{OSIApproved: true}},
+		"GPL-3.0-only":            {LicenseInfo: licenses.LicenseInfo{OSIApproved: true}},
+		"Classpath-exception-2.0": {LicenseInfo: licenses.LicenseInfo{SPDXException: true}},
+		"eCos-2.0":                {LicenseInfo: licenses.LicenseInfo{IsDeprecated: true}},
+		"JSON":                    {},
+		"LicenseRef-custom":       {LicenseInfo: licenses.LicenseInfo{OSIApproved: true, Family: "Custom"}},
+		"LicenseRef-other-custom": {LicenseInfo: licenses.LicenseInfo{OSIApproved: true, Family: "Other"}},
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		expression string
+		want       []Violation
+	}{
+		{name: "allowed", file: "src/LICENSE", expression: "MIT"},
+		{name: "denied", file: "src/a.c", expression: "GPL-3.0-only", want: []Violation{{File: "src/a.c", License: "GPL-3.0-only", Decision: Deny, Rule: "strong copyleft"}}},
+		{name: "choice of an allowed license", file: "src/b.c", expression: "MIT OR GPL-3.0-only"},
+		{name: "and", file: "src/c.c", expression: "MIT AND GPL-3.0-only AND JSON", want: []Violation{
+			{File: "src/c.c", License: "GPL-3.0-only", Decision: Deny, Rule: "strong copyleft"},
+			{File: "src/c.c", License: "JSON", Decision: Review, Rule: "not OSI approved"},
+		}},
+		{name: "choice of the least denied licenses", file: "src/d.c", expression: "GPL-3.0-only OR JSON", want: []Violation{
+			{File: "src/d.c", License: "JSON", Decision: Review, Rule: "not OSI approved"},
+		}},
+		{name: "exception", file: "src/e.java", expression: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{name: "deprecated", file: "src/f.c", expression: "eCos-2.0", want: []Violation{{File: "src/f.c", License: "eCos-2.0", Decision: Review, Rule: "deprecated"}}},
+		{name: "family", file: "src/g.c", expression: "LicenseRef-custom"},
+		{name: "default", file: "src/h.c", expression: "LicenseRef-other-custom", want: []Violation{{File: "src/h.c", License: "LicenseRef-other-custom", Decision: Review, Rule: "default"}}},
+		{name: "unknown license", file: "src/i.c", expression: "LicenseRef-unknown", want: []Violation{{File: "src/i.c", License: "LicenseRef-unknown", Decision: Review, Rule: "default"}}},
+		{name: "override dir", file: "test/a.c", expression: "GPL-3.0-only"},
+		{name: "override nested dir", file: "src/test/data/a.c", expression: "GPL-3.0-only"},
+		{name: "override glob", file: "src/a_test.go", expression: "GPL-3.0-only"},
+		{name: "not an override", file: "src/testing.c", expression: "GPL-3.0-only", want: []Violation{{File: "src/testing.c", License: "GPL-3.0-only", Decision: Deny, Rule: "strong copyleft"}}},
+		{name: "no license", file: "src/j.c"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := identifier.IdentifierResults{File: "/scan/" + tt.file}
+			if tt.expression != "" {
+				e, err := expression.Parse(tt.expression)
+				if err != nil {
+					t.Fatal(err)
+				}
+				result.LicenseExpression = e
+			}
+			got := p.Evaluate(licenseMap, "/scan", result)
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("Evaluate() Diff(-want +got) = %v", d)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	deny := Violation{File: "a", License: "GPL-3.0-only", Decision: Deny}
+	review := Violation{File: "b", License: "JSON", Decision: Review}
+	if err := Check(nil); err != nil {
+		t.Errorf("Check() error = %v, expected nil", err)
+	}
+	if err := Check([]Violation{review, deny}); !errors.Is(err, ErrDenied) {
+		t.Errorf("Check() error = %v, expected %v", err, ErrDenied)
+	}
+	if err := Check([]Violation{review}); !errors.Is(err, ErrReview) {
+		t.Errorf("Check() error = %v, expected %v", err, ErrReview)
+	}
+}
+
+func TestRead_invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr string
+	}{
+		{name: "default", policy: "default: maybe", wantErr: `default: invalid decision "maybe"`},
+		{name: "no decision", policy: "rules:\n  - ids: [MIT]", wantErr: "rules[0]: no decision"},
+		{name: "rule decision", policy: "rules:\n  - decision: block", wantErr: `rules[0]: invalid decision "block"`},
+		{name: "ID pattern", policy: "rules:\n  - decision: deny\n    ids: ['GPL-[']", wantErr: `rules[0]: invalid ID pattern "GPL-["`},
+		{name: "unknown field", policy: "rules:\n  - decision: deny\n    id: [MIT]", wantErr: "field id not found"},
+		{name: "override without paths", policy: "overrides:\n  - default: allow", wantErr: "overrides[0]: no paths"},
+		{name: "override rule", policy: `{"overrides": [{"paths": ["test"], "rules": [{"decision": "ok"}]}]}`, wantErr: `overrides[0].rules[0]: invalid decision "ok"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := Read(strings.NewReader(tt.policy)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Read() error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
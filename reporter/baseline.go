@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Changes of a file compared with a baseline
+const (
+	// ChangeAdded is a file which is not in the baseline
+	ChangeAdded = "added"
+	// ChangeRemoved is a file of the baseline which was not found
+	ChangeRemoved = "removed"
+	// ChangeRenamed is a file of the baseline (with the same content hash) which was renamed or moved
+	ChangeRenamed = "renamed"
+	// ChangeModified is a file of the baseline whose licenses or copyrights changed
+	ChangeModified = "modified"
+)
+
+// FileChange is a file whose licenses or copyrights differ from a baseline report, or which was renamed.
+// Files are relative to the base path of their report.
+type FileChange struct {
+	File        string `json:"file"`
+	Change      string `json:"change"`
+	RenamedFrom string `json:"renamedFrom,omitempty"`
+	// AddedLicenses and RemovedLicenses are the license IDs found only in the file or only in the baseline
+	AddedLicenses   []string `json:"addedLicenses,omitempty"`
+	RemovedLicenses []string `json:"removedLicenses,omitempty"`
+	// ChangedLicenses are the license IDs found in both, with other kinds, numbers, or confidences of matches
+	ChangedLicenses []string `json:"changedLicenses,omitempty"`
+	// AddedCopyrights and RemovedCopyrights are the copyright statements found only in the file or only in the baseline
+	AddedCopyrights   []string `json:"addedCopyrights,omitempty"`
+	RemovedCopyrights []string `json:"removedCopyrights,omitempty"`
+}
+
+// Introduces is true when a license or a copyright statement was added to the file
+func (c FileChange) Introduces() bool {
+	return len(c.AddedLicenses) > 0 || len(c.AddedCopyrights) > 0
+}
+
+// ReadReportFile reads a JSON report, such as a baseline written with --output json
+func ReadReportFile(filePath string) (Report, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return Report{}, err
+	}
+	defer f.Close()
+	report, err := ReadReport(f)
+	if err != nil {
+		return report, fmt.Errorf("report %v: %w", filePath, err)
+	}
+	return report, nil
+}
+
+// ReadReport reads a JSON report
+func ReadReport(r io.Reader) (Report, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return report, err
+	}
+	if report.SchemaVersion == "" || strings.Split(report.SchemaVersion, ".")[0] != strings.Split(SchemaVersion, ".")[0] {
+		return report, fmt.Errorf("unsupported schemaVersion %q (expected %v)", report.SchemaVersion, SchemaVersion)
+	}
+	return report, nil
+}
+
+// RelativeFile returns the slash-separated path of the file relative to the base path, or the file if it is not under it
+func RelativeFile(basePath, file string) string {
+	if basePath != "" {
+		if rel, err := filepath.Rel(basePath, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// Compare returns the changes of the files of a report from a baseline report, sorted by file.
+// Files are matched by their path relative to the base path of each report. A file whose path is not in the baseline is
+// matched by the SHA-256 hash of its content with a file of the baseline which is no longer found, as a rename or move.
+func Compare(baseline, current Report) []FileChange {
+	baseFiles := make(map[string]Result, len(baseline.Results))
+	var basePaths []string
+	for _, r := range baseline.Results {
+		file := RelativeFile(baseline.BasePath, r.File)
+		baseFiles[file] = r
+		basePaths = append(basePaths, file)
+	}
+	sort.Strings(basePaths)
+	currentFiles := make(map[string]Result, len(current.Results))
+	var currentPaths []string
+	for _, r := range current.Results {
+		file := RelativeFile(current.BasePath, r.File)
+		currentFiles[file] = r
+		currentPaths = append(currentPaths, file)
+	}
+	sort.Strings(currentPaths)
+
+	// the files of the baseline which are no longer found, by hash, which may have been renamed
+	renamed := make(map[string][]string)
+	for _, file := range basePaths {
+		if _, ok := currentFiles[file]; !ok && baseFiles[file].Hash.Sha256 != "" {
+			renamed[baseFiles[file].Hash.Sha256] = append(renamed[baseFiles[file].Hash.Sha256], file)
+		}
+	}
+
+	var changes []FileChange
+	matched := make(map[string]bool)
+	for _, file := range currentPaths {
+		r := currentFiles[file]
+		c := FileChange{File: file, Change: ChangeModified}
+		base, ok := baseFiles[file]
+		if !ok {
+			if candidates := renamed[r.Hash.Sha256]; r.Hash.Sha256 != "" && len(candidates) > 0 {
+				c.Change, c.RenamedFrom = ChangeRenamed, candidates[0]
+				renamed[r.Hash.Sha256] = candidates[1:]
+				base = baseFiles[c.RenamedFrom]
+				matched[c.RenamedFrom] = true
+			} else {
+				c.Change = ChangeAdded
+			}
+		} else {
+			matched[file] = true
+		}
+		c.compare(base, r)
+		if c.Change == ChangeRenamed || c.Introduces() || len(c.RemovedLicenses) > 0 || len(c.ChangedLicenses) > 0 || len(c.RemovedCopyrights) > 0 {
+			changes = append(changes, c)
+		}
+	}
+	for _, file := range basePaths {
+		if _, ok := currentFiles[file]; ok || matched[file] {
+			continue
+		}
+		c := FileChange{File: file, Change: ChangeRemoved}
+		c.compare(baseFiles[file], Result{})
+		if len(c.RemovedLicenses) > 0 || len(c.RemovedCopyrights) > 0 {
+			changes = append(changes, c)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].File < changes[j].File })
+	return changes
+}
+
+// compare sets the licenses and copyrights which were added, removed, or changed from the baseline result
+func (c *FileChange) compare(base, r Result) {
+	baseLicenses := make(map[string][]Match)
+	for _, l := range base.Licenses {
+		baseLicenses[l.ID] = l.Matches
+	}
+	for _, l := range r.Licenses {
+		if baseMatches, ok := baseLicenses[l.ID]; !ok {
+			c.AddedLicenses = append(c.AddedLicenses, l.ID)
+		} else if !sameMatches(baseMatches, l.Matches) {
+			c.ChangedLicenses = append(c.ChangedLicenses, l.ID)
+		}
+		delete(baseLicenses, l.ID)
+	}
+	for id := range baseLicenses {
+		c.RemovedLicenses = append(c.RemovedLicenses, id)
+	}
+	sort.Strings(c.RemovedLicenses)
+
+	baseCopyrights := copyrights(base)
+	currentCopyrights := copyrights(r)
+	for _, s := range currentCopyrights {
+		if !slices.Contains(baseCopyrights, s) {
+			c.AddedCopyrights = append(c.AddedCopyrights, s)
+		}
+	}
+	for _, s := range baseCopyrights {
+		if !slices.Contains(currentCopyrights, s) {
+			c.RemovedCopyrights = append(c.RemovedCopyrights, s)
+		}
+	}
+}
+
+// sameMatches is true when the matches have the same kinds and confidences, wherever they are in the file and whatever their coverage
+func sameMatches(a, b []Match) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Kind != b[i].Kind || a[i].Confidence != b[i].Confidence {
+			return false
+		}
+	}
+	return true
+}
+
+// copyrights returns the distinct copyright statements of a result, with spaces collapsed
+func copyrights(r Result) []string {
+	var ret []string
+	for _, m := range r.CopyrightStatements {
+		s := strings.Join(strings.Fields(m.Text), " ")
+		if !slices.Contains(ret, s) {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package reporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	mit := LicenseMatches{ID: "MIT", Matches: []Match{{Begins: 0, Ends: 100, Kind: "primary", Coverage: 1, Confidence: 1}}}
+	movedMIT := LicenseMatches{ID: "MIT", Matches: []Match{{Begins: 50, Ends: 150, Kind: "primary", Coverage: 0.5, Confidence: 1}}}
+	apache := LicenseMatches{ID: "Apache-2.0", Matches: []Match{{Begins: 0, Ends: 100, Kind: "primary", Coverage: 1, Confidence: 1}}}
+	partialApache := LicenseMatches{ID: "Apache-2.0", Matches: []Match{{Begins: 0, Ends: 100, Kind: "primary", Coverage: 1, Confidence: 0.9}}}
+	copyright := func(text string) []PatternMatch { return []PatternMatch{{Text: text}} }
+
+	baseline := Report{
+		SchemaVersion: SchemaVersion,
+		BasePath:      "/old/src",
+		Results: []Result{
+			{File: "/old/src/same.go", Hash: Hash{Sha256: "same"}, Licenses: []LicenseMatches{mit}, CopyrightStatements: copyright("Copyright 2020 Someone")},
+			{File: "/old/src/moved.go", Hash: Hash{Sha256: "moved"}, Licenses: []LicenseMatches{mit}},
+			{File: "/old/src/changed.go", Hash: Hash{Sha256: "changed"}, Licenses: []LicenseMatches{mit, apache}, CopyrightStatements: copyright("Copyright 2020 Someone")},
+			{File: "/old/src/removed.go", Hash: Hash{Sha256: "removed"}, Licenses: []LicenseMatches{apache}},
+			{File: "/old/src/empty.go", Hash: Hash{Sha256: "empty"}},
+		},
+	}
+	current := Report{
+		SchemaVersion: SchemaVersion,
+		BasePath:      "src",
+		Results: []Result{
+			{File: "src/same.go", Hash: Hash{Sha256: "same2"}, Licenses: []LicenseMatches{movedMIT}, CopyrightStatements: copyright("Copyright  2020\nSomeone")},
+			{File: "src/pkg/moved.go", Hash: Hash{Sha256: "moved"}, Licenses: []LicenseMatches{mit}},
+			{File: "src/changed.go", Hash: Hash{Sha256: "changed2"}, Licenses: []LicenseMatches{partialApache}, CopyrightStatements: copyright("Copyright 2023 Other")},
+			{File: "src/added.go", Hash: Hash{Sha256: "added"}, Licenses: []LicenseMatches{apache}},
+			{File: "src/new_empty.go", Hash: Hash{Sha256: "new_empty"}},
+		},
+	}
+
+	want := []FileChange{
+		{File: "added.go", Change: ChangeAdded, AddedLicenses: []string{"Apache-2.0"}},
+		{File: "changed.go", Change: ChangeModified, RemovedLicenses: []string{"MIT"}, ChangedLicenses: []string{"Apache-2.0"}, AddedCopyrights: []string{"Copyright 2023 Other"}, RemovedCopyrights: []string{"Copyright 2020 Someone"}},
+		{File: "pkg/moved.go", Change: ChangeRenamed, RenamedFrom: "moved.go"},
+		{File: "removed.go", Change: ChangeRemoved, RemovedLicenses: []string{"Apache-2.0"}},
+	}
+	got := Compare(baseline, current)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Didn't get expected changes: (-want, +got): %v", d)
+	}
+	for _, c := range got {
+		if c.Introduces() != (c.File == "added.go" || c.File == "changed.go") {
+			t.Errorf("%v: unexpected Introduces() %v", c.File, c.Introduces())
+		}
+	}
+}
+
+func TestReadReport(t *testing.T) {
+	t.Parallel()
+	if _, err := ReadReport(strings.NewReader(`{"schemaVersion": "1.0", "results": []}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, s := range []string{`{"results": []}`, `{"schemaVersion": "2.0"}`, `not json`} {
+		if _, err := ReadReport(strings.NewReader(s)); err == nil {
+			t.Errorf("%v: expected an error", s)
+		}
+	}
+}
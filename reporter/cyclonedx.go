@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package reporter
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+// OccurrencePropertyPrefix names the component properties which hold the offsets of license matches.
+// CycloneDX 1.4 evidence has no occurrences, so each match is recorded as a property named
+// "license-scanner:occurrence:<license ID>" with the value "<begins>-<ends>" (inclusive offsets in the original text).
+const OccurrencePropertyPrefix = "license-scanner:occurrence:"
+
+// Copyright properties hold the copyright holders of a file component: a "license-scanner:copyright:holder" property with the
+// name of each holder, and "license-scanner:copyright:years:<holder>" and "license-scanner:copyright:email:<holder>" properties
+// with its years (e.g. "2019-2021, 2023") and each of its emails.
+const (
+	CopyrightHolderProperty      = "license-scanner:copyright:holder"
+	CopyrightYearsPropertyPrefix = "license-scanner:copyright:years:"
+	CopyrightEmailPropertyPrefix = "license-scanner:copyright:email:"
+)
+
+// NewCycloneDXBOM creates a BOM with a file component for each scanned file.
+// Component hashes are the digests of the normalized text. The component license is the composed license expression,
+// and the licenses found and copyrights flagged are added as evidence, with the copyright holders as properties.
+func NewCycloneDXBOM(results []identifier.IdentifierResults, licenseLibrary *licenses.LicenseLibrary, options Options) *cyclonedx.BOM {
+	bom := cyclonedx.NewBOM()
+	bom.SerialNumber = newSerialNumber()
+	bom.Metadata = &cyclonedx.Metadata{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Tools: &[]cyclonedx.Tool{
+			{
+				Vendor:  "CycloneDX",
+				Name:    options.ToolName,
+				Version: options.ToolVersion,
+			},
+		},
+	}
+
+	sorted := make([]*identifier.IdentifierResults, 0, len(results))
+	for i := range results {
+		sorted = append(sorted, &results[i])
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].File < sorted[j].File
+	})
+
+	components := make([]cyclonedx.Component, 0, len(sorted))
+	for _, ir := range sorted {
+		components = append(components, newFileComponent(ir, licenseLibrary))
+	}
+	bom.Components = &components
+	return bom
+}
+
+func newFileComponent(ir *identifier.IdentifierResults, licenseLibrary *licenses.LicenseLibrary) cyclonedx.Component {
+	component := cyclonedx.Component{
+		BOMRef: ir.File,
+		Type:   cyclonedx.ComponentTypeFile,
+		Name:   ir.File,
+	}
+
+	var hashes []cyclonedx.Hash
+	if ir.Hash.Md5 != "" {
+		hashes = append(hashes, cyclonedx.Hash{Algorithm: cyclonedx.HashAlgoMD5, Value: ir.Hash.Md5})
+	}
+	if ir.Hash.Sha256 != "" {
+		hashes = append(hashes, cyclonedx.Hash{Algorithm: cyclonedx.HashAlgoSHA256, Value: ir.Hash.Sha256})
+	}
+	if ir.Hash.Sha512 != "" {
+		hashes = append(hashes, cyclonedx.Hash{Algorithm: cyclonedx.HashAlgoSHA512, Value: ir.Hash.Sha512})
+	}
+	if len(hashes) > 0 {
+		component.Hashes = &hashes
+	}
+
+	if ir.LicenseExpression != nil {
+		component.Licenses = &cyclonedx.Licenses{newCycloneDXExpressionChoice(ir.LicenseExpression, licenseLibrary)}
+	}
+
+	evidence := cyclonedx.Evidence{}
+	var properties []cyclonedx.Property
+	for _, lm := range newLicenseMatches(ir.Matches) {
+		if evidence.Licenses == nil {
+			evidence.Licenses = &cyclonedx.Licenses{}
+		}
+		*evidence.Licenses = append(*evidence.Licenses, NewCycloneDXLicenseChoice(lm.ID, licenseLibrary))
+		for _, m := range lm.Matches {
+			properties = append(properties, cyclonedx.Property{
+				Name:  OccurrencePropertyPrefix + lm.ID,
+				Value: fmt.Sprintf("%d-%d", m.Begins, m.Ends),
+			})
+		}
+	}
+
+	var copyrights []cyclonedx.Copyright
+	for _, c := range ir.CopyRightStatements {
+		if text := strings.TrimSpace(c.Text); text != "" {
+			copyrights = append(copyrights, cyclonedx.Copyright{Text: text})
+		}
+	}
+	if len(copyrights) > 0 {
+		evidence.Copyright = &copyrights
+	}
+	for _, h := range identifier.CopyrightHolders(*ir) {
+		properties = append(properties, cyclonedx.Property{Name: CopyrightHolderProperty, Value: h.Name})
+		if len(h.Years) > 0 {
+			properties = append(properties, cyclonedx.Property{Name: CopyrightYearsPropertyPrefix + h.Name, Value: formatYears(h.Years)})
+		}
+		for _, e := range h.Emails {
+			properties = append(properties, cyclonedx.Property{Name: CopyrightEmailPropertyPrefix + h.Name, Value: e})
+		}
+	}
+
+	if evidence.Licenses != nil || evidence.Copyright != nil {
+		component.Evidence = &evidence
+	}
+	if len(properties) > 0 {
+		component.Properties = &properties
+	}
+	return component
+}
+
+// formatYears returns the years and ranges of years separated by commas
+func formatYears(years []identifier.YearRange) string {
+	s := make([]string, 0, len(years))
+	for _, y := range years {
+		s = append(s, y.String())
+	}
+	return strings.Join(s, ", ")
+}
+
+// NewCycloneDXLicenseChoice returns a LicenseChoice for a license ID found by the identifier.
+// SPDX licenses use the ID, other licenses use the name, and mutated licenses (e.g. "X WITH Y") are expressions.
+func NewCycloneDXLicenseChoice(id string, licenseLibrary *licenses.LicenseLibrary) cyclonedx.LicenseChoice {
+	var lic licenses.License
+	var ok bool
+	if licenseLibrary != nil {
+		lic, ok = licenseLibrary.LicenseMap[id]
+	}
+	if !ok {
+		if strings.Contains(id, " WITH ") {
+			return cyclonedx.LicenseChoice{Expression: id}
+		}
+		return cyclonedx.LicenseChoice{License: &cyclonedx.License{Name: id}}
+	}
+
+	cl := &cyclonedx.License{}
+	if len(lic.LicenseInfo.URLs) > 0 {
+		cl.URL = lic.LicenseInfo.URLs[0] // the schema allows a single URL
+	}
+	if lic.SPDXLicenseID != "" {
+		cl.ID = lic.SPDXLicenseID
+	} else {
+		cl.Name = lic.GetID()
+	}
+	return cyclonedx.LicenseChoice{License: cl}
+}
+
+// newCycloneDXExpressionChoice returns a LicenseChoice for a license expression, which is a license if it is a single license ID
+func newCycloneDXExpressionChoice(e *expression.Expression, licenseLibrary *licenses.LicenseLibrary) cyclonedx.LicenseChoice {
+	if e.IsLicense() && !e.OrLater && e.Exception == "" {
+		return NewCycloneDXLicenseChoice(e.License, licenseLibrary)
+	}
+	return cyclonedx.LicenseChoice{Expression: e.String()}
+}
+
+// WriteCycloneDX writes the BOM as pretty-printed JSON or XML
+func WriteCycloneDX(w io.Writer, bom *cyclonedx.BOM, format cyclonedx.BOMFileFormat) error {
+	return cyclonedx.NewBOMEncoder(w, format).SetPretty(true).Encode(bom)
+}
+
+// newSerialNumber returns a random (version 4) UUID URN
+func newSerialNumber() string {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return ""
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/normalizer"
+)
+
+func testLicenseLibrary() *licenses.LicenseLibrary {
+	return &licenses.LicenseLibrary{
+		LicenseMap: licenses.LicenseMap{
+			"MIT": {
+				SPDXLicenseID: "MIT",
+				LicenseInfo: licenses.LicenseInfo{
+					Name: "MIT License",
+					URLs: []string{"https://opensource.org/licenses/MIT", "https://spdx.org/licenses/MIT.html"},
+				},
+			},
+			"Custom": {
+				LicenseInfo: licenses.LicenseInfo{Name: "Custom"},
+			},
+		},
+	}
+}
+
+func TestNewCycloneDXBOM(t *testing.T) {
+	t.Parallel()
+	results := []identifier.IdentifierResults{
+		{
+			File:              "src/main.go",
+			Matches:           map[string][]identifier.Match{"MIT": {{Begins: 3, Ends: 5}}},
+			LicenseExpression: &expression.Expression{License: "MIT"},
+		},
+		{
+			File: "LICENSE",
+			Matches: map[string][]identifier.Match{
+				"MIT":    {{Begins: 0, Ends: 100}},
+				"Custom": {{Begins: 101, Ends: 120}},
+			},
+			LicenseExpression: &expression.Expression{Operator: expression.And, Operands: []*expression.Expression{
+				{License: "MIT"}, {License: "Custom"},
+			}},
+			Hash:                normalizer.Digest{Md5: "md5", Sha256: "sha256", Sha512: "sha512"},
+			CopyRightStatements: []identifier.PatternMatch{{Text: "\nCopyright 2022 Someone  ", Begins: 1, Ends: 22}},
+			Copyrights:          identifier.ParseCopyrightStatements([]identifier.PatternMatch{{Text: "Copyright 2020-2022 Someone <someone@example.com>"}}),
+		},
+	}
+
+	bom := NewCycloneDXBOM(results, testLicenseLibrary(), Options{ToolName: "tool", ToolVersion: "1.2.3"})
+	if !strings.HasPrefix(bom.SerialNumber, "urn:uuid:") {
+		t.Errorf("expected urn:uuid serial number got %v", bom.SerialNumber)
+	}
+	if bom.Metadata == nil || (*bom.Metadata.Tools)[0].Name != "tool" {
+		t.Errorf("expected tool in metadata got %+v", bom.Metadata)
+	}
+
+	expected := []cyclonedx.Component{
+		{
+			BOMRef: "LICENSE",
+			Type:   cyclonedx.ComponentTypeFile,
+			Name:   "LICENSE",
+			Hashes: &[]cyclonedx.Hash{
+				{Algorithm: cyclonedx.HashAlgoMD5, Value: "md5"},
+				{Algorithm: cyclonedx.HashAlgoSHA256, Value: "sha256"},
+				{Algorithm: cyclonedx.HashAlgoSHA512, Value: "sha512"},
+			},
+			Licenses: &cyclonedx.Licenses{{Expression: "MIT AND Custom"}},
+			Properties: &[]cyclonedx.Property{
+				{Name: OccurrencePropertyPrefix + "Custom", Value: "101-120"},
+				{Name: OccurrencePropertyPrefix + "MIT", Value: "0-100"},
+				{Name: CopyrightHolderProperty, Value: "Someone"},
+				{Name: CopyrightYearsPropertyPrefix + "Someone", Value: "2020-2022"},
+				{Name: CopyrightEmailPropertyPrefix + "Someone", Value: "someone@example.com"},
+			},
+			Evidence: &cyclonedx.Evidence{
+				Licenses: &cyclonedx.Licenses{
+					{License: &cyclonedx.License{Name: "Custom"}},
+					{License: &cyclonedx.License{ID: "MIT", URL: "https://opensource.org/licenses/MIT"}},
+				},
+				Copyright: &[]cyclonedx.Copyright{{Text: "Copyright 2022 Someone"}},
+			},
+		},
+		{
+			BOMRef:     "src/main.go",
+			Type:       cyclonedx.ComponentTypeFile,
+			Name:       "src/main.go",
+			Licenses:   &cyclonedx.Licenses{{License: &cyclonedx.License{ID: "MIT", URL: "https://opensource.org/licenses/MIT"}}},
+			Properties: &[]cyclonedx.Property{{Name: OccurrencePropertyPrefix + "MIT", Value: "3-5"}},
+			Evidence: &cyclonedx.Evidence{
+				Licenses: &cyclonedx.Licenses{{License: &cyclonedx.License{ID: "MIT", URL: "https://opensource.org/licenses/MIT"}}},
+			},
+		},
+	}
+	if d := cmp.Diff(expected, *bom.Components); d != "" {
+		t.Errorf("NewCycloneDXBOM() didn't get expected components: (-want, +got): %v", d)
+	}
+}
+
+func TestNewCycloneDXLicenseChoice(t *testing.T) {
+	t.Parallel()
+	ll := testLicenseLibrary()
+	tests := []struct {
+		id       string
+		expected cyclonedx.LicenseChoice
+	}{
+		{id: "MIT", expected: cyclonedx.LicenseChoice{License: &cyclonedx.License{ID: "MIT", URL: "https://opensource.org/licenses/MIT"}}},
+		{id: "Custom", expected: cyclonedx.LicenseChoice{License: &cyclonedx.License{Name: "Custom"}}},
+		{id: "GPL-2.0-only WITH Classpath-exception-2.0", expected: cyclonedx.LicenseChoice{Expression: "GPL-2.0-only WITH Classpath-exception-2.0"}},
+		{id: "Unknown", expected: cyclonedx.LicenseChoice{License: &cyclonedx.License{Name: "Unknown"}}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.id, func(t *testing.T) {
+			t.Parallel()
+			if d := cmp.Diff(tt.expected, NewCycloneDXLicenseChoice(tt.id, ll)); d != "" {
+				t.Errorf("NewCycloneDXLicenseChoice() didn't get expected result: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	t.Parallel()
+	bom := NewCycloneDXBOM([]identifier.IdentifierResults{{File: "LICENSE"}}, testLicenseLibrary(), Options{ToolName: "tool"})
+	for _, format := range []cyclonedx.BOMFileFormat{cyclonedx.BOMFileFormatJSON, cyclonedx.BOMFileFormatXML} {
+		var b bytes.Buffer
+		if err := WriteCycloneDX(&b, bom, format); err != nil {
+			t.Fatalf("WriteCycloneDX() error = %v", err)
+		}
+		decoded := cyclonedx.NewBOM()
+		if err := cyclonedx.NewBOMDecoder(&b, format).Decode(decoded); err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if decoded.Components == nil || (*decoded.Components)[0].Name != "LICENSE" {
+			t.Errorf("expected LICENSE component after round trip got %+v", decoded.Components)
+		}
+	}
+}
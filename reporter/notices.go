@@ -0,0 +1,261 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package reporter
+
+import (
+	"embed"
+	"encoding/base64"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+// The formats of the notices templates
+const (
+	NoticesText     = "text"
+	NoticesMarkdown = "markdown"
+	NoticesHTML     = "html"
+)
+
+// NoticesFormats lists the formats of the notices templates
+var NoticesFormats = []string{NoticesText, NoticesMarkdown, NoticesHTML}
+
+var (
+	//go:embed templates/notices.*.tmpl
+	noticesTemplates     embed.FS
+	noticesTemplateFiles = map[string]string{
+		NoticesText:     "templates/notices.txt.tmpl",
+		NoticesMarkdown: "templates/notices.md.tmpl",
+		NoticesHTML:     "templates/notices.html.tmpl",
+	}
+	// noticeFileRE finds the NOTICE files (e.g. NOTICE, NOTICE.txt, NOTICE.md) whose contents are redistributed verbatim
+	noticeFileRE = regexp.MustCompile(`(?i)^NOTICE(?:\..*)?$`)
+	backticksRE  = regexp.MustCompile("`+")
+)
+
+// Notices is a third-party notices document for the files of a scan: each license found, with the copyright holders of
+// the files which have it and the license text, and the contents of the NOTICE files
+type Notices struct {
+	Tool        Tool
+	Licenses    []NoticeLicense
+	NoticeFiles []NoticeFile
+}
+
+// NoticeLicense is a license found by a scan, with the files which have it and their copyright holders
+type NoticeLicense struct {
+	ID      string
+	Name    string
+	Files   []string
+	Holders []CopyrightHolder
+	// Text is the license text from the license library, or else the longest full license text matched in the files, or "" if neither is available
+	Text string
+}
+
+// NoticeFile is the verbatim contents of one or more NOTICE files with the same contents
+type NoticeFile struct {
+	Files []string
+	Text  string
+}
+
+// NewNotices creates the notices of the scanned files, grouped by the licenses of their expressions (see identifier.ComposeExpression)
+// and sorted by ID, so the parts of a license with an exception are not listed on their own.
+// Files are relative to options.BasePath. Skipped files, which have neither matches nor text, are left out.
+func NewNotices(results []identifier.IdentifierResults, licenseLibrary *licenses.LicenseLibrary, options Options) *Notices {
+	notices := &Notices{Tool: Tool{Name: options.ToolName, Version: options.ToolVersion}}
+	byLicense := make(map[string][]identifier.IdentifierResults)
+	leaves := make(map[string]*expression.Expression)
+	var ids []string
+	for _, ir := range results {
+		ir.File = RelativeFile(options.BasePath, ir.File)
+		e := ir.LicenseExpression
+		if e == nil && len(ir.Matches) > 0 {
+			e = identifier.ComposeExpression(licenseLibrary.LicenseMap, identifier.ExpressionOrderText, ir)
+		}
+		if e != nil {
+			seen := make(map[string]bool)
+			for _, leaf := range e.Licenses() {
+				id := leaf.String()
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				if _, ok := byLicense[id]; !ok {
+					ids = append(ids, id)
+					leaves[id] = leaf
+				}
+				byLicense[id] = append(byLicense[id], ir)
+			}
+		}
+		if noticeFileRE.MatchString(path.Base(ir.File)) && strings.TrimSpace(ir.OriginalText) != "" {
+			notices.addNoticeFile(ir.File, ir.OriginalText)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		irs := byLicense[id]
+		sort.SliceStable(irs, func(i, j int) bool { return irs[i].File < irs[j].File })
+		l := NoticeLicense{ID: id, Holders: newCopyrightHolders(irs...)}
+		for _, ir := range irs {
+			l.Files = append(l.Files, ir.File)
+		}
+		leaf := leaves[id]
+		l.Name, l.Text = noticeNameAndText(leaf.License, irs, licenseLibrary)
+		if leaf.Exception != "" {
+			name, text := noticeNameAndText(leaf.Exception, irs, licenseLibrary)
+			l.Name += " WITH " + name
+			if l.Text != "" && text != "" {
+				l.Text += "\n\n" + text
+			}
+		}
+		notices.Licenses = append(notices.Licenses, l)
+	}
+	sort.SliceStable(notices.NoticeFiles, func(i, j int) bool { return notices.NoticeFiles[i].Files[0] < notices.NoticeFiles[j].Files[0] })
+	return notices
+}
+
+// noticeNameAndText returns the name and text of a license or exception ID from the license library,
+// or else the ID and the longest full license text matched in the files
+func noticeNameAndText(id string, results []identifier.IdentifierResults, licenseLibrary *licenses.LicenseLibrary) (name string, text string) {
+	name = id
+	if lic, ok := licenseLibrary.LicenseMap[id]; ok {
+		if lic.LicenseInfo.Name != "" {
+			name = lic.LicenseInfo.Name
+		}
+		text = licenseText(lic, licenseLibrary)
+	}
+	if text == "" {
+		text = primaryMatchedText(results, id)
+	}
+	return name, text
+}
+
+// addNoticeFile adds the contents of a NOTICE file, or adds the file to a NOTICE file with the same contents
+func (n *Notices) addNoticeFile(file, text string) {
+	for i := range n.NoticeFiles {
+		if n.NoticeFiles[i].Text == text {
+			n.NoticeFiles[i].Files = append(n.NoticeFiles[i].Files, file)
+			sort.Strings(n.NoticeFiles[i].Files)
+			return
+		}
+	}
+	n.NoticeFiles = append(n.NoticeFiles, NoticeFile{Files: []string{file}, Text: text})
+}
+
+// primaryMatchedText returns the original text of the longest primary match of a license in the files, which is a full license text
+func primaryMatchedText(results []identifier.IdentifierResults, id string) string {
+	var text string
+	for _, ir := range results {
+		for _, m := range ir.Matches[id] {
+			if m.Kind != identifier.MatchKindPrimary {
+				continue
+			}
+			if t := trimBlankLines(matchedText(ir.OriginalText, Match{Begins: m.Begins, Ends: m.Ends})); len(t) > len(text) {
+				text = t
+			}
+		}
+	}
+	return text
+}
+
+// licenseText returns the text of a license from the license library (decoded if it is base64),
+// or the SPDX text of an SPDX license, or "" if neither is available
+func licenseText(lic licenses.License, licenseLibrary *licenses.LicenseLibrary) string {
+	switch lic.Text.Encoding {
+	case "":
+		if lic.Text.Content != "" {
+			return lic.Text.Content
+		}
+	case "base64":
+		if b, err := base64.StdEncoding.DecodeString(lic.Text.Content); err == nil {
+			return string(b)
+		}
+	}
+	if lic.SPDXLicenseID != "" && licenseLibrary.Resources != nil {
+		if b, err := licenseLibrary.Resources.ReadSPDXTextFile(lic.SPDXLicenseID, lic.LicenseInfo.IsDeprecated); err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// DefaultNoticesTemplate returns the default template of a notices format
+func DefaultNoticesTemplate(format string) (string, error) {
+	f, ok := noticesTemplateFiles[format]
+	if !ok {
+		return "", fmt.Errorf("invalid notices format %q (expected one of: %v)", format, strings.Join(NoticesFormats, ", "))
+	}
+	b, err := noticesTemplates.ReadFile(f)
+	return string(b), err
+}
+
+// noticesFuncs are the functions of the notices templates
+var noticesFuncs = map[string]interface{}{
+	"join": strings.Join,
+	// years formats the years of a holder (e.g. "2019-2021, 2023")
+	"years": func(years []YearRange) string {
+		s := make([]string, 0, len(years))
+		for _, y := range years {
+			s = append(s, y.String())
+		}
+		return strings.Join(s, ", ")
+	},
+	// fence returns a Markdown code fence which is longer than any run of backticks in the text
+	"fence": func(text string) string {
+		n := 3
+		for _, run := range backticksRE.FindAllString(text, -1) {
+			if len(run) >= n {
+				n = len(run) + 1
+			}
+		}
+		return strings.Repeat("`", n)
+	},
+	"trim": trimBlankLines,
+}
+
+// trimBlankLines removes the blank lines and spaces at the end of a text, and the blank lines at its start, but not the indentation of its first line
+func trimBlankLines(text string) string {
+	text = strings.TrimRight(text, " \t\r\n")
+	leading := text[:len(text)-len(strings.TrimLeft(text, " \t\r\n"))]
+	if i := strings.LastIndex(leading, "\n"); i >= 0 {
+		text = text[i+1:]
+	}
+	return text
+}
+
+// WriteNotices writes the notices with a Go template, or with the default template of the format when tmpl is "".
+// HTML templates use html/template, which escapes the values written; the other formats use text/template.
+func WriteNotices(w io.Writer, notices *Notices, format, tmpl string) error {
+	if !slices.Contains(NoticesFormats, format) {
+		return fmt.Errorf("invalid notices format %q (expected one of: %v)", format, strings.Join(NoticesFormats, ", "))
+	}
+	if tmpl == "" {
+		var err error
+		if tmpl, err = DefaultNoticesTemplate(format); err != nil {
+			return err
+		}
+	}
+	if format == NoticesHTML {
+		t, err := htmltemplate.New("notices").Funcs(noticesFuncs).Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid notices template: %w", err)
+		}
+		return t.Execute(w, notices)
+	}
+	t, err := template.New("notices").Funcs(noticesFuncs).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("invalid notices template: %w", err)
+	}
+	return t.Execute(w, notices)
+}
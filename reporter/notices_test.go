@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/identifier"
+)
+
+func testNoticesResults() []identifier.IdentifierResults {
+	mitText := "Copyright 2021 Jane Doe\nPermission is hereby granted"
+	gplText := "GPL License\n\nClasspath exception\nCustom"
+	return []identifier.IdentifierResults{
+		{
+			File:         "/src/b/LICENSE",
+			OriginalText: mitText,
+			Matches:      map[string][]identifier.Match{"MIT": {{Begins: 24, Ends: len(mitText) - 1, Kind: identifier.MatchKindPrimary}}},
+			Copyrights:   identifier.ParseCopyrightStatements([]identifier.PatternMatch{{Text: "Copyright 2021 Jane Doe"}}),
+		},
+		{
+			File:         "/src/a/main.go",
+			OriginalText: "// SPDX-License-Identifier: MIT OR Custom",
+			Matches: map[string][]identifier.Match{
+				"MIT":    {{Begins: 28, Ends: 30, Kind: identifier.MatchKindTag}},
+				"Custom": {{Begins: 35, Ends: 40, Kind: identifier.MatchKindTag}},
+			},
+			Copyrights: identifier.ParseCopyrightStatements([]identifier.PatternMatch{{Text: "Copyright 2019 Jane Doe <jane@example.com>"}}),
+			LicenseExpression: &expression.Expression{Operator: expression.Or, Operands: []*expression.Expression{
+				{License: "MIT"}, {License: "Custom"},
+			}},
+		},
+		{
+			// the license and exception of a mutated license, and an alias, are not licenses of the expression on their own
+			File:         "/src/c/COPYING",
+			OriginalText: gplText,
+			Matches: map[string][]identifier.Match{
+				"GPL-2.0-only":                              {{Begins: 0, Ends: 11, Kind: identifier.MatchKindPrimary}},
+				"Classpath-exception-2.0":                   {{Begins: 13, Ends: 31, Kind: identifier.MatchKindPrimary}},
+				"GPL-2.0-only WITH Classpath-exception-2.0": {{Begins: 0, Ends: 31, Kind: identifier.MatchKindPrimary}},
+				"Custom": {{Begins: 33, Ends: 38, Kind: identifier.MatchKindAlias}},
+			},
+			Copyrights:        identifier.ParseCopyrightStatements([]identifier.PatternMatch{{Text: "Copyright 2018 John Doe"}}),
+			LicenseExpression: &expression.Expression{License: "GPL-2.0-only", Exception: "Classpath-exception-2.0"},
+		},
+		{File: "/src/a/NOTICE", OriginalText: "Foo\nCopyright 2020 Acme <b>Corp</b>\n", Matches: map[string][]identifier.Match{}},
+		{File: "/src/b/notice.txt", OriginalText: "Foo\nCopyright 2020 Acme <b>Corp</b>\n", Matches: map[string][]identifier.Match{}},
+		{File: "/src/big.bin", Status: identifier.StatusSkippedBinary, Matches: map[string][]identifier.Match{}},
+	}
+}
+
+func TestNewNotices(t *testing.T) {
+	t.Parallel()
+	got := NewNotices(testNoticesResults(), testLicenseLibrary(), Options{ToolName: "tool", ToolVersion: "1.2.3", BasePath: "/src"})
+	expected := &Notices{
+		Tool: Tool{Name: "tool", Version: "1.2.3"},
+		Licenses: []NoticeLicense{
+			{ID: "Custom", Name: "Custom", Files: []string{"a/main.go"}, Holders: []CopyrightHolder{
+				{Name: "Jane Doe", Years: []YearRange{{From: 2019, To: 2019}}, Emails: []string{"jane@example.com"}, Files: []string{"a/main.go"}},
+			}},
+			{ID: "GPL-2.0-only WITH Classpath-exception-2.0", Name: "GPL-2.0-only WITH Classpath-exception-2.0", Files: []string{"c/COPYING"}, Holders: []CopyrightHolder{
+				{Name: "John Doe", Years: []YearRange{{From: 2018, To: 2018}}, Files: []string{"c/COPYING"}},
+			}, Text: "GPL License\n\nClasspath exception"},
+			{ID: "MIT", Name: "MIT License", Files: []string{"a/main.go", "b/LICENSE"}, Holders: []CopyrightHolder{
+				{Name: "Jane Doe", Years: []YearRange{{From: 2019, To: 2019}, {From: 2021, To: 2021}}, Emails: []string{"jane@example.com"}, Files: []string{"a/main.go", "b/LICENSE"}},
+			}, Text: "Permission is hereby granted"},
+		},
+		NoticeFiles: []NoticeFile{{Files: []string{"a/NOTICE", "b/notice.txt"}, Text: "Foo\nCopyright 2020 Acme <b>Corp</b>\n"}},
+	}
+	if d := cmp.Diff(expected, got); d != "" {
+		t.Errorf("NewNotices() didn't get expected result: (-want, +got): %v", d)
+	}
+}
+
+func TestWriteNotices(t *testing.T) {
+	t.Parallel()
+	notices := NewNotices(testNoticesResults(), testLicenseLibrary(), Options{ToolName: "tool", BasePath: "/src"})
+	tests := []struct {
+		format   string
+		tmpl     string
+		expected []string
+	}{
+		{
+			format: NoticesText,
+			expected: []string{
+				"MIT (MIT License)\n",
+				"Files: a/main.go, b/LICENSE\n",
+				"    Jane Doe 2019, 2021 <jane@example.com>\n",
+				"\nPermission is hereby granted\n",
+				"The text of this license was not found.",
+				"NOTICE: a/NOTICE, b/notice.txt\n",
+				"Copyright 2020 Acme <b>Corp</b>\n",
+			},
+		},
+		{
+			format: NoticesMarkdown,
+			expected: []string{
+				"## MIT (MIT License)\n",
+				"* `b/LICENSE`\n",
+				"* Jane Doe 2019, 2021 <jane@example.com>\n",
+				"```text\nPermission is hereby granted\n```\n",
+				"### a/NOTICE, b/notice.txt\n",
+			},
+		},
+		{
+			format: NoticesHTML,
+			expected: []string{
+				"<h2>MIT (MIT License)</h2>",
+				"<li>Jane Doe 2019, 2021 &lt;jane@example.com&gt;</li>",
+				"<pre>Permission is hereby granted</pre>",
+				"Copyright 2020 Acme &lt;b&gt;Corp&lt;/b&gt;</pre>",
+			},
+		},
+		{
+			format:   NoticesHTML,
+			tmpl:     `{{range .Licenses}}<b>{{.ID}}</b> {{years (index .Holders 0).Years}}{{end}}{{range .NoticeFiles}}{{.Text}}{{end}}`,
+			expected: []string{"<b>Custom</b> 2019<b>GPL-2.0-only WITH Classpath-exception-2.0</b> 2018<b>MIT</b> 2019, 2021Foo\nCopyright 2020 Acme &lt;b&gt;Corp&lt;/b&gt;\n"},
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := WriteNotices(&b, notices, tt.format, tt.tmpl); err != nil {
+			t.Fatalf("WriteNotices(%v) error = %v", tt.format, err)
+		}
+		for _, expected := range tt.expected {
+			if !strings.Contains(b.String(), expected) {
+				t.Errorf("WriteNotices(%v) expected %q got\n%v", tt.format, expected, b.String())
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := WriteNotices(&b, notices, "pdf", ""); err == nil {
+		t.Error("WriteNotices() expected an error for an invalid format")
+	}
+	if err := WriteNotices(&b, notices, NoticesText, "{{.Bogus"); err == nil || !strings.Contains(err.Error(), "invalid notices template") {
+		t.Errorf("WriteNotices() expected an invalid template error got %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/CycloneDX/license-scanner/identifier"
+)
+
+// SchemaVersion is the version of the JSON report schema.
+// Fields may be added in minor versions. Removing or changing the meaning of a field requires a major version bump.
+const SchemaVersion = "1.10"
+
+// Options holds the settings used to build a Report
+type Options struct {
+	// ToolName and ToolVersion identify the scanner which produced the report
+	ToolName    string
+	ToolVersion string
+	// SPDXVersion is the SPDX license list version of the license library used for the scan
+	SPDXVersion string
+	// IncludeNormalizedText adds the normalized text of each scanned input to the report
+	IncludeNormalizedText bool
+	// DocumentName names the scanned file or directory in SPDX documents
+	DocumentName string
+	// BasePath is the directory which SPDX file names, and the files of a baseline comparison, are relative to
+	BasePath string
+	// Baseline is a previous report which the results are compared with, if any
+	Baseline *Report
+}
+
+// Report is the versioned, machine-readable form of a scan
+type Report struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Tool          Tool   `json:"tool"`
+	SPDXVersion   string `json:"spdxLicenseListVersion,omitempty"`
+	// BasePath is the scanned directory (or the directory of the scanned file)
+	BasePath string   `json:"basePath,omitempty"`
+	Results  []Result `json:"results"`
+	// CopyrightHolders are the holders of the copyright statements of all the results, merged by name
+	CopyrightHolders []CopyrightHolder `json:"copyrightHolders,omitempty"`
+	// Changes are the changes from the baseline report, when one was given
+	Changes []FileChange `json:"changes,omitempty"`
+}
+
+// Tool identifies the scanner which produced the report
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Result holds the scan results for a single file or text input
+type Result struct {
+	File                     string               `json:"file,omitempty"`
+	Status                   string               `json:"status,omitempty"`
+	Hash                     Hash                 `json:"hash"`
+	Licenses                 []LicenseMatches     `json:"licenses"`
+	LicenseExpression        string               `json:"licenseExpression,omitempty"`
+	Blocks                   []Block              `json:"blocks,omitempty"`
+	CopyrightStatements      []PatternMatch       `json:"copyrightStatements,omitempty"`
+	Copyrights               []CopyrightStatement `json:"copyrights,omitempty"`
+	KeywordMatches           []PatternMatch       `json:"keywordMatches,omitempty"`
+	Keywords                 []KeywordMatch       `json:"keywords,omitempty"`
+	AcceptablePatternMatches []PatternMatch       `json:"acceptablePatternMatches,omitempty"`
+	NearMiss                 *NearMiss            `json:"nearMiss,omitempty"`
+	LicenseTags              []LicenseTag         `json:"licenseTags,omitempty"`
+	InvalidLicenseTags       []InvalidLicenseTag  `json:"invalidLicenseTags,omitempty"`
+	Declared                 *Declared            `json:"declared,omitempty"`
+	Notes                    string               `json:"notes,omitempty"`
+	NormalizedText           string               `json:"normalizedText,omitempty"`
+}
+
+// Hash holds the digests of the normalized text
+type Hash struct {
+	Md5    string `json:"md5,omitempty"`
+	Sha256 string `json:"sha256,omitempty"`
+	Sha512 string `json:"sha512,omitempty"`
+}
+
+// LicenseMatches holds every match found for one license ID
+type LicenseMatches struct {
+	ID      string  `json:"id"`
+	Matches []Match `json:"matches"`
+}
+
+// Match holds the offsets of a license match in the original text (Ends is inclusive),
+// the kind of pattern which matched, the fraction of the text covered, and a confidence score (0-1)
+type Match struct {
+	Begins     int     `json:"begins"`
+	Ends       int     `json:"ends"`
+	Kind       string  `json:"kind,omitempty"`
+	Coverage   float64 `json:"coverage"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Block is a segment of the original text with the IDs or labels which matched it
+type Block struct {
+	Text    string   `json:"text"`
+	Matches []string `json:"matches,omitempty"`
+}
+
+// PatternMatch is text found by a copyright, keyword, or acceptable pattern (Ends is inclusive)
+type PatternMatch struct {
+	Text   string `json:"text"`
+	Begins int    `json:"begins"`
+	Ends   int    `json:"ends"`
+}
+
+// KeywordMatch is text found by a keyword rule, with the name and severity of the rule (Ends is inclusive)
+type KeywordMatch struct {
+	Text     string `json:"text"`
+	Begins   int    `json:"begins"`
+	Ends     int    `json:"ends"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+}
+
+// CopyrightStatement is a copyright statement with its holder, years, emails, and "All rights reserved" marker (Ends is inclusive)
+type CopyrightStatement struct {
+	Text              string      `json:"text"`
+	Begins            int         `json:"begins"`
+	Ends              int         `json:"ends"`
+	Holder            string      `json:"holder,omitempty"`
+	Years             []YearRange `json:"years,omitempty"`
+	Emails            []string    `json:"emails,omitempty"`
+	AllRightsReserved bool        `json:"allRightsReserved,omitempty"`
+}
+
+// YearRange is an inclusive range of years, or a year when from and to are the same
+type YearRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+func (y YearRange) String() string {
+	return identifier.YearRange{From: y.From, To: y.To}.String()
+}
+
+// CopyrightHolder is a copyright holder with the years, emails, and files of its statements
+type CopyrightHolder struct {
+	Name   string      `json:"name"`
+	Years  []YearRange `json:"years,omitempty"`
+	Emails []string    `json:"emails,omitempty"`
+	Files  []string    `json:"files"`
+}
+
+// LicenseTag is the license expression of an SPDX-License-Identifier tag, with its offsets in the original text (Ends is inclusive)
+type LicenseTag struct {
+	Expression string `json:"expression"`
+	Begins     int    `json:"begins"`
+	Ends       int    `json:"ends"`
+}
+
+// InvalidLicenseTag is an SPDX-License-Identifier tag which could not be parsed or validated, with the reason
+type InvalidLicenseTag struct {
+	Value  string `json:"value"`
+	Begins int    `json:"begins"`
+	Ends   int    `json:"ends"`
+	Error  string `json:"error"`
+}
+
+// Declared is the license declared by a package manifest, next to the licenses detected in its text
+type Declared struct {
+	Manifest      string   `json:"manifest"`
+	Values        []string `json:"values"`
+	Expression    string   `json:"expression,omitempty"`
+	Unresolved    []string `json:"unresolved,omitempty"`
+	Discrepancies []string `json:"discrepancies,omitempty"`
+}
+
+// NearMiss is the closest license when no license text matched, with the differences from its template
+type NearMiss struct {
+	ID          string       `json:"id"`
+	Similarity  float64      `json:"similarity"`
+	Differences []Difference `json:"differences"`
+}
+
+// Difference is text added to or removed from a license template.
+// Begins and Ends (inclusive) are the offsets of the added text in the original text, or -1 when text was only removed.
+type Difference struct {
+	Begins   int    `json:"begins"`
+	Ends     int    `json:"ends"`
+	Expected string `json:"expected,omitempty"`
+}
+
+// NewReport converts identifier results into a Report with results sorted by file and licenses sorted by ID
+func NewReport(results []identifier.IdentifierResults, options Options) Report {
+	report := Report{
+		SchemaVersion: SchemaVersion,
+		Tool: Tool{
+			Name:    options.ToolName,
+			Version: options.ToolVersion,
+		},
+		SPDXVersion: options.SPDXVersion,
+		BasePath:    options.BasePath,
+		Results:     make([]Result, 0, len(results)),
+	}
+
+	for i := range results {
+		report.Results = append(report.Results, NewResult(&results[i], options))
+	}
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		return report.Results[i].File < report.Results[j].File
+	})
+	report.CopyrightHolders = newCopyrightHolders(results...)
+	if options.Baseline != nil {
+		report.Changes = Compare(*options.Baseline, report)
+	}
+	return report
+}
+
+// NewResult converts the identifier results for a single input into a Result
+func NewResult(ir *identifier.IdentifierResults, options Options) Result {
+	result := Result{
+		File:   ir.File,
+		Status: string(ir.Status),
+		Hash: Hash{
+			Md5:    ir.Hash.Md5,
+			Sha256: ir.Hash.Sha256,
+			Sha512: ir.Hash.Sha512,
+		},
+		Licenses:                 newLicenseMatches(ir.Matches),
+		CopyrightStatements:      newPatternMatches(ir.CopyRightStatements),
+		KeywordMatches:           newPatternMatches(ir.KeywordMatches),
+		AcceptablePatternMatches: newPatternMatches(ir.AcceptablePatternMatches),
+		Notes:                    ir.Notes,
+	}
+	if ir.LicenseExpression != nil {
+		result.LicenseExpression = ir.LicenseExpression.String()
+	}
+	for _, b := range ir.Blocks {
+		result.Blocks = append(result.Blocks, Block{Text: b.Text, Matches: b.Matches})
+	}
+	if ir.NearMiss != nil {
+		result.NearMiss = &NearMiss{ID: ir.NearMiss.LicenseId, Similarity: ir.NearMiss.Similarity, Differences: []Difference{}}
+		for _, d := range ir.NearMiss.Differences {
+			result.NearMiss.Differences = append(result.NearMiss.Differences, Difference{Begins: d.Begins, Ends: d.Ends, Expected: d.Expected})
+		}
+	}
+	for _, c := range ir.Copyrights {
+		result.Copyrights = append(result.Copyrights, CopyrightStatement{
+			Text: c.Text, Begins: c.Begins, Ends: c.Ends, Holder: c.Holder, Years: newYearRanges(c.Years), Emails: c.Emails, AllRightsReserved: c.AllRightsReserved,
+		})
+	}
+	for _, k := range ir.Keywords {
+		result.Keywords = append(result.Keywords, KeywordMatch{Text: k.Text, Begins: k.Begins, Ends: k.Ends, Rule: k.Rule, Severity: k.Severity})
+	}
+	for _, t := range ir.LicenseTags {
+		result.LicenseTags = append(result.LicenseTags, LicenseTag{Expression: t.Expression.String(), Begins: t.Begins, Ends: t.Ends})
+	}
+	for _, t := range ir.InvalidLicenseTags {
+		result.InvalidLicenseTags = append(result.InvalidLicenseTags, InvalidLicenseTag{Value: t.Value, Begins: t.Begins, Ends: t.Ends, Error: t.Error})
+	}
+	if d := ir.Declared; d != nil {
+		result.Declared = &Declared{Manifest: string(d.Type), Values: d.Values, Unresolved: d.Unresolved, Discrepancies: d.Discrepancies}
+		if d.Expression != nil {
+			result.Declared.Expression = d.Expression.String()
+		}
+	}
+	if options.IncludeNormalizedText {
+		result.NormalizedText = ir.NormalizedText
+	}
+	return result
+}
+
+func newLicenseMatches(matches map[string][]identifier.Match) []LicenseMatches {
+	ids := make([]string, 0, len(matches))
+	for id := range matches {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	ret := make([]LicenseMatches, 0, len(ids))
+	for _, id := range ids {
+		lm := LicenseMatches{ID: id, Matches: []Match{}}
+		var prev identifier.Match
+		for i, m := range matches[id] {
+			if i > 0 && m.Begins == prev.Begins && m.Ends == prev.Ends {
+				continue // skip consecutive duplicates
+			}
+			lm.Matches = append(lm.Matches, Match{Begins: m.Begins, Ends: m.Ends, Kind: string(m.Kind), Coverage: m.Coverage, Confidence: m.Confidence})
+			prev = m
+		}
+		ret = append(ret, lm)
+	}
+	return ret
+}
+
+func newPatternMatches(pms []identifier.PatternMatch) []PatternMatch {
+	var ret []PatternMatch
+	for _, pm := range pms {
+		ret = append(ret, PatternMatch{Text: pm.Text, Begins: pm.Begins, Ends: pm.Ends})
+	}
+	return ret
+}
+
+// newCopyrightHolders returns the holders of the copyright statements of the results, merged by name
+func newCopyrightHolders(results ...identifier.IdentifierResults) []CopyrightHolder {
+	var ret []CopyrightHolder
+	for _, h := range identifier.CopyrightHolders(results...) {
+		ret = append(ret, CopyrightHolder{Name: h.Name, Years: newYearRanges(h.Years), Emails: h.Emails, Files: h.Files})
+	}
+	return ret
+}
+
+func newYearRanges(years []identifier.YearRange) []YearRange {
+	var ret []YearRange
+	for _, y := range years {
+		ret = append(ret, YearRange{From: y.From, To: y.To})
+	}
+	return ret
+}
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/manifest"
+	"github.com/CycloneDX/license-scanner/normalizer"
+)
+
+func TestNewReport(t *testing.T) {
+	t.Parallel()
+	results := []identifier.IdentifierResults{
+		{
+			File: "z/LICENSE",
+			Matches: map[string][]identifier.Match{
+				"MIT":        {{Begins: 0, Ends: 10}, {Begins: 0, Ends: 10}, {Begins: 20, Ends: 30}},
+				"Apache-2.0": {{Begins: 40, Ends: 50, Kind: identifier.MatchKindPrimary, Coverage: 0.25, Confidence: 1}},
+			},
+			LicenseExpression:   mustParse(t, "MIT AND Apache-2.0"),
+			Blocks:              []identifier.Block{{Text: "some text", Matches: []string{"MIT"}}},
+			Hash:                normalizer.Digest{Md5: "md5", Sha256: "sha256", Sha512: "sha512"},
+			CopyRightStatements: []identifier.PatternMatch{{Text: "Copyright 2022 Someone", Begins: 1, Ends: 22}},
+			Copyrights:          identifier.ParseCopyrightStatements([]identifier.PatternMatch{{Text: "Copyright 2022 Someone", Begins: 1, Ends: 22}}),
+			NormalizedText:      "normalized",
+		},
+		{
+			File: "a/README",
+			NearMiss: &identifier.NearMiss{
+				LicenseId:   "MIT",
+				Similarity:  0.9,
+				Differences: []identifier.Difference{{Begins: 3, Ends: 9}, {Begins: -1, Ends: -1, Expected: "without restriction"}},
+			},
+			LicenseTags:        []identifier.LicenseTag{{Begins: 30, Ends: 46, Expression: mustParse(t, "MIT OR Apache-2.0")}},
+			InvalidLicenseTags: []identifier.InvalidLicenseTag{{Begins: 60, Ends: 67, Value: "NOPE-1.0", Error: `unknown license ID "NOPE-1.0"`}},
+			KeywordMatches:     []identifier.PatternMatch{{Text: "export control", Begins: 5, Ends: 18}},
+			Keywords: []identifier.KeywordMatch{
+				{PatternMatch: identifier.PatternMatch{Text: "export control", Begins: 5, Ends: 18}, Rule: "export", Severity: identifier.SeverityWarning},
+			},
+		},
+		{
+			File: "m/package.json",
+			Declared: &manifest.Declaration{
+				Type:          manifest.NPM,
+				Values:        []string{"MIT", "Foo"},
+				Expression:    mustParse(t, "MIT"),
+				Unresolved:    []string{"Foo"},
+				Discrepancies: []string{`declared "Foo" is not a known license`},
+			},
+		},
+	}
+
+	got := NewReport(results, Options{ToolName: "tool", ToolVersion: "1.2.3", SPDXVersion: "3.21"})
+	expected := Report{
+		SchemaVersion: SchemaVersion,
+		Tool:          Tool{Name: "tool", Version: "1.2.3"},
+		SPDXVersion:   "3.21",
+		Results: []Result{
+			{
+				File:     "a/README",
+				Licenses: []LicenseMatches{},
+				NearMiss: &NearMiss{
+					ID:          "MIT",
+					Similarity:  0.9,
+					Differences: []Difference{{Begins: 3, Ends: 9}, {Begins: -1, Ends: -1, Expected: "without restriction"}},
+				},
+				LicenseTags:        []LicenseTag{{Expression: "MIT OR Apache-2.0", Begins: 30, Ends: 46}},
+				InvalidLicenseTags: []InvalidLicenseTag{{Value: "NOPE-1.0", Begins: 60, Ends: 67, Error: `unknown license ID "NOPE-1.0"`}},
+				KeywordMatches:     []PatternMatch{{Text: "export control", Begins: 5, Ends: 18}},
+				Keywords:           []KeywordMatch{{Text: "export control", Begins: 5, Ends: 18, Rule: "export", Severity: "warning"}},
+			},
+			{
+				File:     "m/package.json",
+				Licenses: []LicenseMatches{},
+				Declared: &Declared{
+					Manifest:      "npm",
+					Values:        []string{"MIT", "Foo"},
+					Expression:    "MIT",
+					Unresolved:    []string{"Foo"},
+					Discrepancies: []string{`declared "Foo" is not a known license`},
+				},
+			},
+			{
+				File: "z/LICENSE",
+				Hash: Hash{Md5: "md5", Sha256: "sha256", Sha512: "sha512"},
+				Licenses: []LicenseMatches{
+					{ID: "Apache-2.0", Matches: []Match{{Begins: 40, Ends: 50, Kind: "primary", Coverage: 0.25, Confidence: 1}}},
+					{ID: "MIT", Matches: []Match{{Begins: 0, Ends: 10}, {Begins: 20, Ends: 30}}},
+				},
+				LicenseExpression:   "MIT AND Apache-2.0",
+				Blocks:              []Block{{Text: "some text", Matches: []string{"MIT"}}},
+				CopyrightStatements: []PatternMatch{{Text: "Copyright 2022 Someone", Begins: 1, Ends: 22}},
+				Copyrights:          []CopyrightStatement{{Text: "Copyright 2022 Someone", Begins: 1, Ends: 22, Holder: "Someone", Years: []YearRange{{From: 2022, To: 2022}}}},
+			},
+		},
+		CopyrightHolders: []CopyrightHolder{{Name: "Someone", Years: []YearRange{{From: 2022, To: 2022}}, Files: []string{"z/LICENSE"}}},
+	}
+	if d := cmp.Diff(expected, got); d != "" {
+		t.Errorf("NewReport() didn't get expected result: (-want, +got): %v", d)
+	}
+
+	withNormalized := NewReport(results, Options{IncludeNormalizedText: true})
+	if withNormalized.Results[2].NormalizedText != "normalized" {
+		t.Errorf("NewReport() expected normalized text with IncludeNormalizedText got %q", withNormalized.Results[2].NormalizedText)
+	}
+}
+
+func mustParse(t *testing.T, s string) *expression.Expression {
+	e, err := expression.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+	report := NewReport([]identifier.IdentifierResults{
+		{File: "LICENSE", Matches: map[string][]identifier.Match{"MIT": {{Begins: 3, Ends: 7}}}},
+	}, Options{ToolName: "tool", ToolVersion: "1.2.3"})
+
+	var b bytes.Buffer
+	if err := WriteJSON(&b, report); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+	}
+	if got["schemaVersion"] != SchemaVersion {
+		t.Errorf("expected schemaVersion %v got %v", SchemaVersion, got["schemaVersion"])
+	}
+
+	var roundTrip Report
+	if err := json.Unmarshal(b.Bytes(), &roundTrip); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if d := cmp.Diff(report, roundTrip); d != "" {
+		t.Errorf("round trip didn't get expected result: (-want, +got): %v", d)
+	}
+}
+
+func TestLicenseTagReports(t *testing.T) {
+	t.Parallel()
+	ll := testLicenseLibrary()
+	ll.LicenseMap["GPL-2.0-or-later"] = licenses.License{SPDXLicenseID: "GPL-2.0-or-later", LicenseInfo: licenses.LicenseInfo{Name: "GNU General Public License v2.0 or later"}}
+	ll.LicenseMap["LLVM-exception"] = licenses.License{SPDXLicenseID: "LLVM-exception", LicenseInfo: licenses.LicenseInfo{Name: "LLVM Exception", SPDXException: true}}
+	ir, err := identifier.IdentifyLicensesInString("// SPDX-License-Identifier: GPL-2.0-or-later+ OR MIT WITH LLVM-exception OR LicenseRef-foo\n", identifier.Options{}, ll)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInString() error = %v", err)
+	}
+	ir.File = "main.go"
+	results := []identifier.IdentifierResults{ir}
+
+	if got := NewReport(results, Options{}).Results[0].LicenseTags; len(got) != 1 || got[0].Expression != "GPL-2.0-or-later+ OR MIT WITH LLVM-exception OR LicenseRef-foo" {
+		t.Errorf("NewReport() expected the tag with its + and exception got %+v", got)
+	}
+
+	doc := NewSPDXDocument(results, ll, Options{})
+	if d := cmp.Diff([]string{"GPL-2.0-or-later", "LicenseRef-foo", "MIT"}, doc.Files[0].LicenseInfoInFiles); d != "" {
+		t.Errorf("NewSPDXDocument() didn't get expected LicenseInfoInFiles (-want, +got): %v", d)
+	}
+	if d := cmp.Diff([]SPDXExtractedLicensingInfo{{LicenseID: "LicenseRef-foo", Name: "LicenseRef-foo", ExtractedText: "LicenseRef-foo"}}, doc.HasExtractedLicensingInfos); d != "" {
+		t.Errorf("NewSPDXDocument() didn't get expected extracted licensing info (-want, +got): %v", d)
+	}
+
+	bom := NewCycloneDXBOM(results, ll, Options{})
+	var evidence []string
+	for _, l := range *(*bom.Components)[0].Evidence.Licenses {
+		switch {
+		case l.License == nil:
+			evidence = append(evidence, "expression:"+l.Expression)
+		case l.License.ID != "":
+			evidence = append(evidence, l.License.ID)
+		default:
+			evidence = append(evidence, "name:"+l.License.Name)
+		}
+	}
+	if d := cmp.Diff([]string{"GPL-2.0-or-later", "name:LicenseRef-foo", "MIT"}, evidence); d != "" {
+		t.Errorf("NewCycloneDXBOM() didn't get expected evidence (-want, +got): %v", d)
+	}
+	if got := (*(*bom.Components)[0].Licenses)[0].Expression; got != "GPL-2.0-or-later+ OR MIT WITH LLVM-exception OR LicenseRef-foo" {
+		t.Errorf("NewCycloneDXBOM() expected the tag expression got %q", got)
+	}
+
+	for _, rule := range NewSARIFLog(results, ll, Options{}).Runs[0].Tool.Driver.Rules {
+		if rule.ID != "LicenseRef-foo" && rule.Properties == nil {
+			t.Errorf("NewSARIFLog() expected the license metadata of rule %v", rule.ID)
+		}
+	}
+
+	var names []string
+	for _, l := range NewNotices(results, ll, Options{}).Licenses {
+		names = append(names, l.Name)
+	}
+	if d := cmp.Diff([]string{"GNU General Public License v2.0 or later", "LicenseRef-foo", "MIT License WITH LLVM Exception"}, names); d != "" {
+		t.Errorf("NewNotices() didn't get expected license names (-want, +got): %v", d)
+	}
+}
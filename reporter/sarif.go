@@ -0,0 +1,285 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFLog is a SARIF 2.1.0 log with a single run
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun holds the rules (licenses) and results (matches) of a scan
+type SARIFRun struct {
+	Tool       SARIFTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the scanner and the license rules it reports
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool component which ran the scan
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes a license which was found
+type SARIFRule struct {
+	ID               string          `json:"id"`
+	Name             string          `json:"name,omitempty"`
+	ShortDescription SARIFMessage    `json:"shortDescription"`
+	HelpURI          string          `json:"helpUri,omitempty"`
+	Properties       *SARIFRuleProps `json:"properties,omitempty"`
+}
+
+// SARIFRuleProps holds the license metadata of a rule
+type SARIFRuleProps struct {
+	Tags          []string `json:"tags,omitempty"`
+	Family        string   `json:"family,omitempty"`
+	SPDXStandard  bool     `json:"spdxStandard"`
+	SPDXException bool     `json:"spdxException"`
+	OSIApproved   bool     `json:"osiApproved"`
+	FSFLibre      bool     `json:"fsfLibre"`
+	Deprecated    bool     `json:"deprecated"`
+}
+
+// SARIFMessage is a plain text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is a single license match. The rank (0-100) is the confidence of the match.
+type SARIFResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Rank       float64           `json:"rank,omitempty"`
+	Message    SARIFMessage      `json:"message"`
+	Locations  []SARIFLocation   `json:"locations"`
+	Properties *SARIFResultProps `json:"properties,omitempty"`
+}
+
+// SARIFResultProps holds the kind of a match and the fraction of the file it covers
+type SARIFResultProps struct {
+	Kind     string  `json:"kind"`
+	Coverage float64 `json:"coverage"`
+}
+
+// SARIFLocation is the location of a match
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is a region of a scanned file
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation identifies a scanned file
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFRegion is the region of a match. Lines and columns are 1-based and EndColumn is exclusive.
+type SARIFRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+	ByteOffset  int `json:"byteOffset"`
+	ByteLength  int `json:"byteLength"`
+}
+
+// NewSARIFLog creates a SARIF log with a result for each license match.
+// The rule ID of a result is the license ID, and the rule metadata comes from the license info.
+func NewSARIFLog(results []identifier.IdentifierResults, licenseLibrary *licenses.LicenseLibrary, options Options) *SARIFLog {
+	run := SARIFRun{
+		Tool: SARIFTool{
+			Driver: SARIFDriver{
+				Name:           options.ToolName,
+				Version:        options.ToolVersion,
+				InformationURI: "https://github.com/CycloneDX/license-scanner",
+				Rules:          []SARIFRule{},
+			},
+		},
+		ColumnKind: "unicodeCodePoints",
+		Results:    []SARIFResult{},
+	}
+
+	sorted := make([]*identifier.IdentifierResults, 0, len(results))
+	for i := range results {
+		sorted = append(sorted, &results[i])
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].File < sorted[j].File
+	})
+
+	// Rules are sorted by ID so that the rule indexes do not depend on the order of the files
+	ruleIndexes := make(map[string]int)
+	var ids []string
+	for _, ir := range sorted {
+		for id := range ir.Matches {
+			if _, ok := ruleIndexes[id]; !ok {
+				ruleIndexes[id] = 0
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		ruleIndexes[id] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSARIFRule(id, licenseLibrary))
+	}
+
+	for _, ir := range sorted {
+		uri := newArtifactURI(ir.File, options.BasePath)
+		lines := newLineIndex(ir.OriginalText)
+		for _, lm := range newLicenseMatches(ir.Matches) {
+			rule := run.Tool.Driver.Rules[ruleIndexes[lm.ID]]
+			for _, m := range lm.Matches {
+				result := SARIFResult{
+					RuleID:    lm.ID,
+					RuleIndex: ruleIndexes[lm.ID],
+					Level:     "note",
+					Message:   SARIFMessage{Text: fmt.Sprintf("License %v found", rule.ShortDescription.Text)},
+					Locations: []SARIFLocation{
+						{
+							PhysicalLocation: SARIFPhysicalLocation{
+								ArtifactLocation: SARIFArtifactLocation{URI: uri},
+								Region:           lines.region(m),
+							},
+						},
+					},
+				}
+				if m.Kind != "" {
+					result.Rank = math.Round(m.Confidence * 100)
+					result.Properties = &SARIFResultProps{Kind: m.Kind, Coverage: m.Coverage}
+				}
+				run.Results = append(run.Results, result)
+			}
+		}
+	}
+
+	return &SARIFLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []SARIFRun{run},
+	}
+}
+
+func newSARIFRule(id string, licenseLibrary *licenses.LicenseLibrary) SARIFRule {
+	rule := SARIFRule{ID: id, Name: id, ShortDescription: SARIFMessage{Text: id}}
+	if licenseLibrary == nil {
+		return rule
+	}
+	lic, ok := licenseLibrary.LicenseMap[id]
+	if !ok {
+		return rule
+	}
+
+	info := lic.LicenseInfo
+	if info.Name != "" {
+		rule.Name = info.Name
+		rule.ShortDescription.Text = fmt.Sprintf("%v (%v)", info.Name, id)
+	}
+	if len(info.URLs) > 0 {
+		rule.HelpURI = info.URLs[0]
+	}
+	rule.Properties = &SARIFRuleProps{
+		Tags:          []string{"license"},
+		Family:        info.Family,
+		SPDXStandard:  info.SPDXStandard,
+		SPDXException: info.SPDXException,
+		OSIApproved:   info.OSIApproved,
+		FSFLibre:      info.IsFSFLibre,
+		Deprecated:    info.IsDeprecated,
+	}
+	return rule
+}
+
+// newArtifactURI returns a URI relative to the base path, or a file URI for an absolute path outside of it
+func newArtifactURI(file string, basePath string) string {
+	if basePath != "" {
+		if rel, err := filepath.Rel(basePath, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	u := url.URL{Path: filepath.ToSlash(file)}
+	if filepath.IsAbs(file) {
+		u.Scheme = "file"
+	}
+	return u.String()
+}
+
+// lineIndex converts byte offsets in a text into lines and columns
+type lineIndex struct {
+	text       string
+	lineStarts []int
+}
+
+func newLineIndex(text string) lineIndex {
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return lineIndex{text: text, lineStarts: lineStarts}
+}
+
+// position returns the 1-based line and column (in code points) of a byte offset
+func (li lineIndex) position(offset int) (int, int) {
+	line := sort.Search(len(li.lineStarts), func(i int) bool { return li.lineStarts[i] > offset }) - 1
+	return line + 1, utf8.RuneCountInString(li.text[li.lineStarts[line]:offset]) + 1
+}
+
+// region returns the SARIF region of a match (Ends is inclusive), or nil if the offsets are outside the text
+func (li lineIndex) region(m Match) *SARIFRegion {
+	if m.Begins < 0 || m.Ends < m.Begins || m.Ends >= len(li.text) {
+		return nil
+	}
+	startLine, startColumn := li.position(m.Begins)
+	endLine, endColumn := li.position(m.Ends)
+	return &SARIFRegion{
+		StartLine:   startLine,
+		StartColumn: startColumn,
+		EndLine:     endLine,
+		EndColumn:   endColumn + 1,
+		ByteOffset:  m.Begins,
+		ByteLength:  m.Ends - m.Begins + 1,
+	}
+}
+
+// WriteSARIF writes the log as indented JSON
+func WriteSARIF(w io.Writer, log *SARIFLog) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/identifier"
+)
+
+func TestNewSARIFLog(t *testing.T) {
+	t.Parallel()
+	ll := testLicenseLibrary()
+	mit := ll.LicenseMap["MIT"]
+	mit.LicenseInfo.Family = "MIT"
+	mit.LicenseInfo.SPDXStandard = true
+	mit.LicenseInfo.OSIApproved = true
+	ll.LicenseMap["MIT"] = mit
+
+	results := []identifier.IdentifierResults{
+		{
+			File:         "src/z/LICENSE",
+			OriginalText: "Copyright © 2022\nMIT License\n",
+			Matches: map[string][]identifier.Match{
+				"MIT": {{Begins: 18, Ends: 28}},
+			},
+		},
+		{
+			File:         "src/a.go",
+			OriginalText: "// ©\n// Custom\n// MIT",
+			Matches: map[string][]identifier.Match{
+				"MIT":    {{Begins: 19, Ends: 21, Kind: identifier.MatchKindAlias, Coverage: 0.1, Confidence: 0.3}},
+				"Custom": {{Begins: 3, Ends: 14}},
+			},
+		},
+	}
+
+	log := NewSARIFLog(results, ll, Options{ToolName: "tool", ToolVersion: "1.2.3", BasePath: "src"})
+	if log.Version != SARIFVersion || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log %+v", log)
+	}
+	run := log.Runs[0]
+
+	expectedRules := []SARIFRule{
+		{
+			ID:               "Custom",
+			Name:             "Custom",
+			ShortDescription: SARIFMessage{Text: "Custom (Custom)"},
+			Properties:       &SARIFRuleProps{Tags: []string{"license"}},
+		},
+		{
+			ID:               "MIT",
+			Name:             "MIT License",
+			ShortDescription: SARIFMessage{Text: "MIT License (MIT)"},
+			HelpURI:          "https://opensource.org/licenses/MIT",
+			Properties:       &SARIFRuleProps{Tags: []string{"license"}, Family: "MIT", SPDXStandard: true, OSIApproved: true},
+		},
+	}
+	if diff := cmp.Diff(expectedRules, run.Tool.Driver.Rules); diff != "" {
+		t.Errorf("Rules: Diff(-want +got) = %v", diff)
+	}
+
+	location := func(uri string, region SARIFRegion) []SARIFLocation {
+		return []SARIFLocation{{PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: uri}, Region: &region}}}
+	}
+	expectedResults := []SARIFResult{
+		{
+			RuleID: "Custom", RuleIndex: 0, Level: "note",
+			Message: SARIFMessage{Text: "License Custom (Custom) found"},
+			// "©" is two bytes but one column, so the match ends at column 9 of the second line
+			Locations: location("a.go", SARIFRegion{StartLine: 1, StartColumn: 4, EndLine: 2, EndColumn: 10, ByteOffset: 3, ByteLength: 12}),
+		},
+		{
+			RuleID: "MIT", RuleIndex: 1, Level: "note", Rank: 30,
+			Message:    SARIFMessage{Text: "License MIT License (MIT) found"},
+			Locations:  location("a.go", SARIFRegion{StartLine: 3, StartColumn: 4, EndLine: 3, EndColumn: 7, ByteOffset: 19, ByteLength: 3}),
+			Properties: &SARIFResultProps{Kind: "alias", Coverage: 0.1},
+		},
+		{
+			RuleID: "MIT", RuleIndex: 1, Level: "note",
+			Message:   SARIFMessage{Text: "License MIT License (MIT) found"},
+			Locations: location("z/LICENSE", SARIFRegion{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 12, ByteOffset: 18, ByteLength: 11}),
+		},
+	}
+	if diff := cmp.Diff(expectedResults, run.Results); diff != "" {
+		t.Errorf("Results: Diff(-want +got) = %v", diff)
+	}
+}
+
+func TestNewArtifactURI(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		file     string
+		basePath string
+		want     string
+	}{
+		{name: "relative to base", file: "src/dir/a b.go", basePath: "src", want: "dir/a%20b.go"},
+		{name: "no base", file: "src/a.go", want: "src/a.go"},
+		{name: "absolute outside base", file: "/tmp/LICENSE", basePath: "src", want: "file:///tmp/LICENSE"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := newArtifactURI(tt.file, tt.basePath); got != tt.want {
+				t.Errorf("newArtifactURI() = %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	t.Parallel()
+	results := []identifier.IdentifierResults{
+		{
+			File:         "LICENSE",
+			OriginalText: "MIT License",
+			Matches:      map[string][]identifier.Match{"MIT": {{Begins: 0, Ends: 10}}},
+		},
+	}
+	log := NewSARIFLog(results, testLicenseLibrary(), Options{ToolName: "tool", ToolVersion: "1.2.3"})
+
+	var b bytes.Buffer
+	if err := WriteSARIF(&b, log); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	var got SARIFLog
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+	if diff := cmp.Diff(log, &got); diff != "" {
+		t.Errorf("WriteSARIF() round trip Diff(-want +got) = %v", diff)
+	}
+	if !bytes.Contains(b.Bytes(), []byte(`"$schema": "`+SARIFSchema+`"`)) {
+		t.Errorf("expected $schema in %s", b.Bytes())
+	}
+}
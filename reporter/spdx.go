@@ -0,0 +1,309 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package reporter
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA1 is the checksum required by the SPDX 2.3 spec
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+const (
+	SPDXVersion          = "SPDX-2.3"
+	SPDXDataLicense      = "CC0-1.0"
+	SPDXDocumentID       = "SPDXRef-DOCUMENT"
+	SPDXNoAssertion      = "NOASSERTION"
+	SPDXLicenseRefPrefix = "LicenseRef-"
+)
+
+// SPDXDocument is an SPDX 2.3 document with a File element for each scanned file
+type SPDXDocument struct {
+	SPDXVersion                string                       `json:"spdxVersion"`
+	DataLicense                string                       `json:"dataLicense"`
+	SPDXID                     string                       `json:"SPDXID"`
+	Name                       string                       `json:"name"`
+	DocumentNamespace          string                       `json:"documentNamespace"`
+	CreationInfo               SPDXCreationInfo             `json:"creationInfo"`
+	Files                      []SPDXFile                   `json:"files"`
+	HasExtractedLicensingInfos []SPDXExtractedLicensingInfo `json:"hasExtractedLicensingInfos,omitempty"`
+	Relationships              []SPDXRelationship           `json:"relationships"`
+}
+
+// SPDXCreationInfo records when and by which tool the document was created
+type SPDXCreationInfo struct {
+	LicenseListVersion string   `json:"licenseListVersion,omitempty"`
+	Creators           []string `json:"creators"`
+	Created            string   `json:"created"`
+}
+
+// SPDXFile describes a scanned file
+type SPDXFile struct {
+	FileName           string         `json:"fileName"`
+	SPDXID             string         `json:"SPDXID"`
+	Checksums          []SPDXChecksum `json:"checksums"`
+	LicenseConcluded   string         `json:"licenseConcluded"`
+	LicenseInfoInFiles []string       `json:"licenseInfoInFiles"`
+	CopyrightText      string         `json:"copyrightText"`
+	// FileContributors are the copyright holders of the file
+	FileContributors []string `json:"fileContributors,omitempty"`
+}
+
+// SPDXChecksum is a checksum of the original file contents
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+// SPDXExtractedLicensingInfo holds the text of a license which is not on the SPDX license list
+type SPDXExtractedLicensingInfo struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name,omitempty"`
+}
+
+// SPDXRelationship relates two SPDX elements
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var licenseRefInvalidChars = regexp.MustCompile(`[^A-Za-z0-9.\-]+`)
+
+// NewSPDXDocument creates an SPDX 2.3 document with a File element for each scanned file.
+// Licenses which are not on the SPDX license list are written as LicenseRef- entries with their extracted text.
+func NewSPDXDocument(results []identifier.IdentifierResults, licenseLibrary *licenses.LicenseLibrary, options Options) *SPDXDocument {
+	name := options.DocumentName
+	if name == "" {
+		name = options.ToolName
+	}
+	doc := &SPDXDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       SPDXDataLicense,
+		SPDXID:            SPDXDocumentID,
+		Name:              name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%v-%v", licenseRefInvalidChars.ReplaceAllString(name, "-"), strings.TrimPrefix(newSerialNumber(), "urn:uuid:")),
+		CreationInfo: SPDXCreationInfo{
+			LicenseListVersion: options.SPDXVersion,
+			Creators:           []string{fmt.Sprintf("Tool: %v-%v", options.ToolName, options.ToolVersion)},
+			Created:            time.Now().UTC().Format(time.RFC3339),
+		},
+		Files:         []SPDXFile{},
+		Relationships: []SPDXRelationship{},
+	}
+
+	sorted := make([]*identifier.IdentifierResults, 0, len(results))
+	for i := range results {
+		sorted = append(sorted, &results[i])
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].File < sorted[j].File
+	})
+
+	extracted := make(map[string]SPDXExtractedLicensingInfo)
+	for i, ir := range sorted {
+		file := newSPDXFile(i+1, ir, licenseLibrary, options, extracted)
+		doc.Files = append(doc.Files, file)
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{
+			SPDXElementID:      SPDXDocumentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: file.SPDXID,
+		})
+	}
+
+	for _, info := range extracted {
+		doc.HasExtractedLicensingInfos = append(doc.HasExtractedLicensingInfos, info)
+	}
+	sort.Slice(doc.HasExtractedLicensingInfos, func(i, j int) bool {
+		return doc.HasExtractedLicensingInfos[i].LicenseID < doc.HasExtractedLicensingInfos[j].LicenseID
+	})
+	return doc
+}
+
+func newSPDXFile(n int, ir *identifier.IdentifierResults, licenseLibrary *licenses.LicenseLibrary, options Options, extracted map[string]SPDXExtractedLicensingInfo) SPDXFile {
+	fileName := ir.File
+	if options.BasePath != "" {
+		if rel, err := filepath.Rel(options.BasePath, ir.File); err == nil {
+			fileName = rel
+		}
+	}
+	fileName = filepath.ToSlash(fileName)
+	if !strings.HasPrefix(fileName, "/") && !strings.HasPrefix(fileName, "./") {
+		fileName = "./" + fileName
+	}
+
+	file := SPDXFile{
+		FileName: fileName,
+		SPDXID:   fmt.Sprintf("SPDXRef-File-%d", n),
+		Checksums: []SPDXChecksum{
+			{Algorithm: "SHA1", ChecksumValue: fmt.Sprintf("%x", sha1.Sum([]byte(ir.OriginalText)))}, //nolint:gosec
+			{Algorithm: "SHA256", ChecksumValue: fmt.Sprintf("%x", sha256.Sum256([]byte(ir.OriginalText)))},
+		},
+		LicenseConcluded: SPDXNoAssertion,
+		CopyrightText:    SPDXNoAssertion,
+	}
+
+	for _, lm := range newLicenseMatches(ir.Matches) {
+		id, info := newSPDXLicenseID(lm.ID, licenseLibrary)
+		if id == "" {
+			continue
+		}
+		if info != nil {
+			if info.ExtractedText == "" && len(lm.Matches) > 0 {
+				info.ExtractedText = matchedText(ir.OriginalText, lm.Matches[0])
+			}
+			if info.ExtractedText == "" {
+				info.ExtractedText = lm.ID // extracted text is required
+			}
+			if _, ok := extracted[id]; !ok {
+				extracted[id] = *info
+			}
+		}
+		if !slices.Contains(file.LicenseInfoInFiles, id) {
+			file.LicenseInfoInFiles = append(file.LicenseInfoInFiles, id)
+		}
+	}
+	if len(file.LicenseInfoInFiles) == 0 {
+		file.LicenseInfoInFiles = []string{SPDXNoAssertion}
+	}
+
+	var copyrights []string
+	for _, c := range ir.CopyRightStatements {
+		if text := strings.TrimSpace(c.Text); text != "" {
+			copyrights = append(copyrights, text)
+		}
+	}
+	if len(copyrights) > 0 {
+		file.CopyrightText = strings.Join(copyrights, "\n")
+	}
+	for _, h := range identifier.CopyrightHolders(*ir) {
+		file.FileContributors = append(file.FileContributors, h.Name)
+	}
+	return file
+}
+
+// newSPDXLicenseID returns the SPDX license ID, expression, or LicenseRef- for a license ID found by the identifier.
+// Extracted licensing info is returned for LicenseRef- IDs. Exceptions are skipped because they only appear in WITH expressions.
+func newSPDXLicenseID(id string, licenseLibrary *licenses.LicenseLibrary) (string, *SPDXExtractedLicensingInfo) {
+	var lic licenses.License
+	var ok bool
+	if licenseLibrary != nil {
+		lic, ok = licenseLibrary.LicenseMap[id]
+	}
+	if !ok {
+		if strings.Contains(id, " WITH ") || strings.HasPrefix(id, expression.DocumentRefPrefix) {
+			return id, nil
+		}
+		ref := NewSPDXLicenseRef(id)
+		return ref, &SPDXExtractedLicensingInfo{LicenseID: ref, Name: id}
+	}
+	if lic.SPDXLicenseID != "" {
+		if lic.LicenseInfo.SPDXException {
+			return "", nil
+		}
+		return lic.SPDXLicenseID, nil
+	}
+
+	ref := NewSPDXLicenseRef(id)
+	info := &SPDXExtractedLicensingInfo{LicenseID: ref, Name: lic.LicenseInfo.Name}
+	switch {
+	case lic.Text.Content != "" && lic.Text.Encoding == "":
+		info.ExtractedText = lic.Text.Content
+	case len(lic.PrimaryPatternsSources) > 0:
+		info.ExtractedText = lic.PrimaryPatternsSources[0].SourceText
+	}
+	return ref, info
+}
+
+// NewSPDXLicenseRef returns a LicenseRef- ID with characters which are not allowed in SPDX IDs replaced by "-".
+// An ID which is already a LicenseRef- is not prefixed again.
+func NewSPDXLicenseRef(id string) string {
+	id = strings.TrimPrefix(id, SPDXLicenseRefPrefix)
+	return SPDXLicenseRefPrefix + strings.Trim(licenseRefInvalidChars.ReplaceAllString(id, "-"), "-")
+}
+
+// matchedText returns the original text of a match (Ends is inclusive)
+func matchedText(text string, m Match) string {
+	if m.Begins < 0 || m.Ends < m.Begins || m.Ends >= len(text) {
+		return ""
+	}
+	return text[m.Begins : m.Ends+1]
+}
+
+// WriteSPDXJSON writes the document as indented SPDX JSON
+func WriteSPDXJSON(w io.Writer, doc *SPDXDocument) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// WriteSPDXTagValue writes the document in the SPDX tag-value format
+func WriteSPDXTagValue(w io.Writer, doc *SPDXDocument) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SPDXVersion: %v\n", doc.SPDXVersion)
+	fmt.Fprintf(&b, "DataLicense: %v\n", doc.DataLicense)
+	fmt.Fprintf(&b, "SPDXID: %v\n", doc.SPDXID)
+	fmt.Fprintf(&b, "DocumentName: %v\n", doc.Name)
+	fmt.Fprintf(&b, "DocumentNamespace: %v\n", doc.DocumentNamespace)
+	for _, c := range doc.CreationInfo.Creators {
+		fmt.Fprintf(&b, "Creator: %v\n", c)
+	}
+	fmt.Fprintf(&b, "Created: %v\n", doc.CreationInfo.Created)
+	if doc.CreationInfo.LicenseListVersion != "" {
+		fmt.Fprintf(&b, "LicenseListVersion: %v\n", doc.CreationInfo.LicenseListVersion)
+	}
+
+	for _, r := range doc.Relationships {
+		fmt.Fprintf(&b, "Relationship: %v %v %v\n", r.SPDXElementID, r.RelationshipType, r.RelatedSPDXElement)
+	}
+
+	for _, f := range doc.Files {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "FileName: %v\n", f.FileName)
+		fmt.Fprintf(&b, "SPDXID: %v\n", f.SPDXID)
+		for _, c := range f.Checksums {
+			fmt.Fprintf(&b, "FileChecksum: %v: %v\n", c.Algorithm, c.ChecksumValue)
+		}
+		fmt.Fprintf(&b, "LicenseConcluded: %v\n", f.LicenseConcluded)
+		for _, l := range f.LicenseInfoInFiles {
+			fmt.Fprintf(&b, "LicenseInfoInFile: %v\n", l)
+		}
+		fmt.Fprintf(&b, "FileCopyrightText: %v\n", tagValueText(f.CopyrightText))
+		for _, c := range f.FileContributors {
+			fmt.Fprintf(&b, "FileContributor: %v\n", c)
+		}
+	}
+
+	for _, e := range doc.HasExtractedLicensingInfos {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "LicenseID: %v\n", e.LicenseID)
+		fmt.Fprintf(&b, "ExtractedText: %v\n", tagValueText(e.ExtractedText))
+		if e.Name != "" {
+			fmt.Fprintf(&b, "LicenseName: %v\n", e.Name)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tagValueText wraps free-form text in <text> tags unless it is NONE or NOASSERTION
+func tagValueText(s string) string {
+	if s == SPDXNoAssertion || s == "NONE" {
+		return s
+	}
+	return "<text>" + strings.ReplaceAll(s, "</text>", "&lt;/text&gt;") + "</text>"
+}
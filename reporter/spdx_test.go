@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+func testSPDXResults() []identifier.IdentifierResults {
+	return []identifier.IdentifierResults{
+		{
+			File:         "src/dir/main.go",
+			OriginalText: "package main",
+			Matches:      map[string][]identifier.Match{},
+		},
+		{
+			File:         "src/LICENSE",
+			OriginalText: "Copyright 2022 Someone\nMIT...\nCustom license text",
+			Matches: map[string][]identifier.Match{
+				"MIT":     {{Begins: 23, Ends: 28}},
+				"Custom":  {{Begins: 30, Ends: 48}},
+				"Unknown": {{Begins: 30, Ends: 35}},
+			},
+			CopyRightStatements: []identifier.PatternMatch{{Text: "Copyright 2022 Someone\n", Begins: 0, Ends: 22}},
+			Copyrights:          identifier.ParseCopyrightStatements([]identifier.PatternMatch{{Text: "Copyright 2022 Someone\n", Begins: 0, Ends: 22}}),
+		},
+	}
+}
+
+func TestNewSPDXDocument(t *testing.T) {
+	t.Parallel()
+	ll := testLicenseLibrary()
+	custom := ll.LicenseMap["Custom"]
+	custom.PrimaryPatternsSources = []licenses.PrimaryPatternsSources{{SourceText: "Custom (license|terms)", Filename: "license_custom.txt"}}
+	ll.LicenseMap["Custom"] = custom
+
+	doc := NewSPDXDocument(testSPDXResults(), ll, Options{ToolName: "tool", ToolVersion: "1.2.3", DocumentName: "src", BasePath: "src"})
+	if doc.SPDXVersion != SPDXVersion || doc.Name != "src" || !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/src-") {
+		t.Errorf("unexpected document header %+v", doc)
+	}
+	if diff := cmp.Diff([]string{"Tool: tool-1.2.3"}, doc.CreationInfo.Creators); diff != "" {
+		t.Errorf("Creators: Diff(-want +got) = %v", diff)
+	}
+
+	expectedFiles := []SPDXFile{
+		{
+			FileName: "./LICENSE",
+			SPDXID:   "SPDXRef-File-1",
+			Checksums: []SPDXChecksum{
+				{Algorithm: "SHA1", ChecksumValue: "5235a2d3652d43bd9e8fd659fa2bfc339a2590b3"},
+				{Algorithm: "SHA256", ChecksumValue: "81e1b658066fe0c762f879e233829ccbb28db990fcdca17cc6779af810327150"},
+			},
+			LicenseConcluded:   SPDXNoAssertion,
+			LicenseInfoInFiles: []string{"LicenseRef-Custom", "MIT", "LicenseRef-Unknown"},
+			CopyrightText:      "Copyright 2022 Someone",
+			FileContributors:   []string{"Someone"},
+		},
+		{
+			FileName: "./dir/main.go",
+			SPDXID:   "SPDXRef-File-2",
+			Checksums: []SPDXChecksum{
+				{Algorithm: "SHA1", ChecksumValue: "04eb6f1bdaf51ae48ed5cf0153fad8593467b778"},
+				{Algorithm: "SHA256", ChecksumValue: "512843855fcc92a51c810b1b58e0731c01eac9a6a23c157bfa02aad71edffbe7"},
+			},
+			LicenseConcluded:   SPDXNoAssertion,
+			LicenseInfoInFiles: []string{SPDXNoAssertion},
+			CopyrightText:      SPDXNoAssertion,
+		},
+	}
+	if diff := cmp.Diff(expectedFiles, doc.Files); diff != "" {
+		t.Errorf("Files: Diff(-want +got) = %v", diff)
+	}
+
+	expectedExtracted := []SPDXExtractedLicensingInfo{
+		{LicenseID: "LicenseRef-Custom", ExtractedText: "Custom (license|terms)", Name: "Custom"},
+		{LicenseID: "LicenseRef-Unknown", ExtractedText: "Custom", Name: "Unknown"},
+	}
+	if diff := cmp.Diff(expectedExtracted, doc.HasExtractedLicensingInfos); diff != "" {
+		t.Errorf("HasExtractedLicensingInfos: Diff(-want +got) = %v", diff)
+	}
+
+	expectedRelationships := []SPDXRelationship{
+		{SPDXElementID: SPDXDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-File-1"},
+		{SPDXElementID: SPDXDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-File-2"},
+	}
+	if diff := cmp.Diff(expectedRelationships, doc.Relationships); diff != "" {
+		t.Errorf("Relationships: Diff(-want +got) = %v", diff)
+	}
+}
+
+func TestNewSPDXLicenseRef(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "Custom", want: "LicenseRef-Custom"},
+		{id: "Test 1.0", want: "LicenseRef-Test-1.0"},
+		{id: "(weird)_id!", want: "LicenseRef-weird-id"},
+	}
+	for _, tt := range tests {
+		if got := NewSPDXLicenseRef(tt.id); got != tt.want {
+			t.Errorf("NewSPDXLicenseRef(%q) = %q want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSPDX(t *testing.T) {
+	t.Parallel()
+	doc := NewSPDXDocument(testSPDXResults(), testLicenseLibrary(), Options{ToolName: "tool", ToolVersion: "1.2.3", BasePath: "src"})
+
+	var tv bytes.Buffer
+	if err := WriteSPDXTagValue(&tv, doc); err != nil {
+		t.Fatalf("WriteSPDXTagValue() error = %v", err)
+	}
+	for _, expected := range []string{
+		"SPDXVersion: SPDX-2.3\n",
+		"Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-File-1\n",
+		"FileName: ./LICENSE\n",
+		"LicenseInfoInFile: MIT\n",
+		"FileCopyrightText: <text>Copyright 2022 Someone</text>\n",
+		"FileContributor: Someone\n",
+		"LicenseConcluded: NOASSERTION\n",
+		"LicenseID: LicenseRef-Custom\n",
+	} {
+		if !strings.Contains(tv.String(), expected) {
+			t.Errorf("expected tag-value containing %q got\n%v", expected, tv.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := WriteSPDXJSON(&js, doc); err != nil {
+		t.Fatalf("WriteSPDXJSON() error = %v", err)
+	}
+	var got SPDXDocument
+	if err := json.Unmarshal(js.Bytes(), &got); err != nil {
+		t.Fatalf("invalid SPDX JSON: %v", err)
+	}
+	if diff := cmp.Diff(doc, &got); diff != "" {
+		t.Errorf("WriteSPDXJSON() round trip Diff(-want +got) = %v", diff)
+	}
+}
@@ -3,8 +3,11 @@
 package resources
 
 import (
+	"crypto/sha256"
 	"embed"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
@@ -17,8 +20,11 @@ import (
 )
 
 const (
-	LicensePatternsDir = "license_patterns"
-	JSONDir            = "json"
+	LicensePatternsDir    = "license_patterns"
+	AcceptablePatternsDir = "acceptable_patterns"
+	// KeywordRulesFile holds the keyword rules of a custom resources directory (see identifier.ReadKeywordRules)
+	KeywordRulesFile = "keyword_rules.json"
+	JSONDir          = "json"
 )
 
 type Resources struct {
@@ -198,6 +204,11 @@ func (r *Resources) ReadCustomFile(filePath string) ([]byte, error) {
 	return b, err
 }
 
+// ReadCustomKeywordRulesFile reads the keyword rules file of the custom resources, which may not exist (fs.ErrNotExist)
+func (r *Resources) ReadCustomKeywordRulesFile() ([]byte, error) {
+	return r.customReader.ReadFile(path.Join(r.customPath, KeywordRulesFile))
+}
+
 func (r *Resources) WriteCustomFile(bytes []byte, ff ...string) error {
 	f := path.Join(r.customWritePath, path.Join(ff...))
 	return os.WriteFile(f, bytes, 0o600)
@@ -246,3 +257,59 @@ func (r *Resources) WriteSPDXFile(bytes []byte, ff ...string) error {
 	f := path.Join(r.spdxWritePath, path.Join(ff...))
 	return os.WriteFile(f, bytes, 0o600)
 }
+
+// Digest returns a SHA-256 of the path, size, and modification time of each SPDX and custom resource file.
+// It changes when resource files are added, removed, or changed, without reading them.
+// Embedded resources have no modification time, so their contents, which are in memory, are used instead.
+func (r *Resources) Digest() (string, error) {
+	h := sha256.New()
+	for _, d := range []struct {
+		reader resourceReader
+		dirs   []string
+	}{
+		{r.spdxReader, []string{path.Join(r.spdxPath, "template"), path.Join(r.spdxPath, "precheck"), path.Join(r.spdxPath, JSONDir)}},
+		{r.customReader, []string{path.Join(r.customPath, LicensePatternsDir), path.Join(r.customPath, AcceptablePatternsDir)}},
+	} {
+		for _, dir := range d.dirs {
+			if err := digestDir(h, d.reader, dir); err != nil {
+				return "", err
+			}
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// digestDir writes the path, size, and modification time of each file in the dir and its subdirectories,
+// or the path and SHA-256 of the content of each embedded file
+func digestDir(w io.Writer, reader resourceReader, dir string) error {
+	des, err := reader.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, de := range des {
+		p := path.Join(dir, de.Name())
+		if de.IsDir() {
+			if err := digestDir(w, reader, p); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, embedded := reader.(embed.FS); embedded {
+			b, err := reader.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%q %x\n", p, sha256.Sum256(b))
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%q %v %v\n", p, info.Size(), info.ModTime().UnixNano())
+	}
+	return nil
+}
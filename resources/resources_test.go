@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package resources
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_digestDir_embedded(t *testing.T) {
+	const dir = "custom/default/license_patterns/Apache-2.0"
+	var b strings.Builder
+	if err := digestDir(&b, embeddedFS, dir); err != nil {
+		t.Fatalf("digestDir() error = %v", err)
+	}
+	content, err := embeddedFS.ReadFile(dir + "/license_info.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// embedded files have no modification time, so a change which keeps the size must change the content hash
+	if want := fmt.Sprintf("%q %x\n", dir+"/license_info.json", sha256.Sum256(content)); !strings.Contains(b.String(), want) {
+		t.Errorf("digestDir() = %v expected the hash of the content %v", b.String(), want)
+	}
+}
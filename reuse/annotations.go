@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package reuse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/CycloneDX/license-scanner/filter"
+)
+
+const (
+	// Dep5File is the Debian copyright file of the deprecated way to annotate files with licensing information
+	Dep5File = ".reuse/dep5"
+	// TomlFile annotates files with licensing information
+	TomlFile = "REUSE.toml"
+)
+
+// Precedence decides how the licensing information of an annotation combines with the information in a file
+type Precedence string
+
+const (
+	// Closest uses the information of the file, and the annotation for the licenses or copyrights which the file does not have
+	Closest Precedence = "closest"
+	// Aggregate uses the information of both the file and the annotation
+	Aggregate Precedence = "aggregate"
+	// Override uses the information of the annotation, and ignores the file
+	Override Precedence = "override"
+)
+
+// info is the licensing information of a file
+type info struct {
+	// licenses are the SPDX license expressions, which are not parsed
+	licenses  []string
+	copyright bool
+}
+
+// annotation is the licensing information of the files which match it
+type annotation struct {
+	match      func(rel string) bool
+	precedence Precedence
+	info       info
+}
+
+// readAnnotations reads the REUSE.toml or the .reuse/dep5 file at the root of the project, if either exists
+func readAnnotations(root string) ([]annotation, error) {
+	tomlContent, tomlErr := os.ReadFile(filepath.Join(root, TomlFile))
+	dep5Content, dep5Err := os.ReadFile(filepath.Join(root, filepath.FromSlash(Dep5File)))
+	for _, err := range []error{tomlErr, dep5Err} {
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	switch {
+	case tomlErr == nil && dep5Err == nil:
+		return nil, fmt.Errorf("both %v and %v exist (use only %v)", TomlFile, Dep5File, TomlFile)
+	case tomlErr == nil:
+		annotations, err := parseToml(tomlContent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v: %w", TomlFile, err)
+		}
+		return annotations, nil
+	case dep5Err == nil:
+		return parseDep5(dep5Content), nil
+	}
+	return nil, nil
+}
+
+// annotate combines the licensing information of a file with the last annotation which matches it, if any
+func annotate(own info, annotations []annotation, rel string) info {
+	var a *annotation
+	for i := range annotations {
+		if annotations[i].match(rel) {
+			a = &annotations[i]
+		}
+	}
+	if a == nil {
+		return own
+	}
+	switch a.precedence {
+	case Override:
+		return a.info
+	case Aggregate:
+		return info{licenses: append(own.licenses, a.info.licenses...), copyright: own.copyright || a.info.copyright}
+	}
+	if len(own.licenses) == 0 {
+		own.licenses = a.info.licenses
+	}
+	own.copyright = own.copyright || a.info.copyright
+	return own
+}
+
+// parseToml parses the [[annotations]] of a REUSE.toml, whose paths are glob patterns where "**" matches any number of directories
+func parseToml(content []byte) ([]annotation, error) {
+	var doc struct {
+		Version     int `toml:"version"`
+		Annotations []struct {
+			Path       interface{} `toml:"path"`
+			Precedence Precedence  `toml:"precedence"`
+			Copyright  interface{} `toml:"SPDX-FileCopyrightText"`
+			License    interface{} `toml:"SPDX-License-Identifier"`
+		} `toml:"annotations"`
+	}
+	if err := toml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != 1 {
+		return nil, fmt.Errorf("unsupported version %v (expected 1)", doc.Version)
+	}
+	var ret []annotation
+	for i, a := range doc.Annotations {
+		paths := stringList(a.Path)
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("annotation %v has no path", i+1)
+		}
+		precedence := a.Precedence
+		switch precedence {
+		case "":
+			precedence = Closest
+		case Closest, Aggregate, Override:
+		default:
+			return nil, fmt.Errorf("annotation %v has an invalid precedence %q (expected closest, aggregate, or override)", i+1, precedence)
+		}
+		ret = append(ret, annotation{
+			match: func(rel string) bool {
+				for _, p := range paths {
+					if filter.Match(p, rel) {
+						return true
+					}
+				}
+				return false
+			},
+			precedence: precedence,
+			info:       info{licenses: stringList(a.License), copyright: len(stringList(a.Copyright)) > 0},
+		})
+	}
+	return ret, nil
+}
+
+// stringList returns the strings which are not blank of a TOML string or array of strings
+func stringList(v interface{}) []string {
+	var values []interface{}
+	switch v := v.(type) {
+	case string:
+		values = []interface{}{v}
+	case []interface{}:
+		values = v
+	}
+	var ret []string
+	for _, value := range values {
+		if s, ok := value.(string); ok && strings.TrimSpace(s) != "" {
+			ret = append(ret, strings.TrimSpace(s))
+		}
+	}
+	return ret
+}
+
+// parseDep5 parses the Files paragraphs of a .reuse/dep5 file, which aggregate with the information in the files.
+// The Files patterns are globs where "*" also matches "/", and the first line of a License field is its expression.
+func parseDep5(content []byte) []annotation {
+	var ret []annotation
+	for _, fields := range dep5Paragraphs(content) {
+		patterns := strings.Fields(fields["files"])
+		if len(patterns) == 0 {
+			continue // the header paragraph
+		}
+		var res []*regexp.Regexp
+		for _, p := range patterns {
+			res = append(res, dep5Regexp(p))
+		}
+		a := annotation{
+			match: func(rel string) bool {
+				for _, re := range res {
+					if re.MatchString(rel) {
+						return true
+					}
+				}
+				return false
+			},
+			precedence: Aggregate,
+			info:       info{copyright: strings.TrimSpace(fields["copyright"]) != ""},
+		}
+		if license, _, _ := strings.Cut(fields["license"], "\n"); strings.TrimSpace(license) != "" {
+			a.info.licenses = []string{strings.TrimSpace(license)}
+		}
+		ret = append(ret, a)
+	}
+	return ret
+}
+
+// dep5Paragraphs returns the fields, by lower case name, of each paragraph of a Debian copyright file.
+// The continuation lines of a field are joined with "\n".
+func dep5Paragraphs(content []byte) []map[string]string {
+	var paragraphs []map[string]string
+	fields := make(map[string]string)
+	key := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(fields) > 0 {
+				paragraphs = append(paragraphs, fields)
+				fields = make(map[string]string)
+			}
+			key = ""
+		case strings.HasPrefix(line, "#"):
+		case line[0] == ' ' || line[0] == '\t':
+			if key != "" {
+				fields[key] += "\n" + strings.TrimSpace(line)
+			}
+		default:
+			var value string
+			key, value, _ = strings.Cut(line, ":")
+			key = strings.ToLower(strings.TrimSpace(key))
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	if len(fields) > 0 {
+		paragraphs = append(paragraphs, fields)
+	}
+	return paragraphs
+}
+
+// dep5Regexp returns the regular expression of a Files pattern, where "*" matches any characters, "?" matches one character,
+// and "\" escapes the next character
+func dep5Regexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	escaped := false
+	for _, r := range strings.TrimPrefix(pattern, "./") {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			b.WriteString(".*")
+		case r == '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
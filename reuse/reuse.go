@@ -0,0 +1,263 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package reuse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/sbom-utility/log"
+	"golang.org/x/exp/slices"
+
+	"github.com/CycloneDX/license-scanner/expression"
+	"github.com/CycloneDX/license-scanner/filter"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+// LicensesDir is the directory with the text of each license of the project, named by its ID (e.g. LICENSES/MIT.txt)
+const LicensesDir = "LICENSES"
+
+// maxHeaderBytes is the most of each file which is read for its licensing information, like the largest file which is identified
+const maxHeaderBytes = 1000000
+
+var Logger *log.MiniLogger = log.NewLogger(log.DEFAULT_LEVEL)
+
+// ErrNotCompliant is returned by Check when a project does not comply with the REUSE specification
+var ErrNotCompliant = errors.New("the project is not REUSE compliant")
+
+var (
+	// copyrightRE finds a copyright notice at the start of a line, after any comment characters
+	copyrightRE = regexp.MustCompile(`(?im)^\W*(?:SPDX-(?:File|Snippet)CopyrightText:|Copyright(?:\s+\(c\))?\s+\S|©\s*\S)`)
+	// ignoreRE finds the text which is not linted
+	ignoreRE = regexp.MustCompile(`(?s)REUSE-IgnoreStart.*?REUSE-IgnoreEnd`)
+	// licenseFileRE finds the license files (e.g. LICENSE.md, COPYING) which the specification does not cover
+	licenseFileRE = regexp.MustCompile(`^(?:LICEN[CS]E|COPYING)(?:[-.].*)?$`)
+	// vcsDirs are the version control directories which the specification does not cover, even with NoIgnore
+	vcsDirs = []string{".git", ".hg", ".sl", ".svn"}
+)
+
+// Report is the REUSE compliance of a project. Files are relative to the root of the project.
+type Report struct {
+	// Files is the number of files which the specification covers
+	Files int `json:"files"`
+	// BadLicenses are the license IDs which are neither in the license library nor LicenseRef- IDs, with the files which reference them or their text
+	BadLicenses map[string][]string `json:"badLicenses,omitempty"`
+	// DeprecatedLicenses are the deprecated license IDs, with the files which reference them
+	DeprecatedLicenses map[string][]string `json:"deprecatedLicenses,omitempty"`
+	// InvalidExpressions are the license expressions which cannot be parsed, with the files which have them
+	InvalidExpressions map[string][]string `json:"invalidExpressions,omitempty"`
+	// MissingLicenseTexts are the license IDs without a text in the LICENSES directory, with the files which reference them
+	MissingLicenseTexts map[string][]string `json:"missingLicenseTexts,omitempty"`
+	// UnusedLicenseTexts are the license texts of the LICENSES directory which no file references
+	UnusedLicenseTexts []string `json:"unusedLicenseTexts,omitempty"`
+	// MissingLicensing are the files without a license
+	MissingLicensing []string `json:"missingLicensing,omitempty"`
+	// MissingCopyright are the files without a copyright notice
+	MissingCopyright []string `json:"missingCopyright,omitempty"`
+	// ReadErrors are the files which cannot be read, with the error
+	ReadErrors []string `json:"readErrors,omitempty"`
+	Compliant  bool     `json:"compliant"`
+}
+
+// Check returns an error which wraps ErrNotCompliant if the project is not compliant
+func (r *Report) Check() error {
+	if r.Compliant {
+		return nil
+	}
+	return ErrNotCompliant
+}
+
+// Lint checks that the files under the root which the filter selects comply with the REUSE specification (https://reuse.software/spec/):
+// each file has a license and a copyright notice in its header, in a FILE.license sidecar, or in an annotation of REUSE.toml or .reuse/dep5,
+// each license has a text in the LICENSES directory, and each license text is used
+func Lint(ctx context.Context, root string, f filter.Filter, licenseMap licenses.LicenseMap) (*Report, error) {
+	annotations, err := readAnnotations(root)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]string, len(licenseMap))
+	for id := range licenseMap {
+		ids[strings.ToLower(id)] = id
+	}
+	canonical := func(id string) string {
+		if known, ok := ids[strings.ToLower(id)]; ok {
+			return known
+		}
+		return id
+	}
+
+	r := &Report{}
+	// references are the files which reference each license or exception ID
+	references := make(map[string][]string)
+	if err := f.Walk(root, Logger.Warningf, func(p string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if !covered(rel) {
+			return nil
+		}
+		r.Files++
+		fileInfo, err := readInfo(p)
+		if err != nil {
+			r.ReadErrors = append(r.ReadErrors, fmt.Sprintf("%v: %v", rel, err))
+			return nil
+		}
+		fileInfo = annotate(fileInfo, annotations, rel)
+		if len(fileInfo.licenses) == 0 {
+			r.MissingLicensing = append(r.MissingLicensing, rel)
+		}
+		if !fileInfo.copyright {
+			r.MissingCopyright = append(r.MissingCopyright, rel)
+		}
+		for _, value := range fileInfo.licenses {
+			e, err := expression.Parse(value)
+			if err != nil {
+				r.InvalidExpressions = addFile(r.InvalidExpressions, value, rel)
+				continue
+			}
+			for _, l := range e.Licenses() {
+				for _, id := range []string{l.License, l.Exception} {
+					if id != "" {
+						references[canonical(id)] = appendFile(references[canonical(id)], rel)
+					}
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	texts, err := readLicenseTexts(root, canonical)
+	if err != nil {
+		return nil, err
+	}
+	for id, files := range references {
+		license, known := licenseMap[id]
+		switch {
+		case !known && !strings.HasPrefix(id, expression.LicenseRefPrefix):
+			r.BadLicenses = addFile(r.BadLicenses, id, files...)
+		case known && license.LicenseInfo.IsDeprecated:
+			r.DeprecatedLicenses = addFile(r.DeprecatedLicenses, id, files...)
+		}
+		if _, ok := texts[id]; !ok {
+			r.MissingLicenseTexts = addFile(r.MissingLicenseTexts, id, files...)
+		}
+	}
+	for text, file := range texts {
+		if _, known := licenseMap[text]; !known && !strings.HasPrefix(text, expression.LicenseRefPrefix) {
+			r.BadLicenses = addFile(r.BadLicenses, text, file)
+		}
+		if _, ok := references[text]; !ok {
+			r.UnusedLicenseTexts = append(r.UnusedLicenseTexts, file)
+		}
+	}
+	for _, files := range []map[string][]string{r.BadLicenses, r.DeprecatedLicenses, r.InvalidExpressions, r.MissingLicenseTexts} {
+		for _, list := range files {
+			sort.Strings(list)
+		}
+	}
+	for _, list := range [][]string{r.UnusedLicenseTexts, r.MissingLicensing, r.MissingCopyright, r.ReadErrors} {
+		sort.Strings(list)
+	}
+	r.Compliant = len(r.BadLicenses) == 0 && len(r.DeprecatedLicenses) == 0 && len(r.InvalidExpressions) == 0 &&
+		len(r.MissingLicenseTexts) == 0 && len(r.UnusedLicenseTexts) == 0 && len(r.MissingLicensing) == 0 &&
+		len(r.MissingCopyright) == 0 && len(r.ReadErrors) == 0
+	return r, nil
+}
+
+// covered is false for the files which the specification does not cover: license files, the LICENSES and .reuse directories,
+// REUSE.toml, .license sidecars, SPDX documents, and version control directories
+func covered(rel string) bool {
+	name := path.Base(rel)
+	lower := strings.ToLower(name)
+	switch {
+	case licenseFileRE.MatchString(name), name == TomlFile:
+		return false
+	case strings.HasPrefix(rel, LicensesDir+"/"), strings.HasPrefix(rel, path.Dir(Dep5File)+"/"):
+		return false
+	case strings.HasSuffix(lower, ".license"), strings.HasSuffix(lower, ".spdx"), strings.HasSuffix(lower, ".spdx.json"):
+		return false
+	}
+	for _, dir := range strings.Split(path.Dir(rel), "/") {
+		if slices.Contains(vcsDirs, dir) {
+			return false
+		}
+	}
+	return true
+}
+
+// readInfo reads the licensing information in the FILE.license sidecar of a file if it exists, or else in the file
+func readInfo(p string) (info, error) {
+	content, err := readHeader(p + ".license")
+	if errors.Is(err, fs.ErrNotExist) {
+		content, err = readHeader(p)
+	}
+	if err != nil {
+		return info{}, err
+	}
+	text := ignoreRE.ReplaceAllString(string(content), "")
+	return info{licenses: identifier.LicenseTagValues(text), copyright: copyrightRE.MatchString(text)}, nil
+}
+
+// readHeader reads at most the first maxHeaderBytes of a file
+func readHeader(p string) ([]byte, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxHeaderBytes))
+}
+
+// readLicenseTexts returns the file of each license text in the LICENSES directory, by license ID (the name without its extension).
+// IDs are changed to the case of the license library by canonical.
+func readLicenseTexts(root string, canonical func(id string) string) (map[string]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, LicensesDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	texts := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(strings.ToLower(name), ".license") {
+			continue
+		}
+		texts[canonical(strings.TrimSuffix(name, path.Ext(name)))] = LicensesDir + "/" + name
+	}
+	return texts, nil
+}
+
+// addFile adds the files of a key to a map which is created if it is nil
+func addFile(m map[string][]string, key string, files ...string) map[string][]string {
+	if m == nil {
+		m = make(map[string][]string)
+	}
+	for _, file := range files {
+		m[key] = appendFile(m[key], file)
+	}
+	return m
+}
+
+// appendFile appends a file which is not already the last file of the list
+func appendFile(files []string, file string) []string {
+	if len(files) > 0 && files[len(files)-1] == file {
+		return files
+	}
+	return append(files, file)
+}
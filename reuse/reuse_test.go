@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package reuse
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/filter"
+	"github.com/CycloneDX/license-scanner/licenses"
+)
+
+var licenseMap = licenses.LicenseMap{
+	"MIT":                     {LicenseInfo: licenses.LicenseInfo{Name: "MIT License"}},
+	"Apache-2.0":              {LicenseInfo: licenses.LicenseInfo{Name: "Apache License 2.0"}},
+	"ISC":                     {LicenseInfo: licenses.LicenseInfo{Name: "ISC License"}},
+	"GPL-2.0":                 {LicenseInfo: licenses.LicenseInfo{Name: "GNU General Public License v2.0 only", IsDeprecated: true}},
+	"Classpath-exception-2.0": {LicenseInfo: licenses.LicenseInfo{Name: "Classpath exception 2.0", SPDXException: true}},
+}
+
+// writeFiles writes the files of a project, by slash-separated path, in a new directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+const header = "// SPDX-FileCopyrightText: 2023 Jane Doe\n// SPDX-License-Identifier: "
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  *Report
+	}{
+		{
+			name: "compliant",
+			files: map[string]string{
+				"main.go":                      header + "MIT\npackage main\n",
+				"lib/lib.go":                   "/*\n * Copyright (c) 2023 Jane Doe\n * SPDX-License-Identifier: apache-2.0 */\n",
+				"logo.png":                     "\x89PNG",
+				"logo.png.license":             "SPDX-FileCopyrightText: 2023 Jane Doe\n\nSPDX-License-Identifier: LicenseRef-Logo\n",
+				"LICENSE":                      "MIT License",
+				"LICENSES/MIT.txt":             "MIT License",
+				"LICENSES/Apache-2.0.txt":      "Apache License",
+				"LICENSES/LicenseRef-Logo.txt": "All rights reserved",
+			},
+			want: &Report{Files: 3, Compliant: true},
+		},
+		{
+			name: "not compliant",
+			files: map[string]string{
+				"a.go":             header + "MIT OR Foo\n",
+				"b.go":             header + "GPL-2.0 WITH Classpath-exception-2.0\n",
+				"c.go":             header + "MIT AND (\n",
+				"d.go":             "package d\n",
+				"big.txt":          strings.Repeat("x", maxHeaderBytes) + header + "MIT\n",
+				"e.go":             "// REUSE-IgnoreStart\n" + header + "MIT\n// REUSE-IgnoreEnd\n",
+				"LICENSES/MIT.txt": "MIT License",
+				"LICENSES/ISC.txt": "ISC License",
+			},
+			want: &Report{
+				Files:               6,
+				BadLicenses:         map[string][]string{"Foo": {"a.go"}},
+				DeprecatedLicenses:  map[string][]string{"GPL-2.0": {"b.go"}},
+				InvalidExpressions:  map[string][]string{"MIT AND (": {"c.go"}},
+				MissingLicenseTexts: map[string][]string{"Foo": {"a.go"}, "GPL-2.0": {"b.go"}, "Classpath-exception-2.0": {"b.go"}},
+				UnusedLicenseTexts:  []string{"LICENSES/ISC.txt"},
+				MissingLicensing:    []string{"big.txt", "d.go", "e.go"},
+				MissingCopyright:    []string{"big.txt", "d.go", "e.go"},
+			},
+		},
+		{
+			name: "REUSE.toml",
+			files: map[string]string{
+				"REUSE.toml": `version = 1
+
+[[annotations]]
+path = ["docs/**", "*.md"]
+SPDX-FileCopyrightText = "2023 Jane Doe"
+SPDX-License-Identifier = "Apache-2.0"
+
+[[annotations]]
+path = "docs/generated/**"
+precedence = "override"
+SPDX-FileCopyrightText = ["2023 Jane Doe"]
+SPDX-License-Identifier = "MIT"
+`,
+				"README.md":               "# Project\n",
+				"sub/README.md":           "# Sub\n",
+				"docs/guide.md":           header + "MIT\n",
+				"docs/generated/api.md":   header + "Apache-2.0\n",
+				"LICENSES/MIT.txt":        "MIT License",
+				"LICENSES/Apache-2.0.txt": "Apache License",
+			},
+			want: &Report{
+				Files:            4,
+				MissingLicensing: []string{"sub/README.md"},
+				MissingCopyright: []string{"sub/README.md"},
+			},
+		},
+		{
+			name: "dep5",
+			files: map[string]string{
+				".reuse/dep5": `Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+Upstream-Name: project
+
+Files: docs/* *.md
+Copyright: 2023 Jane Doe
+License: MIT
+ The text of the license is ignored.
+`,
+				"README.md":        "# Project\n",
+				"docs/a/guide.txt": "Guide\n",
+				"docs/b.go":        header + "Apache-2.0\n",
+				"LICENSES/MIT.txt": "MIT License",
+			},
+			want: &Report{
+				Files:               3,
+				MissingLicenseTexts: map[string][]string{"Apache-2.0": {"docs/b.go"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Lint(context.Background(), writeFiles(t, tt.files), filter.Filter{}, licenseMap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("Didn't get expected report: (-want, +got): %v", d)
+			}
+			if err := got.Check(); errors.Is(err, ErrNotCompliant) == tt.want.Compliant {
+				t.Errorf("Check() error = %v, compliant %v", err, tt.want.Compliant)
+			}
+		})
+	}
+}
+
+func TestLint_InvalidAnnotations(t *testing.T) {
+	t.Parallel()
+	tests := map[string]map[string]string{
+		"both":       {"REUSE.toml": "version = 1\n", ".reuse/dep5": "Files: *\n"},
+		"version":    {"REUSE.toml": "version = 2\n"},
+		"path":       {"REUSE.toml": "version = 1\n[[annotations]]\nSPDX-License-Identifier = \"MIT\"\n"},
+		"precedence": {"REUSE.toml": "version = 1\n[[annotations]]\npath = \"*\"\nprecedence = \"first\"\n"},
+	}
+	for name, files := range tests {
+		if _, err := Lint(context.Background(), writeFiles(t, files), filter.Filter{}, licenseMap); err == nil {
+			t.Errorf("%v: expected an error", name)
+		} else if !strings.Contains(err.Error(), "REUSE.toml") {
+			t.Errorf("%v: expected a REUSE.toml error got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+
+	"github.com/CycloneDX/license-scanner/archive"
+)
+
+// ArchiveSeparator separates the name of an archive from the path of an entry in it
+const ArchiveSeparator = archive.Separator
+
+// walk calls fn for each entry of an uploaded archive, including nested archives, or for the upload itself for any other file
+func (s *Server) walk(name string, b []byte, fn func(archive.Entry) error) error {
+	if archive.IsArchive(name) {
+		return archive.Walk(name, b, s.config.ArchiveLimits, fn)
+	}
+	if int64(len(b)) > s.config.MaxFileBytes {
+		return fn(archive.Entry{Name: name, Size: int64(len(b)), Err: fmt.Errorf("%w (%v > %v)", archive.ErrTooLarge, len(b), s.config.MaxFileBytes)})
+	}
+	return fn(archive.Entry{Name: name, Size: int64(len(b)), Content: b})
+}
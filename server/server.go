@@ -0,0 +1,358 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/license-scanner/archive"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/reporter"
+)
+
+const (
+	// DefaultMaxRequestBytes is the default limit for the size of a request body
+	DefaultMaxRequestBytes = 32 << 20
+	// DefaultMaxFileBytes is the default limit for the size of a scanned text, file, or archive entry
+	DefaultMaxFileBytes = 1000000
+	// DefaultShutdownTimeout is how long in-flight requests may run after shutdown begins
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// Config holds the settings of a Server
+type Config struct {
+	// ToolName and ToolVersion identify the scanner in reports
+	ToolName    string
+	ToolVersion string
+	// Options are the default identifier options. Requests may override the enhancements with query parameters.
+	Options identifier.Options
+	// IncludeNormalizedText adds the normalized text to reports by default
+	IncludeNormalizedText bool
+	// MaxRequestBytes limits the size of a request body (DefaultMaxRequestBytes when 0)
+	MaxRequestBytes int64
+	// MaxFileBytes limits the size of each scanned text, file, or archive entry (DefaultMaxFileBytes when 0)
+	MaxFileBytes int64
+	// ArchiveLimits limit the expansion of uploaded archives (archive.DefaultLimits for each field which is 0).
+	// Uploaded archives are always expanded, so MaxDepth is at least 1. MaxFileBytes is replaced by the MaxFileBytes above.
+	ArchiveLimits archive.Limits
+	// Timeout limits the time of each scan request, which then fails with status 503 (no limit when 0).
+	// Options.FileTimeout limits the time of each text or file in a request.
+	Timeout time.Duration
+	// ShutdownTimeout limits how long in-flight requests may run after shutdown begins (DefaultShutdownTimeout when 0)
+	ShutdownTimeout time.Duration
+}
+
+// Server scans text and uploaded files with a license library which is loaded once
+type Server struct {
+	licenseLibrary *licenses.LicenseLibrary
+	config         Config
+}
+
+// LicenseInfo is a license in the list returned by the licenses endpoint
+type LicenseInfo struct {
+	ID            string `json:"id"`
+	Name          string `json:"name,omitempty"`
+	Family        string `json:"family,omitempty"`
+	SPDXStandard  bool   `json:"spdxStandard"`
+	SPDXException bool   `json:"spdxException"`
+	OSIApproved   bool   `json:"osiApproved"`
+	FSFLibre      bool   `json:"fsfLibre"`
+	Deprecated    bool   `json:"deprecated"`
+}
+
+// LicenseList is the response of the licenses endpoint
+type LicenseList struct {
+	SPDXVersion string        `json:"spdxLicenseListVersion,omitempty"`
+	Licenses    []LicenseInfo `json:"licenses"`
+}
+
+// Health is the response of the health endpoint
+type Health struct {
+	Status      string `json:"status"`
+	SPDXVersion string `json:"spdxLicenseListVersion,omitempty"`
+	Licenses    int    `json:"licenses"`
+}
+
+// Error is the response for a failed request
+type Error struct {
+	Error string `json:"error"`
+}
+
+// New returns a Server for the license library, which must already be loaded
+func New(licenseLibrary *licenses.LicenseLibrary, config Config) *Server {
+	if config.MaxRequestBytes <= 0 {
+		config.MaxRequestBytes = DefaultMaxRequestBytes
+	}
+	if config.MaxFileBytes <= 0 {
+		config.MaxFileBytes = DefaultMaxFileBytes
+	}
+	if config.ArchiveLimits.MaxDepth <= 0 {
+		config.ArchiveLimits.MaxDepth = archive.DefaultLimits.MaxDepth
+	}
+	config.ArchiveLimits.MaxFileBytes = config.MaxFileBytes
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	return &Server{licenseLibrary: licenseLibrary, config: config}
+}
+
+// Handler returns the HTTP handler for the endpoints:
+//
+//	GET  /healthz          health and license library summary
+//	GET  /v1/licenses      the licenses in the library
+//	POST /v1/scan/text     scan the request body as text
+//	POST /v1/scan/files    scan the files (and archive entries) of a multipart/form-data upload
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.method(http.MethodGet, s.health))
+	mux.HandleFunc("/v1/licenses", s.method(http.MethodGet, s.listLicenses))
+	mux.HandleFunc("/v1/scan/text", s.method(http.MethodPost, s.scanText))
+	mux.HandleFunc("/v1/scan/files", s.method(http.MethodPost, s.scanFiles))
+	return mux
+}
+
+// ListenAndServe serves on addr until the context is done, then shuts down gracefully
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// method rejects requests with any other method and limits the size of the request body
+func (s *Server) method(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v not allowed", r.Method))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxRequestBytes)
+		h(w, r)
+	}
+}
+
+func (s *Server) health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, Health{
+		Status:      "ok",
+		SPDXVersion: s.licenseLibrary.SPDXVersion,
+		Licenses:    len(s.licenseLibrary.LicenseMap),
+	})
+}
+
+func (s *Server) listLicenses(w http.ResponseWriter, _ *http.Request) {
+	list := LicenseList{SPDXVersion: s.licenseLibrary.SPDXVersion, Licenses: []LicenseInfo{}}
+	for id, l := range s.licenseLibrary.LicenseMap {
+		list.Licenses = append(list.Licenses, LicenseInfo{
+			ID:            id,
+			Name:          l.LicenseInfo.Name,
+			Family:        l.LicenseInfo.Family,
+			SPDXStandard:  l.LicenseInfo.SPDXStandard,
+			SPDXException: l.LicenseInfo.SPDXException,
+			OSIApproved:   l.LicenseInfo.OSIApproved,
+			FSFLibre:      l.LicenseInfo.IsFSFLibre,
+			Deprecated:    l.LicenseInfo.IsDeprecated,
+		})
+	}
+	sort.Slice(list.Licenses, func(i, j int) bool { return list.Licenses[i].ID < list.Licenses[j].ID })
+	writeJSON(w, http.StatusOK, list)
+}
+
+// scanText scans the request body. The optional name query parameter is used as the file of the result.
+func (s *Server) scanText(w http.ResponseWriter, r *http.Request) {
+	options, reportOptions, err := s.requestOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeBodyError(w, err)
+		return
+	}
+	if int64(len(b)) > s.config.MaxFileBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("text too large (%v > %v)", len(b), s.config.MaxFileBytes))
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+	result, err := identifier.IdentifyLicensesInStringContext(ctx, string(b), options, s.licenseLibrary)
+	if err != nil {
+		s.writeScanError(ctx, w, err)
+		return
+	}
+	result.File = r.URL.Query().Get("name")
+	writeJSON(w, http.StatusOK, reporter.NewReport([]identifier.IdentifierResults{result}, reportOptions))
+}
+
+// scanFiles scans each file of a multipart/form-data upload. Entries of archives, including nested archives, are scanned as "<archive>!/<entry>".
+func (s *Server) scanFiles(w http.ResponseWriter, r *http.Request) {
+	options, reportOptions, err := s.requestOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+	var results []identifier.IdentifierResults
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		if part.FileName() == "" {
+			continue // not a file
+		}
+		b, err := io.ReadAll(part)
+		if err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		err = s.walk(part.FileName(), b, func(e archive.Entry) error {
+			result, err := identifier.IdentifyLicensesInEntryContext(ctx, e, options, s.licenseLibrary)
+			results = append(results, result)
+			return err
+		})
+		if err != nil {
+			s.writeScanError(ctx, w, err)
+			return
+		}
+	}
+	if len(results) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no files were uploaded"))
+		return
+	}
+	writeJSON(w, http.StatusOK, reporter.NewReport(results, reportOptions))
+}
+
+// requestOptions applies the copyrights, keywords, acceptable, normalized, minConfidence, minSimilarity, and expressionOrder query parameters to the defaults
+func (s *Server) requestOptions(r *http.Request) (identifier.Options, reporter.Options, error) {
+	options := s.config.Options
+	reportOptions := reporter.Options{
+		ToolName:              s.config.ToolName,
+		ToolVersion:           s.config.ToolVersion,
+		SPDXVersion:           s.licenseLibrary.SPDXVersion,
+		IncludeNormalizedText: s.config.IncludeNormalizedText,
+	}
+
+	query := r.URL.Query()
+	for name, flag := range map[string]*bool{
+		"copyrights": &options.Enhancements.FlagCopyrights,
+		"keywords":   &options.Enhancements.FlagKeywords,
+		"acceptable": &options.Enhancements.FlagAcceptable,
+		"normalized": &reportOptions.IncludeNormalizedText,
+	} {
+		if v := query.Get(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return options, reportOptions, fmt.Errorf("invalid %v query parameter %q", name, v)
+			}
+			*flag = b
+		}
+	}
+	for name, score := range map[string]*float64{
+		"minConfidence": &options.MinConfidence,
+		"minSimilarity": &options.MinSimilarity,
+	} {
+		if v := query.Get(name); v != "" {
+			c, err := strconv.ParseFloat(v, 64)
+			if err != nil || c < 0 || c > 1 {
+				return options, reportOptions, fmt.Errorf("invalid %v query parameter %q (expected 0 to 1)", name, v)
+			}
+			*score = c
+		}
+	}
+	if v := query.Get("expressionOrder"); v != "" {
+		order, err := identifier.ParseExpressionOrder(v)
+		if err != nil {
+			return options, reportOptions, fmt.Errorf("invalid expressionOrder query parameter %q (expected text or id)", v)
+		}
+		options.ExpressionOrder = order
+	}
+	return options, reportOptions, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
+
+// requestContext returns the context of a scan request, with the deadline of the Timeout
+func (s *Server) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if s.config.Timeout > 0 {
+		return context.WithTimeout(r.Context(), s.config.Timeout)
+	}
+	return context.WithCancel(r.Context())
+}
+
+// writeScanError reports a scan which timed out or was canceled as 503, and any other error as 400
+func (s *Server) writeScanError(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("scan timed out (> %v)", s.config.Timeout))
+	case ctx.Err() != nil:
+		writeError(w, http.StatusServiceUnavailable, ctx.Err())
+	default:
+		writeError(w, http.StatusBadRequest, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, Error{Error: err.Error()})
+}
+
+// writeBodyError reports a request body which exceeded the limit as 413.
+// The error from http.MaxBytesReader is only matched by its message before Go 1.19.
+func writeBodyError(w http.ResponseWriter, err error) {
+	if strings.Contains(err.Error(), "request body too large") {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	writeError(w, http.StatusBadRequest, err)
+}
@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/CycloneDX/license-scanner/configurer"
+	"github.com/CycloneDX/license-scanner/identifier"
+	"github.com/CycloneDX/license-scanner/licenses"
+	"github.com/CycloneDX/license-scanner/reporter"
+)
+
+func newTestServer(t *testing.T, config Config) *httptest.Server {
+	t.Helper()
+	flags := configurer.NewDefaultFlags()
+	if err := flags.Set(configurer.ConfigPathFlag, "../testdata/config/"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := configurer.InitConfig(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	config.ToolName = "license-scanner"
+	ts := httptest.NewServer(New(licenseLibrary, config).Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func apacheLicense(t *testing.T) []byte {
+	t.Helper()
+	b, err := os.ReadFile("../LICENSE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func decode(t *testing.T, resp *http.Response, wantStatus int, v interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("expected status %v got %v", wantStatus, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+}
+
+// licenseIDs returns the file and license IDs of each result
+func licenseIDs(report reporter.Report) map[string][]string {
+	ret := make(map[string][]string)
+	for _, r := range report.Results {
+		ret[r.File] = []string{}
+		for _, l := range r.Licenses {
+			ret[r.File] = append(ret[r.File], l.ID)
+		}
+	}
+	return ret
+}
+
+func TestServer_Health(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(t, Config{})
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var health Health
+	decode(t, resp, http.StatusOK, &health)
+	if health.Status != "ok" || health.Licenses == 0 {
+		t.Errorf("unexpected health %+v", health)
+	}
+
+	resp, err = http.Post(ts.URL+"/healthz", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e Error
+	decode(t, resp, http.StatusMethodNotAllowed, &e)
+}
+
+func TestServer_Licenses(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(t, Config{})
+	resp, err := http.Get(ts.URL + "/v1/licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list LicenseList
+	decode(t, resp, http.StatusOK, &list)
+	if len(list.Licenses) == 0 || list.Licenses[0].ID != "Apache-2.0" {
+		t.Errorf("expected Apache-2.0 in licenses got %+v", list.Licenses)
+	}
+}
+
+func TestServer_ScanText(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(t, Config{MaxFileBytes: 20000})
+
+	resp, err := http.Post(ts.URL+"/v1/scan/text?name=LICENSE&copyrights=true", "text/plain", bytes.NewReader(apacheLicense(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report reporter.Report
+	decode(t, resp, http.StatusOK, &report)
+	if report.SchemaVersion != reporter.SchemaVersion {
+		t.Errorf("expected schemaVersion %v got %v", reporter.SchemaVersion, report.SchemaVersion)
+	}
+	if d := cmp.Diff(map[string][]string{"LICENSE": {"Apache-2.0"}}, licenseIDs(report)); d != "" {
+		t.Errorf("licenses: Diff(-want +got) = %v", d)
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{name: "empty text", url: "/v1/scan/text", wantStatus: http.StatusBadRequest},
+		{name: "invalid query parameter", url: "/v1/scan/text?copyrights=maybe", body: "text", wantStatus: http.StatusBadRequest},
+		{name: "invalid minConfidence", url: "/v1/scan/text?minConfidence=2", body: "text", wantStatus: http.StatusBadRequest},
+		{name: "invalid expressionOrder", url: "/v1/scan/text?expressionOrder=random", body: "text", wantStatus: http.StatusBadRequest},
+		{name: "text too large", url: "/v1/scan/text", body: strings.Repeat("x", 20001), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resp, err := http.Post(ts.URL+tt.url, "text/plain", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var e Error
+			decode(t, resp, tt.wantStatus, &e)
+			if e.Error == "" {
+				t.Errorf("expected an error message")
+			}
+		})
+	}
+}
+
+func TestServer_ScanFiles(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(t, Config{MaxFileBytes: 20000})
+	apache := apacheLicense(t)
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	for name, content := range map[string][]byte{"a/LICENSE": apache, "a/big.txt": bytes.Repeat([]byte("x"), 20001)} {
+		w, _ := zw.Create(name)
+		_, _ = w.Write(content)
+	}
+	_ = zw.Close()
+
+	var tgz bytes.Buffer
+	gw := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gw)
+	_ = tw.WriteHeader(&tar.Header{Name: "./b/LICENSE", Mode: 0o644, Size: int64(len(apache)), Typeflag: tar.TypeReg})
+	_, _ = tw.Write(apache)
+	_ = tw.Close()
+	_ = gw.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, content := range map[string][]byte{"src.zip": zipped.Bytes(), "src.tgz": tgz.Bytes(), "README": []byte("no license here")} {
+		w, _ := mw.CreateFormFile("file", name)
+		_, _ = w.Write(content)
+	}
+	_ = mw.WriteField("comment", "not a file")
+	_ = mw.Close()
+
+	resp, err := http.Post(ts.URL+"/v1/scan/files", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report reporter.Report
+	decode(t, resp, http.StatusOK, &report)
+	expected := map[string][]string{
+		"README":             {},
+		"src.tgz!/b/LICENSE": {"Apache-2.0"},
+		"src.zip!/a/LICENSE": {"Apache-2.0"},
+		"src.zip!/a/big.txt": {},
+	}
+	if d := cmp.Diff(expected, licenseIDs(report)); d != "" {
+		t.Errorf("licenses: Diff(-want +got) = %v", d)
+	}
+	for _, r := range report.Results {
+		if r.File == "src.zip!/a/big.txt" && (r.Status != string(identifier.StatusSkippedTooLarge) || !strings.Contains(r.Notes, "too large")) {
+			t.Errorf("expected too large status and note got %v %q", r.Status, r.Notes)
+		}
+	}
+}
+
+func TestServer_MaxRequestBytes(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(t, Config{MaxRequestBytes: 100})
+	resp, err := http.Post(ts.URL+"/v1/scan/text", "text/plain", strings.NewReader(strings.Repeat("x", 101)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e Error
+	decode(t, resp, http.StatusRequestEntityTooLarge, &e)
+}
+
+func TestServer_Timeout(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(t, Config{Timeout: time.Nanosecond})
+	resp, err := http.Post(ts.URL+"/v1/scan/text", "text/plain", bytes.NewReader(apacheLicense(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e Error
+	decode(t, resp, http.StatusServiceUnavailable, &e)
+	if !strings.Contains(e.Error, "timed out") {
+		t.Errorf("expected a timed out error got %q", e.Error)
+	}
+}
+
+func TestServer_ListenAndServe_Shutdown(t *testing.T) {
+	t.Parallel()
+	s := New(&licenses.LicenseLibrary{}, Config{ShutdownTimeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ListenAndServe(ctx, "127.0.0.1:0")
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ListenAndServe() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListenAndServe() did not return after the context was canceled")
+	}
+}